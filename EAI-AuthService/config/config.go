@@ -24,6 +24,12 @@ type Config struct {
 	ServerHost        string
 	ServerPort        string
 	ServerReadTimeout int
+
+	// debug, info, warn or error
+	LogLevel string
+
+	// OTLP/HTTP endpoint of the collector, tracing is off without it
+	OtelExporterEndpoint string
 }
 
 var configOnce sync.Once
@@ -72,6 +78,9 @@ func LoadConfig() (Config, error) {
 			err = fmt.Errorf("failed to convert SERVER_READ_TIMEOUT to int: %v", err)
 			return
 		}
+
+		config.LogLevel = GetEnv("LOG_LEVEL", "info")
+		config.OtelExporterEndpoint = GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
 	})
 
 	return config, err
@@ -1,8 +1,6 @@
 package controller
 
 import (
-	"context"
-	"log"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -42,16 +40,14 @@ type token struct {
 func (c *UserController) Register(ctx *fiber.Ctx) error {
 	var newUser models.AdminRegistrationPayload
 	if err := ctx.BodyParser(&newUser); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
-	context := context.Background()
+	context := ctx.UserContext()
 
 	userId, tokens, err := c.service.Register(context, newUser)
 	if (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	responseData := registerResponse{
@@ -73,20 +69,18 @@ func (c *UserController) Register(ctx *fiber.Ctx) error {
 func (c *UserController) Login(ctx *fiber.Ctx) error {
 	var user models.AdminCredential
 	if err := ctx.BodyParser(&user); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
 	loginPayload := models.Credential{
 		Nip:      strconv.FormatInt(user.Nip, 10),
 		Password: user.Password,
 	}
-	context := context.Background()
+	context := ctx.UserContext()
 
 	userId, name, tokens, err := c.service.Login(context, loginPayload)
 	if (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	responseData := loginResponse{
@@ -110,9 +104,7 @@ func (c *UserController) RenewTokens(ctx *fiber.Ctx) error {
 
 	claims, err := utils.ExtractTokenMetadata(ctx)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return responses.NewUnauthorizedError(err.Error()).WithCode(responses.CodeInvalidToken)
 	}
 
 	// expiresAccessToken := claims.Expires
@@ -124,21 +116,14 @@ func (c *UserController) RenewTokens(ctx *fiber.Ctx) error {
 	renew := &models.Renew{}
 
 	if err := ctx.BodyParser(renew); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
-	context := context.Background()
-
-	log.Printf("token : %+v", claims.UserID)
+	context := ctx.UserContext()
 
 	tokens, customError := c.service.UpdateRefreshToken(context, renew.RefreshToken, claims)
 	if (customError != responses.CustomError{}) {
-		log.Printf("t : %+v", customError)
-		return ctx.Status(customError.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return customError
 	}
 
 	responseData := utils.Tokens{
@@ -155,7 +140,7 @@ func (c *UserController) RenewTokens(ctx *fiber.Ctx) error {
 func (c *UserController) NurseLogin(ctx *fiber.Ctx) error {
 	var user models.NurseCredential
 	if err := ctx.BodyParser(&user); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
 	loginPayload := models.Credential{
@@ -163,13 +148,11 @@ func (c *UserController) NurseLogin(ctx *fiber.Ctx) error {
 		Password: user.Password,
 	}
 
-	context := context.Background()
+	context := ctx.UserContext()
 
 	userId, name, tokens, err := c.service.NurseLogin(context, loginPayload)
 	if (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	responseData := loginResponse{
@@ -1,22 +1,32 @@
 package main
 
 import (
-	"log"
+	"context"
 
 	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/db"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+	"github.com/ravenocx/hospital-mgt/sdk/tracing"
 	"github.com/ravenocx/hospital-mgt/server"
 )
 
 func main() {
 	config, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("failed to load config : %+v", err)
+		logging.Fatal("failed to load config", "error", err)
+	}
+
+	if err := logging.Setup("auth", config.LogLevel); err != nil {
+		logging.Fatal("failed to set up logging", "error", err)
+	}
+
+	if err := tracing.Init(context.Background(), "auth", config.OtelExporterEndpoint); err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
 	}
 
 	db, err := db.OpenConnection(config)
 	if err != nil {
-		log.Fatalf("failed to connect to db: %v", err)
+		logging.Fatal("failed to connect to db", "error", err)
 	}
 	defer db.Close()
 
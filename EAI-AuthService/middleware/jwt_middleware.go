@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"os"
 
 	jwtMiddleware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/responses"
 )
 
 func JWTProtected() func(*fiber.Ctx) error {
@@ -18,15 +20,9 @@ func JWTProtected() func(*fiber.Ctx) error {
 }
 
 func jwtError(c *fiber.Ctx, err error) error {
-	if err.Error() == "Missing or malformed JWT" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": true,
-			"msg":   err.Error(),
-		})
+	if errors.Is(err, jwtMiddleware.ErrJWTMissingOrMalformed) {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeMissingToken)
 	}
 
-	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-		"error": true,
-		"msg":   err.Error(),
-	})
+	return responses.NewUnauthorizedError(err.Error()).WithCode(responses.CodeInvalidToken)
 }
@@ -0,0 +1,26 @@
+package responses
+
+// The codes are part of the API, clients branch on them. Never rename one,
+// add a new code instead.
+const (
+	CodeBadRequest       = "BAD_REQUEST"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidBody      = "INVALID_BODY"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeMissingToken     = "MISSING_TOKEN"
+	CodeInvalidToken     = "INVALID_TOKEN"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+const (
+	CodeUserNotFound        = "USER_NOT_FOUND"
+	CodeNipConflict         = "NIP_CONFLICT"
+	CodeWrongRole           = "WRONG_ROLE"
+	CodeWrongPassword       = "WRONG_PASSWORD"
+	CodeNoAccess            = "NO_ACCESS"
+	CodeInvalidRefreshToken = "INVALID_REFRESH_TOKEN"
+	CodeSessionExpired      = "SESSION_EXPIRED"
+)
@@ -0,0 +1,43 @@
+package responses
+
+import "net/http"
+
+// ProblemContentType is the media type of the problems, RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error answered by the service, RFC 7807 with
+// the code, the request id and the invalid fields as extensions.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestId string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem describes err for the client. The message of a 500 is replaced,
+// it may hold database or driver errors.
+func NewProblem(err CustomError, instance string, requestId string) Problem {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(err.StatusCode),
+		Status:    err.StatusCode,
+		Detail:    err.Message,
+		Instance:  instance,
+		Code:      err.Code,
+		RequestId: requestId,
+	}
+
+	if err.StatusCode == http.StatusInternalServerError {
+		problem.Detail = "something went wrong on our side, quote the request id when reporting it"
+	}
+
+	if err.Fields != nil {
+		problem.Errors = *err.Fields
+	}
+
+	return problem
+}
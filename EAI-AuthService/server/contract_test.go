@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/auth"
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/sdktest"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+// The contract tests run the auth client of the sdk against the real routes
+// and handlers, on top of a repository returning completely filled rows. A
+// response field renamed or dropped on either side fails them.
+
+const (
+	testAdminNip    = int64(6151202001001)
+	testNewAdminNip = int64(6152202102002)
+	testNurseNip    = int64(3031202001001)
+	testPassword    = "secret-password"
+)
+
+// fakeUserRepo knows an admin and a nurse, both with testPassword.
+type fakeUserRepo struct {
+	password string
+	created  *models.AdminRegistrationPayload
+}
+
+func (r *fakeUserRepo) user(nip string) *models.User {
+	id, role := "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a005", "admin"
+	if nip == strconv.FormatInt(testNurseNip, 10) {
+		id, role = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a004", "nurse"
+	}
+
+	return &models.User{
+		ID:                  id,
+		Nip:                 nip,
+		Name:                role + " one",
+		Role:                role,
+		Password:            r.password,
+		IdentityCardScanImg: "https://storage.example.com/card.png",
+		Access:              true,
+		CreatedAt:           time.Now(),
+	}
+}
+
+func (r *fakeUserRepo) GetUser(ctx context.Context, nip string) (*models.User, error) {
+	if nip == strconv.FormatInt(testNewAdminNip, 10) {
+		return nil, pgx.ErrNoRows
+	}
+	return r.user(nip), nil
+}
+
+func (r *fakeUserRepo) CreateUser(ctx context.Context, user *models.AdminRegistrationPayload, userId uuid.UUID, refreshToken string) (string, error) {
+	r.created = user
+	return userId.String(), nil
+}
+
+func (r *fakeUserRepo) CreateUserTx(ctx context.Context, tx pgx.Tx, user *models.AdminRegistrationPayload, hashPassword string) (string, error) {
+	return uuid.NewString(), nil
+}
+
+func (r *fakeUserRepo) UpdateRefreshToken(ctx context.Context, userId string, refreshToken string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (r *fakeUserRepo) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	return r.user(strconv.FormatInt(testAdminNip, 10)), nil
+}
+
+func (r *fakeUserRepo) GetNurseAccessByNip(ctx context.Context, nip string) (*models.User, error) {
+	return r.user(nip), nil
+}
+
+// startServer serves the routes on a random port and returns a strict client
+// of it, without a token.
+func startServer(t *testing.T, repo *fakeUserRepo) *auth.Client {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", "contract-test-secret")
+	t.Setenv("JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT", "15")
+	t.Setenv("JWT_REFRESH_KEY_EXPIRE_HOURS_COUNT", "24")
+
+	s := NewServer(nil, config.Config{})
+	s.registerRoutes(repo)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen : %+v", err)
+	}
+	go s.app.Listener(ln)
+	t.Cleanup(func() { s.app.Shutdown() })
+
+	clientConfig := httpclient.DefaultConfig("http://" + ln.Addr().String())
+	clientConfig.MaxRetries = 0
+
+	return auth.New(api.NewClient(httpclient.New(clientConfig)).Strict())
+}
+
+func TestAuthContract(t *testing.T) {
+	repo := &fakeUserRepo{password: utils.GeneratePassword(testPassword)}
+	client := startServer(t, repo)
+	ctx := context.Background()
+
+	t.Run("AdminRegister", func(t *testing.T) {
+		session, err := client.AdminRegister(ctx, auth.AdminRegistration{Nip: testNewAdminNip, Name: "admin two", Password: testPassword})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, session)
+		sdktest.RequireFilled(t, repo.created)
+	})
+
+	t.Run("AdminLogin", func(t *testing.T) {
+		session, err := client.AdminLogin(ctx, auth.Credential{Nip: testAdminNip, Password: testPassword})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, session)
+	})
+
+	t.Run("NurseLogin", func(t *testing.T) {
+		session, err := client.NurseLogin(ctx, auth.Credential{Nip: testNurseNip, Password: testPassword})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, session)
+	})
+
+	t.Run("RenewTokens", func(t *testing.T) {
+		session, err := client.AdminLogin(ctx, auth.Credential{Nip: testAdminNip, Password: testPassword})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		tokens, err := client.WithToken(session.Token.AccessToken).RenewTokens(ctx, session.Token.RefreshToken)
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, tokens)
+	})
+}
+
+func TestAuthClientErrors(t *testing.T) {
+	client := startServer(t, &fakeUserRepo{password: utils.GeneratePassword(testPassword)})
+	ctx := context.Background()
+
+	_, err := client.AdminLogin(ctx, auth.Credential{Nip: testAdminNip, Password: "wrong-password"})
+	if api.StatusCode(err) != http.StatusBadRequest || api.Code(err) != responses.CodeWrongPassword {
+		t.Errorf("got error %v, want a 400 %s", err, responses.CodeWrongPassword)
+	}
+
+	session, err := client.AdminLogin(ctx, auth.Credential{Nip: testAdminNip, Password: testPassword})
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+
+	// an expired refresh token is refused with the message of the service
+	_, err = client.WithToken(session.Token.AccessToken).RenewTokens(ctx, "expired.1")
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message == "" || apiErr.RequestId == "" {
+		t.Errorf("got error %v, want a 401 with a message and a request id", err)
+	}
+	if apiErr != nil && apiErr.Code != responses.CodeSessionExpired {
+		t.Errorf("got code %q, want %s", apiErr.Code, responses.CodeSessionExpired)
+	}
+
+	// validation errors list the invalid fields
+	_, err = client.AdminLogin(ctx, auth.Credential{Nip: testAdminNip})
+	if !errors.As(err, &apiErr) || apiErr.Code != responses.CodeValidationFailed || len(apiErr.Fields) == 0 {
+		t.Errorf("got error %v, want a %s with the fields", err, responses.CodeValidationFailed)
+	}
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/openapi"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+var routeParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// TestOpenAPICoversRoutes fails when a route is registered without being in
+// the OpenAPI document, or the other way around.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load the OpenAPI document : %+v", err)
+	}
+
+	s := NewServer(nil, config.Config{})
+	s.registerRoutes(&fakeUserRepo{})
+
+	registered := map[string]bool{}
+	for _, route := range s.app.GetRoutes(true) {
+		if route.Method == http.MethodHead {
+			continue
+		}
+
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		if len(path) > 1 {
+			path = strings.TrimRight(path, "/")
+		}
+		registered[route.Method+" "+path] = true
+
+		item := doc.Paths.Value(path)
+		if item == nil || item.GetOperation(route.Method) == nil {
+			t.Errorf("%s %s is registered but missing from the OpenAPI document", route.Method, path)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !registered[method+" "+path] {
+				t.Errorf("%s %s is in the OpenAPI document but not registered", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	s := NewServer(nil, config.Config{})
+	s.registerRoutes(&fakeUserRepo{})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"nip as a string", http.MethodPost, "/v1/user/admin/login", `{"nip": "6151202001001", "password": "secret-password"}`, http.StatusBadRequest},
+		{"missing password", http.MethodPost, "/v1/user/nurse/login", `{"nip": 3031202001001}`, http.StatusBadRequest},
+		{"short name", http.MethodPost, "/v1/user/admin/register", `{"nip": 6152202102002, "name": "ad", "password": "secret-password"}`, http.StatusBadRequest},
+		{"document", http.MethodGet, "/openapi.json", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := s.app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+// TestOpenAPIValidationAfterAuthentication checks the invalid requests of
+// callers without a valid token are turned away by the authentication, before
+// the validator describes what is wrong with them.
+func TestOpenAPIValidationAfterAuthentication(t *testing.T) {
+	s := NewServer(nil, config.Config{})
+	s.registerRoutes(&fakeUserRepo{})
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+		code  string
+	}{
+		{"no token", "", http.StatusBadRequest, responses.CodeMissingToken},
+		{"invalid token", "not-a-token", http.StatusUnauthorized, responses.CodeInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/user/token/renew", strings.NewReader(`{"refresh_token": 1}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := s.app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+
+			var problem struct {
+				Code string `json:"code"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("failed to decode the error : %+v", err)
+			}
+			if resp.StatusCode != tt.want || problem.Code != tt.code {
+				t.Errorf("got %d %s, want %d %s", resp.StatusCode, problem.Code, tt.want, tt.code)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/controller"
 	"github.com/ravenocx/hospital-mgt/middleware"
 	"github.com/ravenocx/hospital-mgt/repositories"
@@ -10,21 +9,27 @@ import (
 )
 
 func (s *Server) RegisterRoute() {
+	s.registerRoutes(repositories.NewUserRepo(s.dbPool))
+}
+
+// registerRoutes serves the routes on top of repo, the database in the service
+// and a fake in the contract tests.
+func (s *Server) registerRoutes(repo repositories.UserRepositories) {
 	mainRoute := s.app.Group("/v1/user")
 
-	UserRoute(mainRoute, s.dbPool)
+	UserRoute(mainRoute, repo, s.validate)
 }
 
-func UserRoute(r fiber.Router, db *pgxpool.Pool) {
-	c := controller.NewUserController(service.NewUserService(repositories.NewUserRepo(db)))
+func UserRoute(r fiber.Router, repo repositories.UserRepositories, validate fiber.Handler) {
+	c := controller.NewUserController(service.NewUserService(repo))
 
-	r.Post("/nurse/login", c.NurseLogin)
-	r.Post("/token/renew", middleware.JWTProtected(), c.RenewTokens)
+	r.Post("/nurse/login", validate, c.NurseLogin)
+	r.Post("/token/renew", middleware.JWTProtected(), validate, c.RenewTokens)
 
 	adminRoute := r.Group("/admin")
 
-	adminRoute.Post("/register", c.Register)
-	adminRoute.Post("/login", c.Login)
+	adminRoute.Post("/register", validate, c.Register)
+	adminRoute.Post("/login", validate, c.Login)
 
 
 }
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -35,7 +34,7 @@ func (s *userService) Register(ctx context.Context, newUser models.AdminRegistra
 	validate := utils.NewValidator()
 
 	if err := validate.Struct(&newUser); err != nil {
-		return "", nil, responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+		return "", nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
 	}
 
 	existingUser, err := s.repo.GetUser(ctx, strconv.FormatInt(newUser.Nip, 10))
@@ -46,7 +45,7 @@ func (s *userService) Register(ctx context.Context, newUser models.AdminRegistra
 	}
 
 	if existingUser != nil {
-		return "", nil, responses.NewConflictError("user already exists")
+		return "", nil, responses.NewConflictError("user already exists").WithCode(responses.CodeNipConflict)
 	}
 
 	newUser.Password = utils.GeneratePassword(newUser.Password)
@@ -81,25 +80,25 @@ func (s *userService) Register(ctx context.Context, newUser models.AdminRegistra
 
 func (s *userService) Login(ctx context.Context, creds models.Credential) (string, string, *utils.Tokens, responses.CustomError) {
 	if strings.HasPrefix(creds.Nip, "303") {
-		return "", "", nil, responses.NewNotFoundError("user is not from admin (nip not starts with 615)")
+		return "", "", nil, responses.NewNotFoundError("user is not from admin (nip not starts with 615)").WithCode(responses.CodeWrongRole)
 	}
 
 	validate := utils.NewValidator()
 
 	if err := validate.Struct(&creds); err != nil {
-		return "", "", nil, responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+		return "", "", nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
 	}
 
 	user, err := s.repo.GetUser(ctx, creds.Nip)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", "", nil, responses.NewNotFoundError("user not found")
+			return "", "", nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 		}
 		return "", "", nil, responses.NewInternalServerError(fmt.Sprintf("failed to get user : %+v", err.Error()))
 	}
 
 	if err := utils.ComparePasswords(user.Password, creds.Password); err != nil {
-		return "", "", nil, responses.NewBadRequestError("wrong password, please try again!")
+		return "", "", nil, responses.NewBadRequestError("wrong password, please try again!").WithCode(responses.CodeWrongPassword)
 	}
 
 	tokens, err := utils.GenerateNewTokens(user.ID, user.Role)
@@ -109,7 +108,7 @@ func (s *userService) Login(ctx context.Context, creds models.Credential) (strin
 
 	res, err := s.repo.UpdateRefreshToken(ctx, user.ID, tokens.Refresh)
 	if res.RowsAffected() == 0 {
-		return "", "", nil, responses.NewNotFoundError(fmt.Sprintf("user not found : %+v", err.Error()))
+		return "", "", nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 	}
 
 	if err != nil {
@@ -121,37 +120,37 @@ func (s *userService) Login(ctx context.Context, creds models.Credential) (strin
 
 func (s *userService) NurseLogin(ctx context.Context, creds models.Credential) (string, string, *utils.Tokens, responses.CustomError) {
 	if strings.HasPrefix(creds.Nip, "615") {
-		return "", "", nil, responses.NewNotFoundError("user is not from nurse (nip not starts with 303)")
+		return "", "", nil, responses.NewNotFoundError("user is not from nurse (nip not starts with 303)").WithCode(responses.CodeWrongRole)
 	}
 
 	validate := utils.NewValidator()
 
 	if err := validate.Struct(&creds); err != nil {
-		return "", "", nil, responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+		return "", "", nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
 	}
 
 	userAccess, err := s.repo.GetNurseAccessByNip(ctx, creds.Nip)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", "", nil, responses.NewNotFoundError("user not found")
+			return "", "", nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 		}
 		return "", "", nil, responses.NewInternalServerError(fmt.Sprintf("failed to get user : %+v", err.Error()))
 	}
 	if !userAccess.Access {
-		return "", "", nil, responses.NewBadRequestError("user doesn't have access")
+		return "", "", nil, responses.NewBadRequestError("user doesn't have access").WithCode(responses.CodeNoAccess)
 	}
 
 	user, err := s.repo.GetUser(ctx, creds.Nip)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", "", nil, responses.NewNotFoundError("user not found")
+			return "", "", nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 		}
 		return "", "", nil, responses.NewInternalServerError(fmt.Sprintf("failed to get user : %+v", err.Error()))
 	}
 
 
 	if err := utils.ComparePasswords(user.Password, creds.Password); err != nil {
-		return "", "", nil, responses.NewBadRequestError("wrong password!")
+		return "", "", nil, responses.NewBadRequestError("wrong password!").WithCode(responses.CodeWrongPassword)
 	}
 
 	tokens, err := utils.GenerateNewTokens(user.ID, user.Role)
@@ -161,7 +160,7 @@ func (s *userService) NurseLogin(ctx context.Context, creds models.Credential) (
 
 	res, err := s.repo.UpdateRefreshToken(ctx, user.ID, tokens.Refresh)
 	if res.RowsAffected() == 0 {
-		return "", "", nil, responses.NewNotFoundError(fmt.Sprintf("user not found : %+v", err.Error()))
+		return "", "", nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 	}
 
 	if err != nil {
@@ -174,7 +173,7 @@ func (s *userService) NurseLogin(ctx context.Context, creds models.Credential) (
 func (s *userService) UpdateRefreshToken(ctx context.Context, refreshToken string, token *utils.TokenMetadata) (*utils.Tokens, responses.CustomError) {
 	expiresRefreshToken, err := utils.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return nil, responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+		return nil, responses.NewBadRequestError("refresh token is not in valid format").WithCode(responses.CodeInvalidRefreshToken)
 	}
 
 	now := time.Now().Unix()
@@ -185,13 +184,11 @@ func (s *userService) UpdateRefreshToken(ctx context.Context, refreshToken strin
 		user, err := s.repo.GetUserById(ctx, userID.String())
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				return nil, responses.NewNotFoundError("user not found")
+				return nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 			}
 			return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get user : %+v", err.Error()))
 		}
 
-		log.Printf("test %+v", user)
-
 		tokens, err := utils.GenerateNewTokens(user.ID, user.Role)
 		if err != nil {
 			return nil, responses.NewInternalServerError(fmt.Sprintf("failed to generate new JWT token : %+v", err))
@@ -199,9 +196,8 @@ func (s *userService) UpdateRefreshToken(ctx context.Context, refreshToken strin
 
 		res, err := s.repo.UpdateRefreshToken(ctx, user.ID, tokens.Refresh)
 		if res.RowsAffected() == 0 {
-			return nil, responses.NewNotFoundError(fmt.Sprintf("user not found : %+v", err.Error()))
+			return nil, responses.NewNotFoundError("user not found").WithCode(responses.CodeUserNotFound)
 		}
-		log.Println("test")
 
 		if err != nil {
 			return nil, responses.NewInternalServerError(fmt.Sprintf("failed to update refresh token : %+v", err.Error()))
@@ -209,6 +205,6 @@ func (s *userService) UpdateRefreshToken(ctx context.Context, refreshToken strin
 
 		return tokens, responses.CustomError{}
 	} else {
-		return nil, responses.NewUnauthorizedError("unauthorized, your session was ended earlier")
+		return nil, responses.NewUnauthorizedError("unauthorized, your session was ended earlier").WithCode(responses.CodeSessionExpired)
 	}
 }
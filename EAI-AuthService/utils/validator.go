@@ -1,17 +1,30 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/ravenocx/hospital-mgt/responses"
 )
 
 func NewValidator() *validator.Validate {
 	validate := validator.New()
 
+	// name the fields as in the JSON of the request
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
 	_ = validate.RegisterValidation("uuid", func(fl validator.FieldLevel) bool {
 		field := fl.Field().String()
 		if _, err := uuid.Parse(field); err != nil {
@@ -72,12 +85,53 @@ func NewValidator() *validator.Validate {
 	return validate
 }
 
-func ValidatorErrors(err error) map[string]string {
-	fields := map[string]string{}
+// ValidatorErrors lists the fields that failed validation, nil when err is
+// not a validation error.
+func ValidatorErrors(err error) []responses.FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make([]responses.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		// drop the name of the validated struct, keep the path to the field
+		field := fieldErr.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
 
-	for _, err := range err.(validator.ValidationErrors) {
-		fields[err.Field()] = err.Error()
+		fields = append(fields, responses.FieldError{
+			Field:  field,
+			Rule:   fieldErr.Tag(),
+			Detail: fieldDetail(fieldErr),
+		})
 	}
 
 	return fields
 }
+
+func fieldDetail(err validator.FieldError) string {
+	unit := ""
+	switch err.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch err.Tag() {
+	case "required", "required_if", "required_unless", "required_without":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", err.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", err.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", err.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(err.Param(), "'", ""))
+	}
+
+	return fmt.Sprintf("doesn't meet the %s rule", err.Tag())
+}
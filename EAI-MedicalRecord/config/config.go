@@ -24,6 +24,27 @@ type Config struct {
 	ServerHost        string
 	ServerPort        string
 	ServerReadTimeout int
+
+	// debug, info, warn or error
+	LogLevel string
+
+	// OTLP/HTTP endpoint of the collector, tracing is off without it
+	OtelExporterEndpoint string
+
+	BreakGlassDuration int // minutes
+
+	EncryptionKeys      string
+	EncryptionActiveKey int
+	EncryptionSearchKey string
+	ReencryptInterval   int // seconds
+	ReencryptBatchSize  int
+
+	PatientServiceURL    string
+	NurseServiceURL      string
+	HttpClientTimeout    int // milliseconds
+	HttpClientMaxRetries int
+	HttpBreakerThreshold int
+	HttpBreakerCooldown  int // seconds
 }
 
 var configOnce sync.Once
@@ -72,6 +93,63 @@ func LoadConfig() (Config, error) {
 			err = fmt.Errorf("failed to convert SERVER_READ_TIMEOUT to int: %v", err)
 			return
 		}
+
+		config.LogLevel = GetEnv("LOG_LEVEL", "info")
+		config.OtelExporterEndpoint = GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+
+		config.BreakGlassDuration, err = strconv.Atoi(GetEnv("BREAK_GLASS_DURATION_MINUTES", "60"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert BREAK_GLASS_DURATION_MINUTES to int: %v", err)
+			return
+		}
+
+		config.EncryptionKeys = GetEnv("ENCRYPTION_KEYS", "")
+		config.EncryptionSearchKey = GetEnv("ENCRYPTION_SEARCH_KEY", "")
+
+		config.EncryptionActiveKey, err = strconv.Atoi(GetEnv("ENCRYPTION_ACTIVE_KEY", "1"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert ENCRYPTION_ACTIVE_KEY to int: %v", err)
+			return
+		}
+
+		config.ReencryptInterval, err = strconv.Atoi(GetEnv("REENCRYPT_INTERVAL_SECONDS", "300"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert REENCRYPT_INTERVAL_SECONDS to int: %v", err)
+			return
+		}
+
+		config.ReencryptBatchSize, err = strconv.Atoi(GetEnv("REENCRYPT_BATCH_SIZE", "100"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert REENCRYPT_BATCH_SIZE to int: %v", err)
+			return
+		}
+
+		config.PatientServiceURL = GetEnv("PATIENT_SERVICE_URL", "http://localhost:5000")
+		config.NurseServiceURL = GetEnv("NURSE_SERVICE_URL", "http://localhost:4000")
+
+		config.HttpClientTimeout, err = strconv.Atoi(GetEnv("HTTP_CLIENT_TIMEOUT_MS", "3000"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert HTTP_CLIENT_TIMEOUT_MS to int: %v", err)
+			return
+		}
+
+		config.HttpClientMaxRetries, err = strconv.Atoi(GetEnv("HTTP_CLIENT_MAX_RETRIES", "2"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert HTTP_CLIENT_MAX_RETRIES to int: %v", err)
+			return
+		}
+
+		config.HttpBreakerThreshold, err = strconv.Atoi(GetEnv("HTTP_BREAKER_THRESHOLD", "5"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert HTTP_BREAKER_THRESHOLD to int: %v", err)
+			return
+		}
+
+		config.HttpBreakerCooldown, err = strconv.Atoi(GetEnv("HTTP_BREAKER_COOLDOWN_SECONDS", "30"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert HTTP_BREAKER_COOLDOWN_SECONDS to int: %v", err)
+			return
+		}
 	})
 
 	return config, err
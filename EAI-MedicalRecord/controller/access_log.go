@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+)
+
+type AccessLogController struct {
+	service service.AccessLogService
+}
+
+func NewAccessLogController(service service.AccessLogService) *AccessLogController {
+	return &AccessLogController{service: service}
+}
+
+func (c *AccessLogController) GetAccessLogs(ctx *fiber.Ctx) error {
+	identNumberQuery, err := strconv.ParseInt(ctx.Query("identityNumber"), 10, 64)
+	identNumber := &identNumberQuery
+	if err != nil {
+		identNumber = nil
+	}
+
+	limit, err := strconv.Atoi(ctx.Query("limit", "20"))
+	if err != nil || limit < 0 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	accessLogQuery := models.GetAccessLogQueries{
+		IdentityNumber: identNumber,
+		Limit:          limit,
+		Offset:         offset,
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetAccessLogs(context, accessLogQuery)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *AccessLogController) VerifyAccessLogs(ctx *fiber.Ctx) error {
+	context := ctx.UserContext()
+	resp, custErr := c.service.VerifyAccessLogs(context)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type BreakGlassController struct {
+	service service.BreakGlassService
+}
+
+func NewBreakGlassController(service service.BreakGlassService) *BreakGlassController {
+	return &BreakGlassController{service: service}
+}
+
+func (c *BreakGlassController) BreakGlass(ctx *fiber.Ctx) error {
+	var newGrant models.BreakGlassPayload
+	if err := ctx.BodyParser(&newGrant); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	grant, custErr := c.service.BreakGlass(context, newGrant, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Break the glass access granted, every read is logged and reviewed",
+		"data":    grant,
+	})
+}
+
+func (c *BreakGlassController) GetBreakGlassGrants(ctx *fiber.Ctx) error {
+	limit, err := strconv.Atoi(ctx.Query("limit", "20"))
+	if err != nil || limit < 0 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	grantQuery := models.GetBreakGlassQueries{
+		ReviewStatus: ctx.Query("reviewStatus", models.BreakGlassPending),
+		Limit:        limit,
+		Offset:       offset,
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetBreakGlassGrants(context, grantQuery)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *BreakGlassController) ReviewBreakGlass(ctx *fiber.Ctx) error {
+	grantId := ctx.Params("id")
+
+	var review models.BreakGlassReviewPayload
+	if err := ctx.BodyParser(&review); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	grant, custErr := c.service.ReviewBreakGlass(context, grantId, review, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    grant,
+	})
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+)
+
+type DiagnosisController struct {
+	service service.DiagnosisService
+}
+
+func NewDiagnosisController(service service.DiagnosisService) *DiagnosisController {
+	return &DiagnosisController{service: service}
+}
+
+func (c *DiagnosisController) SearchIcd10Codes(ctx *fiber.Ctx) error {
+	limit, err := strconv.Atoi(ctx.Query("limit", "10"))
+	if err != nil || limit < 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	icd10Query := models.GetIcd10Queries{
+		Query:  ctx.Query("q"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.SearchIcd10Codes(context, icd10Query)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
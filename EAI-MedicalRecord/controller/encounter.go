@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type EncounterController struct {
+	service service.EncounterService
+}
+
+func NewEncounterController(service service.EncounterService) *EncounterController {
+	return &EncounterController{service: service}
+}
+
+func (c *EncounterController) OpenEncounter(ctx *fiber.Ctx) error {
+	var newEncounter models.EncounterRegistrationPayload
+	if err := ctx.BodyParser(&newEncounter); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	id, custErr := c.service.OpenEncounter(context, newEncounter, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Encounter opened successfully",
+		"data": fiber.Map{
+			"id":             id,
+			"identityNumber": newEncounter.IdentityNumber,
+			"encounterType":  newEncounter.EncounterType,
+			"status":         models.EncounterStatusOpen,
+		},
+	})
+}
+
+func (c *EncounterController) DischargeEncounter(ctx *fiber.Ctx) error {
+	encounterId := ctx.Params("encounterId")
+
+	var discharge models.EncounterDischargePayload
+	if err := ctx.BodyParser(&discharge); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	custErr := c.service.DischargeEncounter(context, encounterId, discharge, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Encounter discharged successfully",
+	})
+}
+
+func (c *EncounterController) GetEncounters(ctx *fiber.Ctx) error {
+	identNumberQuery, err := strconv.ParseInt(ctx.Query("identityNumber"), 10, 64)
+	identNumber := &identNumberQuery
+	if err != nil {
+		identNumber = nil
+	}
+
+	limit, err := strconv.Atoi(ctx.Query("limit", "5"))
+	if err != nil || limit < 0 {
+		limit = 5
+	}
+
+	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	encounterQuery := models.GetEncounterQueries{
+		IdentityNumber: identNumber,
+		Status:         ctx.Query("status"),
+		Limit:          limit,
+		Offset:         offset,
+	}
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetEncounters(context, encounterQuery, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
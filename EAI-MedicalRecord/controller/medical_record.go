@@ -1,13 +1,13 @@
 package controller
 
 import (
-	"context"
-	"log"
+	"log/slog"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/ravenocx/hospital-mgt/middleware"
 	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
 	"github.com/ravenocx/hospital-mgt/responses"
 	"github.com/ravenocx/hospital-mgt/service"
 	"github.com/ravenocx/hospital-mgt/utils"
@@ -24,44 +24,42 @@ func NewUserController(service service.MedicalRecordService) *MedicalRecordContr
 func (c *MedicalRecordController) RegisterRecord(ctx *fiber.Ctx) error {
 	var newRecord models.RecordRegistrationPayload
 	if err := ctx.BodyParser(&newRecord); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
 	claims, err := utils.ExtractTokenMetadata(ctx)
 	if err != nil {
-		log.Println(err)
-		return middleware.UnauthorizedResponse(ctx, "token not found")
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
 	}
 
 	userID := claims.UserID
 
 	jwtToken := utils.ExtractToken(ctx)
 
-	nurse, custErr := c.service.GetNurseDetail(userID.String(), jwtToken)
+	context := ctx.UserContext()
+	nurse, custErr := c.service.GetNurseDetail(context, userID.String(), jwtToken)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
-		})
+		return custErr
 	}
 
 	// TODO : get user should consume endpoint get user
 	createdByDetail := models.CreatedByDetail{
 		UserId: userID.String(),
-		Nip:    strconv.FormatInt(nurse[0].NIP, 10) ,
-		Name:   nurse[0].Name,
+		Nip:    strconv.FormatInt(nurse.Nip, 10) ,
+		Name:   nurse.Name,
+		Role:   claims.Role,
 	}
 
-	context := context.Background()
-	custErr = c.service.RegisterRecord(context, newRecord, createdByDetail, jwtToken)
+	record, warnings, custErr := c.service.RegisterRecord(context, newRecord, createdByDetail, jwtToken)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
-		})
+		return custErr
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Medical record added successfully",
-		"data":    newRecord,
+		"message":  "Medical record added successfully",
+		"data":     record,
+		"warnings": warnings,
 	})
 }
 
@@ -73,28 +71,32 @@ func (c *MedicalRecordController) GetRecord(ctx *fiber.Ctx) error {
 	}
 
 	limit, err := strconv.Atoi(ctx.Query("limit", "5"))
-	log.Printf("limit : %+v", limit)
 	if err != nil || limit < 0 {
 		limit = 5
 	}
-	log.Printf("limit : %+v", limit)
 
 
 	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
 	if err != nil || offset < 0 {
 		offset = 0
 	}
-	log.Printf("offset : %+v", offset)
 
 
-	nip := ctx.Query("nip")
+	// nip and userId are the names used before createdBy.nip and
+	// createdBy.userId, they are still read for older clients
+	nip := ctx.Query("createdBy.nip", ctx.Query("nip"))
 	_, err = strconv.ParseInt(nip, 10, 64)
 	if err != nil {
 		nip = ""
 	}
 
-	userId := ctx.Query("userId")
+	userId := ctx.Query("createdBy.userId", ctx.Query("userId"))
 	createdAt := ctx.Query("createdAt")
+	if !repositories.RecordSort.Valid("createdAt", createdAt) {
+		return responses.NewValidationError("query params doesn't meet requirement", []responses.FieldError{
+			{Field: "createdAt", Rule: "oneof", Detail: "must be one of asc desc"},
+		})
+	}
 
 	recordQuery := models.GetRecordQueries{
 		IdentityNumber:  identNumber,
@@ -102,15 +104,25 @@ func (c *MedicalRecordController) GetRecord(ctx *fiber.Ctx) error {
 		Offset:          offset,
 		CreatedByNip:    nip,
 		CreatedByUserId: userId,
+		CreatedByRole:   ctx.Query("createdBy.role"),
 		CreatedAt:       createdAt,
+		CreatedFrom:     ctx.Query("createdFrom"),
+		CreatedTo:       ctx.Query("createdTo"),
+		Search:          strings.TrimSpace(ctx.Query("q")),
+		EncounterId:     ctx.Query("encounterId"),
+		DiagnosisCode:   ctx.Query("diagnosisCode"),
 	}
 
-	context := context.Background()
-	resp, custErr := c.service.GetRecord(context, recordQuery)
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetRecord(context, recordQuery, requester)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
-		})
+		return custErr
 	}
 
 	if len(resp) == 0 {
@@ -125,3 +137,101 @@ func (c *MedicalRecordController) GetRecord(ctx *fiber.Ctx) error {
 		"data":    resp,
 	})
 }
+
+func (c *MedicalRecordController) GetRecordById(ctx *fiber.Ctx) error {
+	recordId := ctx.Params("id")
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetRecordById(context, recordId, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *MedicalRecordController) AmendRecord(ctx *fiber.Ctx) error {
+	recordId := ctx.Params("id")
+
+	var amendment models.RecordAmendmentPayload
+	if err := ctx.BodyParser(&amendment); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	jwtToken := utils.ExtractToken(ctx)
+
+	context := ctx.UserContext()
+	nurse, custErr := c.service.GetNurseDetail(context, requester.UserId, jwtToken)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	amendedBy := models.CreatedByDetail{
+		UserId: requester.UserId,
+		Nip:    strconv.FormatInt(nurse.Nip, 10),
+		Name:   nurse.Name,
+	}
+
+	resp, warnings, custErr := c.service.AmendRecord(context, recordId, amendment, amendedBy, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Medical record amended successfully",
+		"data":     resp,
+		"warnings": warnings,
+	})
+}
+
+func (c *MedicalRecordController) GetRecordHistory(ctx *fiber.Ctx) error {
+	recordId := ctx.Params("id")
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetRecordHistory(context, recordId, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+// recordRequester reads who is asking for medical records from the token, an
+// admin gives the reason of the access in the reason query param.
+func recordRequester(ctx *fiber.Ctx) (models.RecordRequester, error) {
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		return models.RecordRequester{}, err
+	}
+
+	return models.RecordRequester{
+		UserId:   claims.UserID.String(),
+		Role:     claims.Role,
+		Reason:   ctx.Query("reason"),
+		ClientIP: ctx.IP(),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+)
+
+type MedicationController struct {
+	service service.MedicationService
+}
+
+func NewMedicationController(service service.MedicationService) *MedicationController {
+	return &MedicationController{service: service}
+}
+
+func (c *MedicationController) SearchDrugs(ctx *fiber.Ctx) error {
+	limit, err := strconv.Atoi(ctx.Query("limit", "10"))
+	if err != nil || limit < 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	drugQuery := models.GetDrugQueries{
+		Name:   ctx.Query("name"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.SearchDrugs(context, drugQuery)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *MedicationController) GetActiveMedications(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Query("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetActiveMedications(context, identityNumber, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
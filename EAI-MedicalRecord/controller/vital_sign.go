@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+)
+
+type VitalSignController struct {
+	service service.VitalSignService
+}
+
+func NewVitalSignController(service service.VitalSignService) *VitalSignController {
+	return &VitalSignController{service: service}
+}
+
+func (c *VitalSignController) GetVitalSignSeries(ctx *fiber.Ctx) error {
+	identNumberQuery, err := strconv.ParseInt(ctx.Query("identityNumber"), 10, 64)
+	identNumber := &identNumberQuery
+	if err != nil {
+		identNumber = nil
+	}
+
+	vitalQuery := models.GetVitalSignQueries{
+		IdentityNumber: identNumber,
+		Type:           ctx.Query("type"),
+		From:           ctx.Query("from"),
+		To:             ctx.Query("to"),
+	}
+
+	requester, err := recordRequester(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetVitalSignSeries(context, vitalQuery, requester)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
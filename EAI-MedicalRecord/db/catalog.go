@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed catalog/drugs.csv
+var drugCatalog string
+
+//go:embed catalog/icd10.csv
+var icd10Catalog string
+
+// SeedCatalogs upserts the catalogs bundled with the service (drugs and ICD-10
+// codes), so the tables follow the files shipped in catalog/. Entries removed
+// from a file are kept because existing records still reference them.
+func SeedCatalogs(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	drugStatement := `INSERT INTO drug_catalog (code, name, form, units, routes) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name, form = EXCLUDED.form, units = EXCLUDED.units, routes = EXCLUDED.routes`
+
+	err = seedCatalog(ctx, tx, "drug", drugCatalog, 5, func(row []string) error {
+		_, err := tx.Exec(ctx, drugStatement, row[0], row[1], row[2], strings.Split(row[3], "|"), strings.Split(row[4], "|"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	icd10Statement := `INSERT INTO icd10_codes (code, description) VALUES ($1, $2)
+		ON CONFLICT (code) DO UPDATE SET description = EXCLUDED.description`
+
+	err = seedCatalog(ctx, tx, "ICD-10", icd10Catalog, 2, func(row []string) error {
+		_, err := tx.Exec(ctx, icd10Statement, row[0], row[1])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func seedCatalog(ctx context.Context, tx pgx.Tx, name string, data string, columns int, insert func(row []string) error) error {
+	rows, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("unable to read %s catalog : %+v", name, err)
+	}
+
+	// first row is the header
+	for i, row := range rows[1:] {
+		if len(row) != columns {
+			return fmt.Errorf("%s catalog line %d : expected %d columns, got %d", name, i+2, columns, len(row))
+		}
+
+		if err := insert(row); err != nil {
+			return fmt.Errorf("unable to seed %s %s : %+v", name, row[0], err)
+		}
+	}
+
+	return nil
+}
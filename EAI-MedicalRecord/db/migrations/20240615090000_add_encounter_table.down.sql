@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_medical_records_encounter_id CASCADE;
+
+ALTER TABLE medical_records DROP COLUMN IF EXISTS encounter_id;
+
+DROP TABLE IF EXISTS encounter_staff CASCADE;
+
+DROP TABLE IF EXISTS encounters CASCADE;
@@ -0,0 +1,30 @@
+CREATE TABLE encounters (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number),
+    encounter_type VARCHAR(20) NOT NULL, -- outpatient, inpatient, emergency
+    admitting_reason TEXT NOT NULL,
+    ward VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- open, discharged
+    opened_by_user_id UUID NOT NULL,
+    admitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    discharge_summary TEXT,
+    discharged_by_user_id UUID,
+    discharged_at TIMESTAMP
+);
+
+CREATE INDEX idx_encounters_identity_number ON encounters(identity_number, admitted_at);
+-- a patient can only have one open encounter at a time
+CREATE UNIQUE INDEX idx_encounters_open_identity_number ON encounters(identity_number) WHERE status = 'open';
+
+CREATE TABLE encounter_staff (
+    encounter_id UUID NOT NULL REFERENCES encounters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (encounter_id, user_id)
+);
+
+CREATE INDEX idx_encounter_staff_user_id ON encounter_staff(user_id);
+
+ALTER TABLE medical_records ADD COLUMN encounter_id UUID REFERENCES encounters(id);
+
+CREATE INDEX idx_medical_records_encounter_id ON medical_records(encounter_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS record_vital_signs CASCADE;
+
+DROP INDEX IF EXISTS idx_record_vital_signs_record_id CASCADE;
+DROP INDEX IF EXISTS idx_record_vital_signs_series CASCADE;
@@ -0,0 +1,13 @@
+CREATE TABLE record_vital_signs (
+    id BIGSERIAL PRIMARY KEY,
+    record_id UUID NOT NULL REFERENCES medical_records(id) ON DELETE CASCADE,
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number),
+    vital_type VARCHAR(30) NOT NULL, -- temperature, blood_pressure, pulse, respiratory_rate, spo2, weight, height, pain_score
+    value NUMERIC(7, 2) NOT NULL, -- systolic for blood_pressure
+    diastolic NUMERIC(7, 2), -- only for blood_pressure
+    unit VARCHAR(20) NOT NULL, -- always the canonical unit of the type
+    measured_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_record_vital_signs_record_id ON record_vital_signs(record_id);
+CREATE INDEX idx_record_vital_signs_series ON record_vital_signs(identity_number, vital_type, measured_at);
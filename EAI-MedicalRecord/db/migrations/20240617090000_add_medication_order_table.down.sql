@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS medication_orders CASCADE;
+
+DROP TABLE IF EXISTS drug_catalog CASCADE;
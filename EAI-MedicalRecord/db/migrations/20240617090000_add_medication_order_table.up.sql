@@ -0,0 +1,31 @@
+CREATE TABLE drug_catalog (
+    code VARCHAR(20) PRIMARY KEY NOT NULL, -- ATC code
+    name VARCHAR(100) NOT NULL,
+    form VARCHAR(30) NOT NULL,
+    units TEXT[] NOT NULL, -- dose units the drug can be ordered in
+    routes TEXT[] NOT NULL -- routes the drug can be given by
+);
+
+CREATE INDEX idx_drug_catalog_name ON drug_catalog(lower(name) text_pattern_ops);
+
+CREATE TABLE medication_orders (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    record_id UUID NOT NULL REFERENCES medical_records(id) ON DELETE CASCADE,
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number),
+    drug_code VARCHAR(20) NOT NULL REFERENCES drug_catalog(code),
+    drug_name VARCHAR(100) NOT NULL, -- name at the time of the order
+    dose NUMERIC(10, 3) NOT NULL,
+    dose_unit VARCHAR(20) NOT NULL,
+    route VARCHAR(20) NOT NULL,
+    frequency VARCHAR(20) NOT NULL,
+    start_at TIMESTAMP NOT NULL,
+    stop_at TIMESTAMP,
+    prescribed_by_user_id UUID NOT NULL,
+    prescribed_by_name VARCHAR(50) NOT NULL,
+    prescribed_by_nip VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_medication_orders_record_id ON medication_orders(record_id);
+CREATE INDEX idx_medication_orders_identity_number ON medication_orders(identity_number, start_at);
+CREATE INDEX idx_medication_orders_drug_code ON medication_orders(drug_code);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS record_diagnoses CASCADE;
+
+DROP TABLE IF EXISTS icd10_codes CASCADE;
@@ -0,0 +1,17 @@
+CREATE TABLE icd10_codes (
+    code VARCHAR(10) PRIMARY KEY NOT NULL,
+    description TEXT NOT NULL
+);
+
+CREATE INDEX idx_icd10_codes_code ON icd10_codes(code text_pattern_ops);
+
+CREATE TABLE record_diagnoses (
+    record_id UUID NOT NULL REFERENCES medical_records(id) ON DELETE CASCADE,
+    code VARCHAR(10) NOT NULL REFERENCES icd10_codes(code),
+    diagnosis_type VARCHAR(10) NOT NULL, -- primary, secondary
+    PRIMARY KEY (record_id, code)
+);
+
+-- a record has at most one primary diagnosis
+CREATE UNIQUE INDEX idx_record_diagnoses_primary ON record_diagnoses(record_id) WHERE diagnosis_type = 'primary';
+CREATE INDEX idx_record_diagnoses_code ON record_diagnoses(code text_pattern_ops);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS medical_records_immutable ON medical_records;
+
+DROP FUNCTION IF EXISTS medical_records_immutable();
+
+DROP TABLE IF EXISTS medical_record_amendments CASCADE;
+
+DROP FUNCTION IF EXISTS medical_record_amendments_immutable();
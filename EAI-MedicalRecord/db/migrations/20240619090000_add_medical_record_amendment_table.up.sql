@@ -0,0 +1,48 @@
+CREATE TABLE medical_record_amendments (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    record_id UUID NOT NULL REFERENCES medical_records(id),
+    version INT NOT NULL, -- the original record is version 1
+    symptoms TEXT NOT NULL,
+    medications TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    amended_by_nip VARCHAR(20) NOT NULL,
+    amended_by_name VARCHAR(50) NOT NULL,
+    amended_by_user_id UUID NOT NULL,
+    amended_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (record_id, version)
+);
+
+-- the content of a record is part of the legal record: it can only be
+-- corrected by adding an amendment, never updated or deleted in place
+CREATE OR REPLACE FUNCTION medical_records_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'medical record % can not be deleted', OLD.id;
+    END IF;
+
+    IF NEW.id IS DISTINCT FROM OLD.id
+        OR NEW.identity_number IS DISTINCT FROM OLD.identity_number
+        OR NEW.symptoms IS DISTINCT FROM OLD.symptoms
+        OR NEW.medications IS DISTINCT FROM OLD.medications
+        OR NEW.created_by_nip IS DISTINCT FROM OLD.created_by_nip
+        OR NEW.created_by_name IS DISTINCT FROM OLD.created_by_name
+        OR NEW.created_by_user_id IS DISTINCT FROM OLD.created_by_user_id
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+        RAISE EXCEPTION 'medical record % is immutable, add an amendment instead', OLD.id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER medical_records_immutable BEFORE UPDATE OR DELETE ON medical_records
+    FOR EACH ROW EXECUTE FUNCTION medical_records_immutable();
+
+CREATE OR REPLACE FUNCTION medical_record_amendments_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'medical record amendment % is immutable', OLD.id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER medical_record_amendments_immutable BEFORE UPDATE OR DELETE ON medical_record_amendments
+    FOR EACH ROW EXECUTE FUNCTION medical_record_amendments_immutable();
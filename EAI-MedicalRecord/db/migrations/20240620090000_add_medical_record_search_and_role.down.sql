@@ -0,0 +1,28 @@
+CREATE OR REPLACE FUNCTION medical_records_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'medical record % can not be deleted', OLD.id;
+    END IF;
+
+    IF NEW.id IS DISTINCT FROM OLD.id
+        OR NEW.identity_number IS DISTINCT FROM OLD.identity_number
+        OR NEW.symptoms IS DISTINCT FROM OLD.symptoms
+        OR NEW.medications IS DISTINCT FROM OLD.medications
+        OR NEW.created_by_nip IS DISTINCT FROM OLD.created_by_nip
+        OR NEW.created_by_name IS DISTINCT FROM OLD.created_by_name
+        OR NEW.created_by_user_id IS DISTINCT FROM OLD.created_by_user_id
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+        RAISE EXCEPTION 'medical record % is immutable, add an amendment instead', OLD.id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_medical_records_search_vector;
+
+ALTER TABLE medical_records DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_medical_records_created_by_role;
+
+ALTER TABLE medical_records DROP COLUMN IF EXISTS created_by_role;
@@ -0,0 +1,51 @@
+-- role of the author, from the NIP prefix for the existing records
+-- (615 for admins, 303 for nurses)
+ALTER TABLE medical_records ADD COLUMN created_by_role VARCHAR(10);
+
+UPDATE medical_records SET created_by_role = CASE
+    WHEN created_by_nip LIKE '615%' THEN 'admin'
+    WHEN created_by_nip LIKE '303%' THEN 'nurse'
+    ELSE 'unknown'
+END;
+
+ALTER TABLE medical_records ALTER COLUMN created_by_role SET NOT NULL;
+
+CREATE INDEX idx_medical_records_created_by_role ON medical_records(created_by_role);
+
+-- full-text search over the latest symptoms and medications of a record. The
+-- service writes it with the record and again with every amendment.
+ALTER TABLE medical_records ADD COLUMN search_vector TSVECTOR;
+
+UPDATE medical_records SET search_vector = to_tsvector('simple', COALESCE(latest.symptoms, medical_records.symptoms) || ' ' || COALESCE(latest.medications, medical_records.medications))
+FROM medical_records AS record
+LEFT JOIN LATERAL (
+    SELECT symptoms, medications FROM medical_record_amendments
+    WHERE medical_record_amendments.record_id = record.id
+    ORDER BY version DESC LIMIT 1
+) AS latest ON true
+WHERE record.id = medical_records.id;
+
+CREATE INDEX idx_medical_records_search_vector ON medical_records USING gin(search_vector);
+
+-- the author role is part of the record as well
+CREATE OR REPLACE FUNCTION medical_records_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'medical record % can not be deleted', OLD.id;
+    END IF;
+
+    IF NEW.id IS DISTINCT FROM OLD.id
+        OR NEW.identity_number IS DISTINCT FROM OLD.identity_number
+        OR NEW.symptoms IS DISTINCT FROM OLD.symptoms
+        OR NEW.medications IS DISTINCT FROM OLD.medications
+        OR NEW.created_by_nip IS DISTINCT FROM OLD.created_by_nip
+        OR NEW.created_by_name IS DISTINCT FROM OLD.created_by_name
+        OR NEW.created_by_user_id IS DISTINCT FROM OLD.created_by_user_id
+        OR NEW.created_by_role IS DISTINCT FROM OLD.created_by_role
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+        RAISE EXCEPTION 'medical record % is immutable, add an amendment instead', OLD.id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
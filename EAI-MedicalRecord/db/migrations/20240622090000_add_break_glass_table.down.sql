@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS break_glass_reads;
+
+DROP TABLE IF EXISTS break_glass_grants;
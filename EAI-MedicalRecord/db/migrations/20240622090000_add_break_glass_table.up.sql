@@ -0,0 +1,28 @@
+-- time-limited access of a user to the records of one patient outside of the
+-- normal access rules, given on a justification and reviewed by an admin
+CREATE TABLE break_glass_grants (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number),
+    justification TEXT NOT NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    review_status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, acknowledged, escalated
+    review_note TEXT,
+    reviewed_by_user_id UUID,
+    reviewed_at TIMESTAMP
+);
+
+CREATE INDEX idx_break_glass_grants_user_id ON break_glass_grants(user_id, identity_number, expires_at);
+CREATE INDEX idx_break_glass_grants_review_status ON break_glass_grants(review_status, granted_at);
+
+-- every record read while a grant is active
+CREATE TABLE break_glass_reads (
+    id BIGSERIAL PRIMARY KEY,
+    grant_id UUID NOT NULL REFERENCES break_glass_grants(id),
+    record_id UUID NOT NULL REFERENCES medical_records(id),
+    read_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_break_glass_reads_grant_id ON break_glass_reads(grant_id, read_at);
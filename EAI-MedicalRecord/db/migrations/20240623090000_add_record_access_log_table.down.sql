@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS record_access_logs;
+
+DROP FUNCTION IF EXISTS record_access_logs_append_only();
@@ -0,0 +1,30 @@
+-- every read of medical records, one row per patient read. Rows are chained:
+-- hash is the SHA-256 of the row content and the hash of the previous row, so
+-- changing or removing a row breaks the chain from there on.
+CREATE TABLE record_access_logs (
+    id BIGSERIAL PRIMARY KEY,
+    reader_user_id UUID NOT NULL,
+    reader_role VARCHAR(20) NOT NULL,
+    identity_number BIGINT NOT NULL,
+    record_ids UUID[] NOT NULL,
+    access_type VARCHAR(20) NOT NULL, -- list, single, history
+    reason TEXT, -- given by admins
+    client_ip VARCHAR(45) NOT NULL,
+    accessed_at TIMESTAMPTZ NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_record_access_logs_identity_number ON record_access_logs(identity_number, accessed_at);
+
+CREATE OR REPLACE FUNCTION record_access_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'record access logs are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_access_logs_append_only BEFORE UPDATE OR DELETE ON record_access_logs
+    FOR EACH ROW EXECUTE FUNCTION record_access_logs_append_only();
+
+CREATE TRIGGER record_access_logs_no_truncate BEFORE TRUNCATE ON record_access_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION record_access_logs_append_only();
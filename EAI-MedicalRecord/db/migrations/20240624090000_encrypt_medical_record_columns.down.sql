@@ -0,0 +1,39 @@
+-- the values already encrypted stay encrypted, they can only be read by the
+-- service with the master keys
+DROP FUNCTION IF EXISTS reencrypt_medical_record(UUID, TEXT, TEXT, TSVECTOR);
+
+DROP FUNCTION IF EXISTS reencrypt_medical_record_amendment(UUID, TEXT, TEXT);
+
+REVOKE ALL ON medical_records FROM medical_record_reencrypt;
+
+REVOKE ALL ON medical_record_amendments FROM medical_record_reencrypt;
+
+DROP ROLE IF EXISTS medical_record_reencrypt;
+
+CREATE OR REPLACE FUNCTION medical_record_amendments_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'medical record amendment % is immutable', OLD.id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION medical_records_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'medical record % can not be deleted', OLD.id;
+    END IF;
+
+    IF NEW.id IS DISTINCT FROM OLD.id
+        OR NEW.identity_number IS DISTINCT FROM OLD.identity_number
+        OR NEW.symptoms IS DISTINCT FROM OLD.symptoms
+        OR NEW.medications IS DISTINCT FROM OLD.medications
+        OR NEW.created_by_nip IS DISTINCT FROM OLD.created_by_nip
+        OR NEW.created_by_name IS DISTINCT FROM OLD.created_by_name
+        OR NEW.created_by_user_id IS DISTINCT FROM OLD.created_by_user_id
+        OR NEW.created_by_role IS DISTINCT FROM OLD.created_by_role
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+        RAISE EXCEPTION 'medical record % is immutable, add an amendment instead', OLD.id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,92 @@
+-- symptoms and medications are encrypted by the service. The values written
+-- before stay readable as plaintext until the re-encryption job encrypts them.
+-- The job goes through the functions below, which run as the
+-- medical_record_reencrypt role: nobody can log in as it, and the triggers
+-- only let the encrypted columns change for it. The functions only take
+-- ciphertext, the database can't tell a re-encryption from an edit so the
+-- service holding the keys is trusted to keep the plaintext as it is.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'medical_record_reencrypt') THEN
+        CREATE ROLE medical_record_reencrypt NOLOGIN;
+    END IF;
+END
+$$;
+
+GRANT SELECT (id), UPDATE (symptoms, medications, search_vector) ON medical_records TO medical_record_reencrypt;
+
+GRANT SELECT (id), UPDATE (symptoms, medications) ON medical_record_amendments TO medical_record_reencrypt;
+
+CREATE OR REPLACE FUNCTION medical_records_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'medical record % can not be deleted', OLD.id;
+    END IF;
+
+    IF NEW.id IS DISTINCT FROM OLD.id
+        OR NEW.identity_number IS DISTINCT FROM OLD.identity_number
+        OR NEW.created_by_nip IS DISTINCT FROM OLD.created_by_nip
+        OR NEW.created_by_name IS DISTINCT FROM OLD.created_by_name
+        OR NEW.created_by_user_id IS DISTINCT FROM OLD.created_by_user_id
+        OR NEW.created_by_role IS DISTINCT FROM OLD.created_by_role
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+        RAISE EXCEPTION 'medical record % is immutable, add an amendment instead', OLD.id;
+    END IF;
+
+    IF (NEW.symptoms IS DISTINCT FROM OLD.symptoms OR NEW.medications IS DISTINCT FROM OLD.medications)
+        AND current_user IS DISTINCT FROM 'medical_record_reencrypt' THEN
+        RAISE EXCEPTION 'medical record % is immutable, add an amendment instead', OLD.id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION medical_record_amendments_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_user = 'medical_record_reencrypt'
+        AND ROW(NEW.id, NEW.record_id, NEW.version, NEW.reason, NEW.amended_by_nip, NEW.amended_by_name, NEW.amended_by_user_id, NEW.amended_at)
+            IS NOT DISTINCT FROM ROW(OLD.id, OLD.record_id, OLD.version, OLD.reason, OLD.amended_by_nip, OLD.amended_by_name, OLD.amended_by_user_id, OLD.amended_at) THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'medical record amendment % is immutable', OLD.id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION reencrypt_medical_record(record_id UUID, new_symptoms TEXT, new_medications TEXT, new_search_vector TSVECTOR) RETURNS VOID AS $$
+BEGIN
+    IF new_symptoms NOT LIKE 'enc:v1:%' OR new_medications NOT LIKE 'enc:v1:%' THEN
+        RAISE EXCEPTION 'medical record % can only be re-encrypted', record_id;
+    END IF;
+
+    UPDATE medical_records SET symptoms = new_symptoms, medications = new_medications, search_vector = new_search_vector WHERE id = record_id;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE OR REPLACE FUNCTION reencrypt_medical_record_amendment(amendment_id UUID, new_symptoms TEXT, new_medications TEXT) RETURNS VOID AS $$
+BEGIN
+    IF new_symptoms NOT LIKE 'enc:v1:%' OR new_medications NOT LIKE 'enc:v1:%' THEN
+        RAISE EXCEPTION 'medical record amendment % can only be re-encrypted', amendment_id;
+    END IF;
+
+    UPDATE medical_record_amendments SET symptoms = new_symptoms, medications = new_medications WHERE id = amendment_id;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+ALTER FUNCTION reencrypt_medical_record(UUID, TEXT, TEXT, TSVECTOR) OWNER TO medical_record_reencrypt;
+
+ALTER FUNCTION reencrypt_medical_record_amendment(UUID, TEXT, TEXT) OWNER TO medical_record_reencrypt;
+
+-- only the user running the migrations, the one of the service, can call
+-- them. Grant them to the service user when it is another one.
+REVOKE ALL ON FUNCTION reencrypt_medical_record(UUID, TEXT, TEXT, TSVECTOR) FROM PUBLIC;
+
+REVOKE ALL ON FUNCTION reencrypt_medical_record_amendment(UUID, TEXT, TEXT) FROM PUBLIC;
+
+GRANT EXECUTE ON FUNCTION reencrypt_medical_record(UUID, TEXT, TEXT, TSVECTOR) TO CURRENT_USER;
+
+GRANT EXECUTE ON FUNCTION reencrypt_medical_record_amendment(UUID, TEXT, TEXT) TO CURRENT_USER;
+
+-- the search vectors still hold the plaintext words, the service rebuilds
+-- them from the hashed words when it starts, before answering requests
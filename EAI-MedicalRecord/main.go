@@ -1,28 +1,50 @@
 package main
 
 import (
-	"log"
+	"context"
 
 	"github.com/ravenocx/hospital-mgt/config"
-	"github.com/ravenocx/hospital-mgt/db"
+	dbpkg "github.com/ravenocx/hospital-mgt/db"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+	"github.com/ravenocx/hospital-mgt/sdk/tracing"
 	"github.com/ravenocx/hospital-mgt/server"
 )
 
 func main() {
 	config, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("failed to load config : %+v", err)
+		logging.Fatal("failed to load config", "error", err)
 	}
 
-	db, err := db.OpenConnection(config)
+	if err := logging.Setup("medical-record", config.LogLevel); err != nil {
+		logging.Fatal("failed to set up logging", "error", err)
+	}
+
+	if err := tracing.Init(context.Background(), "medical-record", config.OtelExporterEndpoint); err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
+	}
+
+	db, err := dbpkg.OpenConnection(config)
 	if err != nil {
-		log.Fatalf("failed to connect to db: %v", err)
+		logging.Fatal("failed to connect to db", "error", err)
 	}
 	defer db.Close()
 
-	s := server.NewServer(db, config)
+	if err := dbpkg.SeedCatalogs(context.Background(), db); err != nil {
+		logging.Fatal("failed to seed catalogs", "error", err)
+	}
+
+	keyring, err := envelope.New(config.EncryptionKeys, config.EncryptionActiveKey, config.EncryptionSearchKey)
+	if err != nil {
+		logging.Fatal("failed to load encryption keys", "error", err)
+	}
+
+	s := server.NewServer(db, keyring, config)
 
 	s.RegisterRoute()
 
+	s.StartReencryption(context.Background())
+
 	s.StarApp(config)
 }
\ No newline at end of file
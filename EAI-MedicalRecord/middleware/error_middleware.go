@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+// ErrorHandler answers the errors returned by the handlers as problems. A
+// CustomError keeps its status and code, errors of fiber (unknown route, body
+// too large...) get the code of their status and any other error is a 500.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var custErr responses.CustomError
+	var fiberErr *fiber.Error
+
+	switch {
+	case errors.As(err, &custErr):
+	case errors.As(err, &fiberErr):
+		custErr = responses.CustomError{
+			Message:    fiberErr.Message,
+			StatusCode: fiberErr.Code,
+			Code:       statusCode(fiberErr.Code),
+		}
+	default:
+		custErr = responses.NewInternalServerError(err.Error())
+	}
+
+	requestId, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	problem := responses.NewProblem(custErr, c.OriginalURL(), requestId)
+
+	return c.Status(custErr.StatusCode).JSON(problem, responses.ProblemContentType)
+}
+
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return responses.CodeBadRequest
+	case http.StatusUnauthorized:
+		return responses.CodeUnauthorized
+	case http.StatusForbidden:
+		return responses.CodeForbidden
+	case http.StatusNotFound:
+		return responses.CodeNotFound
+	case http.StatusConflict:
+		return responses.CodeConflict
+	case http.StatusInternalServerError:
+		return responses.CodeInternal
+	case http.StatusServiceUnavailable:
+		return responses.CodeServiceUnavailable
+	}
+
+	// METHOD_NOT_ALLOWED, REQUEST_ENTITY_TOO_LARGE...
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
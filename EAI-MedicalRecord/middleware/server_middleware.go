@@ -1,10 +1,17 @@
 package middleware
 
 import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/contrib/otelfiber/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func FiberMiddleware(a *fiber.App) {
@@ -13,7 +20,63 @@ func FiberMiddleware(a *fiber.App) {
 			AllowOrigins: "*",
 			AllowMethods: "GET,POST,PUT,DELETE",
 		}),
-		logger.New(),
+		// reuses the X-Request-ID of the caller, answered in the header and
+		// in the problems
+		requestid.New(),
+		// a span per request, child of the traceparent of the caller
+		otelfiber.Middleware(otelfiber.WithCustomAttributes(requestIdAttribute)),
+		requestLogger,
 		recover.New(),
 	)
-}
\ No newline at end of file
+}
+
+// requestIdAttribute ties the span to the request id of the logs and the
+// problems.
+func requestIdAttribute(c *fiber.Ctx) []attribute.KeyValue {
+	requestId, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	return []attribute.KeyValue{attribute.String("http.request_id", requestId)}
+}
+
+// requestLogger logs every request once answered, a failed one with its error
+// after the ErrorHandler wrote the problem. The logs written while handling
+// the request with its UserContext carry the request id.
+func requestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+
+	requestId, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	ctx := logging.WithRequestId(c.UserContext(), requestId)
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+	if err != nil {
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	status := c.Response().StatusCode()
+	attrs := []any{
+		"method", c.Method(),
+		"path", c.Path(),
+		"status", status,
+		"latency", time.Since(start),
+		"ip", c.IP(),
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		attrs = append(attrs, "traceId", spanContext.TraceID().String())
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= fiber.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "request", attrs...)
+
+	return nil
+}
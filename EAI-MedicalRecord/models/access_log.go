@@ -0,0 +1,39 @@
+package models
+
+const (
+	AccessTypeList        = "list"
+	AccessTypeSingle      = "single"
+	AccessTypeHistory     = "history"
+	AccessTypeEncounters  = "encounters"
+	AccessTypeVitalSigns  = "vital_signs"
+	AccessTypeMedications = "medications"
+	AccessTypeAmendment   = "amendment"
+)
+
+type RecordAccessLog struct {
+	ID             int64    `json:"id"`
+	ReaderUserId   string   `json:"readerUserId"`
+	ReaderRole     string   `json:"readerRole"`
+	IdentityNumber int64    `json:"identityNumber"`
+	RecordIds      []string `json:"recordIds"`
+	AccessType     string   `json:"accessType"`
+	Reason         *string  `json:"reason"`
+	ClientIP       string   `json:"clientIp"`
+	AccessedAt     string   `json:"accessedAt"`
+	PrevHash       string   `json:"prevHash"`
+	Hash           string   `json:"hash"`
+}
+
+type GetAccessLogQueries struct {
+	IdentityNumber *int64 `json:"identityNumber" query:"identityNumber" validate:"required,identity_number"`
+	Limit          int    `json:"limit" query:"limit"`
+	Offset         int    `json:"offset" query:"offset"`
+}
+
+// AccessLogVerification is the result of walking the hash chain of the access
+// log from its first row.
+type AccessLogVerification struct {
+	Valid      bool   `json:"valid"`
+	Entries    int64  `json:"entries"`              // rows checked
+	BrokenAtId *int64 `json:"brokenAtId,omitempty"` // first row whose hash does not match
+}
@@ -0,0 +1,47 @@
+package models
+
+type RecordAmendmentPayload struct {
+	Symptoms    string `json:"symptoms" form:"symptoms" validate:"required_without=Medications,max=2000"`
+	Medications string `json:"medications" form:"medications" validate:"required_without=Symptoms,max=2000"`
+	Reason      string `json:"reason" form:"reason" validate:"required,min=5,max=500"`
+	// the version the amendment was written against, the amendment is
+	// rejected when the record has been amended since
+	BaseVersion int `json:"baseVersion" form:"baseVersion" validate:"required,min=1"`
+	// only the symptoms and medications are versioned, these are read to turn
+	// away the amendments trying to change the structured content
+	Vitals           []VitalSignPayload       `json:"vitals,omitempty" form:"-" validate:"-"`
+	MedicationOrders []MedicationOrderPayload `json:"medicationOrders,omitempty" form:"-" validate:"-"`
+	Diagnoses        []DiagnosisPayload       `json:"diagnoses,omitempty" form:"-" validate:"-"`
+}
+
+// RecordVersion is the current content of a record.
+type RecordVersion struct {
+	IdentityNumber int64
+	Version        int
+	Symptoms       string
+	Medications    string
+}
+
+type RecordAmendmentResponse struct {
+	RecordId    string `json:"recordId"`
+	Version     int    `json:"version"`
+	Symptoms    string `json:"symptoms"`
+	Medications string `json:"medications"`
+	Reason      string `json:"reason"`
+}
+
+type RecordHistoryResponse struct {
+	Version     int             `json:"version"`
+	Symptoms    string          `json:"symptoms"`
+	Medications string          `json:"medications"`
+	Reason      *string         `json:"reason"`
+	Author      CreatedByDetail `json:"author"`
+	CreatedAt   string          `json:"createdAt"`
+	Changes     []FieldChange   `json:"changes"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
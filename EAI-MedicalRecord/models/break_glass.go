@@ -0,0 +1,38 @@
+package models
+
+const (
+	BreakGlassPending      = "pending"
+	BreakGlassAcknowledged = "acknowledged"
+	BreakGlassEscalated    = "escalated"
+)
+
+type BreakGlassPayload struct {
+	IdentityNumber int64  `json:"identityNumber" form:"identityNumber" validate:"required,identity_number"`
+	Justification  string `json:"justification" form:"justification" validate:"required,min=20,max=1000"`
+}
+
+type BreakGlassGrant struct {
+	ID             string  `json:"id"`
+	UserId         string  `json:"userId"`
+	Role           string  `json:"role"`
+	IdentityNumber int64   `json:"identityNumber"`
+	Justification  string  `json:"justification"`
+	GrantedAt      string  `json:"grantedAt"`
+	ExpiresAt      string  `json:"expiresAt"`
+	ReviewStatus   string  `json:"reviewStatus"`
+	ReviewNote     *string `json:"reviewNote"`
+	ReviewedBy     *string `json:"reviewedByUserId"`
+	ReviewedAt     *string `json:"reviewedAt"`
+	Reads          int     `json:"reads"` // records read with the grant
+}
+
+type GetBreakGlassQueries struct {
+	ReviewStatus string `json:"reviewStatus" query:"reviewStatus" validate:"omitempty,oneof=pending acknowledged escalated"`
+	Limit        int    `json:"limit" query:"limit"`
+	Offset       int    `json:"offset" query:"offset"`
+}
+
+type BreakGlassReviewPayload struct {
+	Decision string `json:"decision" form:"decision" validate:"required,oneof=acknowledged escalated"`
+	Note     string `json:"note" form:"note" validate:"required_if=Decision escalated,max=1000"`
+}
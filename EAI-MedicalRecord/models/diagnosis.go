@@ -0,0 +1,28 @@
+package models
+
+const (
+	DiagnosisPrimary   = "primary"
+	DiagnosisSecondary = "secondary"
+)
+
+type Icd10Code struct {
+	Code        string `db:"code" json:"code"`
+	Description string `db:"description" json:"description"`
+}
+
+type DiagnosisPayload struct {
+	Code string `json:"code" form:"code" validate:"required,max=10"`
+	Type string `json:"type" form:"type" validate:"required,oneof='primary' 'secondary'"`
+}
+
+type GetIcd10Queries struct {
+	Query  string `json:"q" query:"q"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+type DiagnosisResponse struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
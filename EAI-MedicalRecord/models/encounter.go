@@ -0,0 +1,61 @@
+package models
+
+const (
+	EncounterTypeOutpatient = "outpatient"
+	EncounterTypeInpatient  = "inpatient"
+	EncounterTypeEmergency  = "emergency"
+
+	EncounterStatusOpen       = "open"
+	EncounterStatusDischarged = "discharged"
+)
+
+type EncounterRegistrationPayload struct {
+	IdentityNumber    int64    `json:"identityNumber" form:"identityNumber" validate:"required,identity_number"`
+	EncounterType     string   `json:"encounterType" form:"encounterType" validate:"required,oneof='outpatient' 'inpatient' 'emergency'"`
+	AdmittingReason   string   `json:"admittingReason" form:"admittingReason" validate:"required,min=1,max=2000"`
+	Ward              string   `json:"ward" form:"ward" validate:"required_if=EncounterType inpatient,max=50"`
+	AttendingStaffIds []string `json:"attendingStaffIds" form:"attendingStaffIds" validate:"required,min=1,max=20,dive,required"`
+}
+
+type EncounterDischargePayload struct {
+	DischargeSummary string `json:"dischargeSummary" form:"dischargeSummary" validate:"required,min=1,max=4000"`
+}
+
+type GetEncounterQueries struct {
+	IdentityNumber *int64 `json:"identityNumber" query:"identityNumber" validate:"required,identity_number"`
+	Status         string `json:"status" query:"status" validate:"omitempty,oneof='open' 'discharged'"`
+	Limit          int    `json:"limit" query:"limit"`
+	Offset         int    `json:"offset" query:"offset"`
+	AccessibleBy   string `json:"-"` // set internally to the nurse the encounters are restricted to
+}
+
+// Encounter is the minimum needed to attach a record to an encounter.
+type Encounter struct {
+	ID             string `db:"id"`
+	IdentityNumber int64  `db:"identity_number"`
+	Status         string `db:"status"`
+}
+
+type GetEncounterResponse struct {
+	ID                 string            `json:"id"`
+	IdentityNumber     int64             `json:"identityNumber"`
+	EncounterType      string            `json:"encounterType"`
+	AdmittingReason    string            `json:"admittingReason"`
+	Ward               *string           `json:"ward"`
+	Status             string            `json:"status"`
+	AttendingStaffIds  []string          `json:"attendingStaffIds"`
+	OpenedByUserId     string            `json:"openedByUserId"`
+	AdmittedAt         string            `json:"admittedAt"`
+	DischargeSummary   *string           `json:"dischargeSummary"`
+	DischargedByUserId *string           `json:"dischargedByUserId"`
+	DischargedAt       *string           `json:"dischargedAt"`
+	Records            []EncounterRecord `json:"records"`
+}
+
+type EncounterRecord struct {
+	ID          string          `json:"id"`
+	Symptoms    string          `json:"symptoms"`
+	Medications string          `json:"medications"`
+	CreatedBy   CreatedByDetail `json:"createdBy"`
+	CreatedAt   string          `json:"createdAt"`
+}
@@ -13,32 +13,62 @@ type MedicalRecord struct {
 }
 
 type RecordRegistrationPayload struct {
-	IdentityNumber int64  `db:"identity_number" json:"identityNumber" form:"identityNumber" validate:"required,identity_number"`
-	Symptoms       string `db:"symptoms" json:"symptoms" form:"symptoms" validate:"required,min=1,max=2000"`
-	Medications    string `db:"medications" json:"medications" form:"medications" validate:"required,min=1,max=2000"`
+	IdentityNumber   int64                    `db:"identity_number" json:"identityNumber" form:"identityNumber" validate:"required,identity_number"`
+	Symptoms         string                   `db:"symptoms" json:"symptoms" form:"symptoms" validate:"required,min=1,max=2000"`
+	Medications      string                   `db:"medications" json:"medications" form:"medications" validate:"required_without=MedicationOrders,max=2000"`
+	MedicationOrders []MedicationOrderPayload `json:"medicationOrders,omitempty" form:"medicationOrders" validate:"omitempty,max=30,dive"`
+	EncounterId      string                   `db:"encounter_id" json:"encounterId,omitempty" form:"encounterId"`
+	Vitals           []VitalSignPayload       `json:"vitals,omitempty" form:"vitals" validate:"omitempty,max=20,dive"`
+	Diagnoses        []DiagnosisPayload       `json:"diagnoses,omitempty" form:"diagnoses" validate:"omitempty,max=10,dive"`
 }
 
+// GetRecordQueries are the filters of GET /v1/medical/record. Every filter is
+// optional, limit defaults to 5, offset to 0 and createdAt (the sort
+// direction) to desc.
 type GetRecordQueries struct {
-	IdentityNumber  *int64 `db:"identity_number" json:"identityNumber" query:"identityNumber" validate:"required,identity_number"`
+	IdentityNumber  *int64 `db:"identity_number" json:"identityNumber" query:"identityNumber" validate:"omitempty,identity_number"`
 	Limit           int    `json:"limit" query:"limit"`
 	Offset          int    `json:"offset" query:"offset"`
 	CreatedByNip    string `db:"created_by_nip" json:"createdByNip" query:"createdBy.nip"`
 	CreatedByUserId string `db:"created_by_user_id" json:"createdByUserId" query:"createdBy.userId"`
+	CreatedByRole   string `db:"created_by_role" json:"createdByRole" query:"createdBy.role" validate:"omitempty,oneof=admin nurse"`
 	CreatedAt       string `db:"created_at" json:"createdAt" query:"createdAt"`
+	CreatedFrom     string `json:"createdFrom" query:"createdFrom" validate:"omitempty,datetime"` // inclusive, RFC3339
+	CreatedTo       string `json:"createdTo" query:"createdTo" validate:"omitempty,datetime"`     // exclusive, RFC3339
+	Search          string `json:"q" query:"q" validate:"omitempty,max=200"`                      // full-text search over symptoms and medications
+	EncounterId     string `db:"encounter_id" json:"encounterId" query:"encounterId"`
+	DiagnosisCode   string `json:"diagnosisCode" query:"diagnosisCode"`
+	ID              string `db:"id" json:"-"` // set internally to fetch a single record
+	AccessibleBy    string `json:"-"`         // set internally to the nurse the records are restricted to
+}
+
+// RecordRequester is the user reading medical records, the access policy is
+// evaluated against it.
+type RecordRequester struct {
+	UserId   string
+	Role     string
+	Reason   string // required from admins, logged with the access
+	ClientIP string
 }
 
 type GetRecordResponse struct {
-	IdentityDetail PatientDetail   `json:"identityDetail"`
-	Symptoms       string          `json:"symptoms"`
-	Medications    string          `json:"medications"`
-	CreatedBy      CreatedByDetail `json:"createdBy"`
-	CreatedAt      string          `json:"createdAt"`
+	ID               string                    `json:"id"`
+	IdentityDetail   PatientDetail             `json:"identityDetail"`
+	Symptoms         string                    `json:"symptoms"`
+	Medications      string                    `json:"medications"`
+	Vitals           []VitalSignResponse       `json:"vitals"`
+	MedicationOrders []MedicationOrderResponse `json:"medicationOrders"`
+	Diagnoses        []DiagnosisResponse       `json:"diagnoses"`
+	Version          int                       `json:"version"` // number of versions, amendments included
+	CreatedBy        CreatedByDetail           `json:"createdBy"`
+	CreatedAt        string                    `json:"createdAt"`
 }
 
 type CreatedByDetail struct {
 	Nip    string `json:"nip"`
 	Name   string `json:"name"`
 	UserId string `json:"userId"`
+	Role   string `json:"role,omitempty"`
 }
 
 type PatientDetail struct {
@@ -48,30 +78,18 @@ type PatientDetail struct {
 	BirthDate           string `json:"birthDate"`
 	Gender              string `json:"gender"`
 	IdentityCardScanImg string `json:"identityCardScanImg"`
+	Partial             bool   `json:"partial,omitempty"` // the patient could not be found, only identityNumber is set
 }
 
-type NurseResponse struct {
-	Message string  `json:"message"`
-	Data    []Nurse `json:"data"`
-}
-
-type Nurse struct {
-	UserID    string `json:"userId"`
-	NIP       int64  `json:"nip"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"createdAt"`
-}
-
-type PatientResponse struct {
-	Message string  `json:"message"`
-	Data    []Patient `json:"data"`
+type PatientAllergy struct {
+	Substance string `db:"substance" json:"substance"`
+	Reaction  string `db:"reaction" json:"reaction"`
+	Severity  string `db:"severity" json:"severity"`
 }
 
-type Patient struct {
-	IdentityNumber int64  `db:"identity_number" json:"identityNumber"`
-	PhoneNumber    string `db:"phone_number" json:"phoneNumber"`
-	Name           string `db:"name" json:"name"`
-	BirthDate      string `db:"birth_date" json:"birthDate"`
-	Gender         string `db:"gender" json:"gender"`
-	CreatedAt      string `db:"created_at" json:"createdAt"`
+type AllergyWarning struct {
+	Substance string `json:"substance"`
+	Reaction  string `json:"reaction"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
 }
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type Drug struct {
+	Code   string   `db:"code" json:"code"`
+	Name   string   `db:"name" json:"name"`
+	Form   string   `db:"form" json:"form"`
+	Units  []string `db:"units" json:"units"`
+	Routes []string `db:"routes" json:"routes"`
+}
+
+type MedicationOrderPayload struct {
+	DrugCode  string   `json:"drugCode" form:"drugCode" validate:"required,max=20"`
+	Dose      *float64 `json:"dose" form:"dose" validate:"required,gt=0"`
+	DoseUnit  string   `json:"doseUnit" form:"doseUnit" validate:"required,max=20"`
+	Route     string   `json:"route" form:"route" validate:"required,max=20"`
+	Frequency string   `json:"frequency" form:"frequency" validate:"required,oneof='once' 'stat' 'prn' 'qd' 'bid' 'tid' 'qid' 'q4h' 'q6h' 'q8h' 'q12h' 'qhs' 'continuous'"`
+	StartAt   string   `json:"startAt" form:"startAt" validate:"omitempty,datetime"`
+	StopAt    string   `json:"stopAt" form:"stopAt" validate:"omitempty,datetime"`
+}
+
+// MedicationOrder is an order checked against the drug catalog.
+type MedicationOrder struct {
+	DrugCode  string
+	DrugName  string
+	Dose      float64
+	DoseUnit  string
+	Route     string
+	Frequency string
+	StartAt   time.Time
+	StopAt    *time.Time
+}
+
+type GetDrugQueries struct {
+	Name   string `json:"name" query:"name"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+type MedicationOrderResponse struct {
+	ID           string          `json:"id"`
+	DrugCode     string          `json:"drugCode"`
+	DrugName     string          `json:"drugName"`
+	Dose         float64         `json:"dose"`
+	DoseUnit     string          `json:"doseUnit"`
+	Route        string          `json:"route"`
+	Frequency    string          `json:"frequency"`
+	StartAt      string          `json:"startAt"`
+	StopAt       *string         `json:"stopAt"`
+	PrescribedBy CreatedByDetail `json:"prescribedBy"`
+	RecordId     string          `json:"-"` // the record it was prescribed in, for the access log
+}
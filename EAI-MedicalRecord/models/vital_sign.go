@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+const (
+	VitalTemperature     = "temperature"
+	VitalBloodPressure   = "blood_pressure"
+	VitalPulse           = "pulse"
+	VitalRespiratoryRate = "respiratory_rate"
+	VitalSpO2            = "spo2"
+	VitalWeight          = "weight"
+	VitalHeight          = "height"
+	VitalPainScore       = "pain_score"
+)
+
+type VitalSignPayload struct {
+	Type       string   `json:"type" form:"type" validate:"required,oneof='temperature' 'blood_pressure' 'pulse' 'respiratory_rate' 'spo2' 'weight' 'height' 'pain_score'"`
+	Value      *float64 `json:"value" form:"value" validate:"required_unless=Type blood_pressure"`
+	Systolic   *float64 `json:"systolic" form:"systolic" validate:"required_if=Type blood_pressure"`
+	Diastolic  *float64 `json:"diastolic" form:"diastolic" validate:"required_if=Type blood_pressure"`
+	Unit       string   `json:"unit" form:"unit" validate:"max=20"`
+	MeasuredAt string   `json:"measuredAt" form:"measuredAt" validate:"omitempty,datetime"`
+}
+
+// VitalSign is a validated entry converted to the canonical unit of its type.
+// For blood pressure Value holds the systolic pressure.
+type VitalSign struct {
+	Type       string
+	Value      float64
+	Diastolic  *float64
+	Unit       string
+	MeasuredAt time.Time
+}
+
+type VitalSignResponse struct {
+	Type       string   `json:"type"`
+	Value      *float64 `json:"value,omitempty"`
+	Systolic   *float64 `json:"systolic,omitempty"`
+	Diastolic  *float64 `json:"diastolic,omitempty"`
+	Unit       string   `json:"unit"`
+	MeasuredAt string   `json:"measuredAt"`
+	RecordId   string   `json:"-"` // the record it was measured in, for the access log
+}
+
+type GetVitalSignQueries struct {
+	IdentityNumber *int64 `json:"identityNumber" query:"identityNumber" validate:"required,identity_number"`
+	Type           string `json:"type" query:"type" validate:"omitempty,oneof='temperature' 'blood_pressure' 'pulse' 'respiratory_rate' 'spo2' 'weight' 'height' 'pain_score'"`
+	From           string `json:"from" query:"from" validate:"omitempty,datetime"`
+	To             string `json:"to" query:"to" validate:"omitempty,datetime"`
+}
+
+// VitalSignSeries is every measurement of one vital sign type of a patient,
+// oldest first, ready to be charted.
+type VitalSignSeries struct {
+	Type   string           `json:"type"`
+	Unit   string           `json:"unit"`
+	Points []VitalSignPoint `json:"points"`
+}
+
+type VitalSignPoint struct {
+	Value      *float64 `json:"value,omitempty"`
+	Systolic   *float64 `json:"systolic,omitempty"`
+	Diastolic  *float64 `json:"diastolic,omitempty"`
+	MeasuredAt string   `json:"measuredAt"`
+}
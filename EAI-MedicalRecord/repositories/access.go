@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+)
+
+// nurseAccessCondition matches the patients a nurse can read: the ones with an
+// open encounter in a ward the nurse is assigned to or one the nurse is part
+// of, and the ones the nurse holds an active break-the-glass grant for.
+// identityNumber and userId are SQL expressions, a column or a placeholder.
+func nurseAccessCondition(identityNumber string, userId string) string {
+	return fmt.Sprintf(`(EXISTS (SELECT 1 FROM encounters WHERE encounters.identity_number = %[1]s AND encounters.status = 'open' AND (
+		lower(encounters.ward) IN (SELECT lower(ward) FROM nurse_ward_assignments WHERE nurse_ward_assignments.user_id = %[2]s)
+		OR EXISTS (SELECT 1 FROM encounter_staff WHERE encounter_staff.encounter_id = encounters.id AND encounter_staff.user_id = %[2]s)))
+	OR EXISTS (SELECT 1 FROM break_glass_grants WHERE break_glass_grants.identity_number = %[1]s AND break_glass_grants.user_id = %[2]s AND break_glass_grants.expires_at > CURRENT_TIMESTAMP))`,
+		identityNumber, userId)
+}
+
+func (r *medicalRecordRepositories) CanNurseAccessPatient(ctx context.Context, userId string, patientIdentityNumber int64) (bool, error) {
+	var allowed bool
+	query := "SELECT " + nurseAccessCondition("$1", "$2")
+
+	row := r.db.QueryRow(ctx, query, patientIdentityNumber, userId)
+	if err := row.Scan(&allowed); err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
+)
+
+type AccessLogRepositories interface {
+	CreateAccessLogs(ctx context.Context, entries []models.RecordAccessLog) error
+	GetAccessLogs(ctx context.Context, filter models.GetAccessLogQueries) ([]models.RecordAccessLog, error)
+	VerifyAccessLogs(ctx context.Context) (*models.AccessLogVerification, error)
+}
+
+type accessLogRepositories struct {
+	db *pgxpool.Pool
+}
+
+func NewAccessLogRepo(db *pgxpool.Pool) AccessLogRepositories {
+	return &accessLogRepositories{db}
+}
+
+// accessLogLockKey is the advisory lock serializing the writers of the access
+// log, each row needs the hash of the one before it.
+const accessLogLockKey = 7_304_202_406
+
+// genesisHash is the previous hash of the first row of the chain.
+var genesisHash = strings.Repeat("0", 64)
+
+const accessLogColumns = "id, reader_user_id, reader_role, identity_number, record_ids, access_type, reason, client_ip, accessed_at, prev_hash, hash"
+
+// CreateAccessLogs appends the entries to the chain, their ID, AccessedAt,
+// PrevHash and Hash are set here.
+func (r *accessLogRepositories) CreateAccessLogs(ctx context.Context, entries []models.RecordAccessLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", accessLogLockKey); err != nil {
+		return err
+	}
+
+	prevHash := genesisHash
+	err = tx.QueryRow(ctx, "SELECT hash FROM record_access_logs ORDER BY id DESC LIMIT 1").Scan(&prevHash)
+	if err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+
+	// the database keeps microseconds, the hash must be computed on what is stored
+	accessedAt := time.Now().UTC().Truncate(time.Microsecond)
+	statement := "INSERT INTO record_access_logs (reader_user_id, reader_role, identity_number, record_ids, access_type, reason, client_ip, accessed_at, prev_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id"
+
+	for i := range entries {
+		entry := &entries[i]
+		entry.AccessedAt = accessedAt.Format(time.RFC3339Nano)
+		entry.PrevHash = prevHash
+		entry.Hash, err = accessLogHash(entry)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRow(ctx, statement, entry.ReaderUserId, entry.ReaderRole, entry.IdentityNumber, entry.RecordIds, entry.AccessType, entry.Reason, entry.ClientIP, accessedAt, entry.PrevHash, entry.Hash)
+		if err := row.Scan(&entry.ID); err != nil {
+			return err
+		}
+
+		prevHash = entry.Hash
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *accessLogRepositories) GetAccessLogs(ctx context.Context, filter models.GetAccessLogQueries) ([]models.RecordAccessLog, error) {
+	var entries []models.RecordAccessLog
+	query := "SELECT " + accessLogColumns + " FROM record_access_logs"
+
+	qb := querybuilder.New()
+	qb.Equal("identity_number", *filter.IdentityNumber)
+
+	query += qb.WhereClause()
+	query += " ORDER BY id DESC"
+	query += qb.Limit(filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAccessLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// VerifyAccessLogs walks the whole chain and stops at the first row that does
+// not match its content or the row before it.
+func (r *accessLogRepositories) VerifyAccessLogs(ctx context.Context) (*models.AccessLogVerification, error) {
+	verification := models.AccessLogVerification{Valid: true}
+
+	rows, err := r.db.Query(ctx, "SELECT "+accessLogColumns+" FROM record_access_logs ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prevHash := genesisHash
+	for rows.Next() {
+		entry, err := scanAccessLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		verification.Entries++
+
+		hash, err := accessLogHash(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.PrevHash != prevHash || entry.Hash != hash {
+			verification.Valid = false
+			verification.BrokenAtId = &entry.ID
+			return &verification, nil
+		}
+		prevHash = entry.Hash
+	}
+
+	return &verification, rows.Err()
+}
+
+func scanAccessLog(row pgx.Row) (*models.RecordAccessLog, error) {
+	var entry models.RecordAccessLog
+	var accessedAt time.Time
+
+	err := row.Scan(&entry.ID, &entry.ReaderUserId, &entry.ReaderRole, &entry.IdentityNumber, &entry.RecordIds, &entry.AccessType, &entry.Reason, &entry.ClientIP, &accessedAt, &entry.PrevHash, &entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	entry.AccessedAt = accessedAt.UTC().Format(time.RFC3339Nano)
+
+	return &entry, nil
+}
+
+// accessLogHash is the SHA-256 of the content of the entry and the hash of the
+// entry before it. The id is left out, it is only known once inserted.
+func accessLogHash(entry *models.RecordAccessLog) (string, error) {
+	recordIds := entry.RecordIds
+	if recordIds == nil {
+		recordIds = []string{}
+	}
+
+	content, err := json.Marshal(struct {
+		PrevHash       string   `json:"prevHash"`
+		ReaderUserId   string   `json:"readerUserId"`
+		ReaderRole     string   `json:"readerRole"`
+		IdentityNumber int64    `json:"identityNumber"`
+		RecordIds      []string `json:"recordIds"`
+		AccessType     string   `json:"accessType"`
+		Reason         *string  `json:"reason"`
+		ClientIP       string   `json:"clientIp"`
+		AccessedAt     string   `json:"accessedAt"`
+	}{entry.PrevHash, entry.ReaderUserId, entry.ReaderRole, entry.IdentityNumber, recordIds, entry.AccessType, entry.Reason, entry.ClientIP, entry.AccessedAt})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ravenocx/hospital-mgt/models"
+)
+
+// latestVersionJoin joins the latest amendment of every record, if any, as
+// latest_version, latest_symptoms and latest_medications.
+const latestVersionJoin = ` LEFT JOIN LATERAL (
+	SELECT version AS latest_version, symptoms AS latest_symptoms, medications AS latest_medications
+	FROM medical_record_amendments WHERE medical_record_amendments.record_id = medical_records.id
+	ORDER BY version DESC LIMIT 1
+) AS latest ON true`
+
+func (r *medicalRecordRepositories) GetRecordVersion(ctx context.Context, recordId string) (*models.RecordVersion, error) {
+	var version models.RecordVersion
+	query := "SELECT identity_number, COALESCE(latest_version, 1), COALESCE(latest_symptoms, symptoms), COALESCE(latest_medications, medications) FROM medical_records" + latestVersionJoin + " WHERE id = $1"
+
+	row := r.db.QueryRow(ctx, query, recordId)
+	if err := row.Scan(&version.IdentityNumber, &version.Version, &version.Symptoms, &version.Medications); err != nil {
+		return nil, err
+	}
+
+	if err := r.keyring.DecryptAll(&version.Symptoms, &version.Medications); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// CreateAmendment adds the given version of the record and moves the search
+// vector of the record to it. Two amendments written against the same version
+// conflict on the (record_id, version) unique constraint, only the first one
+// is kept.
+func (r *medicalRecordRepositories) CreateAmendment(ctx context.Context, recordId string, version int, amendment *models.RecordAmendmentPayload, amendedBy *models.CreatedByDetail) error {
+	statement := "INSERT INTO medical_record_amendments (record_id, version, symptoms, medications, reason, amended_by_nip, amended_by_name, amended_by_user_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+
+	symptoms, err := r.keyring.Encrypt(amendment.Symptoms)
+	if err != nil {
+		return err
+	}
+
+	medications, err := r.keyring.Encrypt(amendment.Medications)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, statement, recordId, version, symptoms, medications, amendment.Reason, amendedBy.Nip, amendedBy.Name, amendedBy.UserId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE medical_records SET search_vector = $2::tsvector WHERE id = $1", recordId, searchVector(r.keyring, amendment.Symptoms, amendment.Medications))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetRecordHistory returns every version of the record, the original first.
+func (r *medicalRecordRepositories) GetRecordHistory(ctx context.Context, recordId string) ([]models.RecordHistoryResponse, error) {
+	var history []models.RecordHistoryResponse
+	query := `SELECT 1, symptoms, medications, NULL, created_by_nip, created_by_name, created_by_user_id, created_at FROM medical_records WHERE id = $1
+		UNION ALL
+		SELECT version, symptoms, medications, reason, amended_by_nip, amended_by_name, amended_by_user_id, amended_at FROM medical_record_amendments WHERE record_id = $1
+		ORDER BY 1`
+
+	rows, err := r.db.Query(ctx, query, recordId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		version := models.RecordHistoryResponse{Changes: []models.FieldChange{}}
+		var createdAt time.Time
+
+		err := rows.Scan(&version.Version, &version.Symptoms, &version.Medications, &version.Reason, &version.Author.Nip, &version.Author.Name, &version.Author.UserId, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.keyring.DecryptAll(&version.Symptoms, &version.Medications); err != nil {
+			return nil, err
+		}
+		version.CreatedAt = createdAt.Format(time.RFC3339Nano)
+
+		history = append(history, version)
+	}
+
+	return history, rows.Err()
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
+)
+
+type BreakGlassRepositories interface {
+	CreateGrant(ctx context.Context, requester models.RecordRequester, grant *models.BreakGlassPayload, duration time.Duration) (*models.BreakGlassGrant, error)
+	GetGrant(ctx context.Context, grantId string) (*models.BreakGlassGrant, error)
+	GetGrants(ctx context.Context, filter models.GetBreakGlassQueries) ([]models.BreakGlassGrant, error)
+	GetActiveGrants(ctx context.Context, userId string) (map[int64]string, error)
+	CreateReads(ctx context.Context, grantId string, recordIds []string) error
+	ReviewGrant(ctx context.Context, grantId string, review *models.BreakGlassReviewPayload, reviewedBy string) (pgconn.CommandTag, error)
+}
+
+type breakGlassRepositories struct {
+	db *pgxpool.Pool
+}
+
+func NewBreakGlassRepo(db *pgxpool.Pool) BreakGlassRepositories {
+	return &breakGlassRepositories{db}
+}
+
+const breakGlassColumns = "id, user_id, role, identity_number, justification, granted_at, expires_at, review_status, review_note, reviewed_by_user_id, reviewed_at"
+
+func (r *breakGlassRepositories) CreateGrant(ctx context.Context, requester models.RecordRequester, grant *models.BreakGlassPayload, duration time.Duration) (*models.BreakGlassGrant, error) {
+	statement := "INSERT INTO break_glass_grants (user_id, role, identity_number, justification, expires_at) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second') RETURNING " + breakGlassColumns
+
+	row := r.db.QueryRow(ctx, statement, requester.UserId, requester.Role, grant.IdentityNumber, grant.Justification, int64(duration/time.Second))
+
+	return scanBreakGlassGrant(row, false)
+}
+
+func (r *breakGlassRepositories) GetGrant(ctx context.Context, grantId string) (*models.BreakGlassGrant, error) {
+	query := "SELECT " + breakGlassColumns + ", (SELECT count(*) FROM break_glass_reads WHERE grant_id = break_glass_grants.id) FROM break_glass_grants WHERE id = $1"
+
+	row := r.db.QueryRow(ctx, query, grantId)
+
+	return scanBreakGlassGrant(row, true)
+}
+
+// GetGrants is the review queue, oldest first so nothing waits forever.
+func (r *breakGlassRepositories) GetGrants(ctx context.Context, filter models.GetBreakGlassQueries) ([]models.BreakGlassGrant, error) {
+	var grants []models.BreakGlassGrant
+	query := "SELECT " + breakGlassColumns + ", (SELECT count(*) FROM break_glass_reads WHERE grant_id = break_glass_grants.id) FROM break_glass_grants"
+
+	qb := querybuilder.New()
+	if filter.ReviewStatus != "" {
+		qb.Equal("review_status", filter.ReviewStatus)
+	}
+
+	query += qb.WhereClause()
+	query += " ORDER BY granted_at ASC"
+	query += qb.Limit(filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		grant, err := scanBreakGlassGrant(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, *grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// GetActiveGrants returns the grant ids of the user that have not expired,
+// keyed by patient identity number.
+func (r *breakGlassRepositories) GetActiveGrants(ctx context.Context, userId string) (map[int64]string, error) {
+	grants := map[int64]string{}
+	query := "SELECT identity_number, id FROM break_glass_grants WHERE user_id = $1 AND expires_at > CURRENT_TIMESTAMP ORDER BY granted_at"
+
+	rows, err := r.db.Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var identityNumber int64
+		var grantId string
+		if err := rows.Scan(&identityNumber, &grantId); err != nil {
+			return nil, err
+		}
+		// the latest grant wins when there are several for a patient
+		grants[identityNumber] = grantId
+	}
+
+	return grants, rows.Err()
+}
+
+func (r *breakGlassRepositories) CreateReads(ctx context.Context, grantId string, recordIds []string) error {
+	statement := "INSERT INTO break_glass_reads (grant_id, record_id) SELECT $1, unnest($2::uuid[])"
+
+	_, err := r.db.Exec(ctx, statement, grantId, recordIds)
+
+	return err
+}
+
+// ReviewGrant records the decision of an admin, a grant is only reviewed once.
+func (r *breakGlassRepositories) ReviewGrant(ctx context.Context, grantId string, review *models.BreakGlassReviewPayload, reviewedBy string) (pgconn.CommandTag, error) {
+	statement := "UPDATE break_glass_grants SET review_status = $1, review_note = NULLIF($2, ''), reviewed_by_user_id = $3, reviewed_at = CURRENT_TIMESTAMP WHERE id = $4 AND review_status = 'pending'"
+
+	return r.db.Exec(ctx, statement, review.Decision, review.Note, reviewedBy, grantId)
+}
+
+// scanBreakGlassGrant scans breakGlassColumns, followed by the number of reads
+// when withReads is set.
+func scanBreakGlassGrant(row pgx.Row, withReads bool) (*models.BreakGlassGrant, error) {
+	var grant models.BreakGlassGrant
+	var grantedAt, expiresAt time.Time
+	var reviewedAt *time.Time
+
+	dest := []interface{}{&grant.ID, &grant.UserId, &grant.Role, &grant.IdentityNumber, &grant.Justification, &grantedAt, &expiresAt, &grant.ReviewStatus, &grant.ReviewNote, &grant.ReviewedBy, &reviewedAt}
+	if withReads {
+		dest = append(dest, &grant.Reads)
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	grant.GrantedAt = grantedAt.Format(time.RFC3339Nano)
+	grant.ExpiresAt = expiresAt.Format(time.RFC3339Nano)
+	if reviewedAt != nil {
+		formatted := reviewedAt.Format(time.RFC3339Nano)
+		grant.ReviewedAt = &formatted
+	}
+
+	return &grant, nil
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
+)
+
+type DiagnosisRepositories interface {
+	GetIcd10Codes(ctx context.Context, codes []string) (map[string]models.Icd10Code, error)
+	SearchIcd10Codes(ctx context.Context, filter models.GetIcd10Queries) ([]models.Icd10Code, error)
+}
+
+type diagnosisRepositories struct {
+	db *pgxpool.Pool
+}
+
+func NewDiagnosisRepo(db *pgxpool.Pool) DiagnosisRepositories {
+	return &diagnosisRepositories{db}
+}
+
+// GetIcd10Codes returns the catalog entries of the given codes keyed by code,
+// unknown codes are simply missing from the map.
+func (r *diagnosisRepositories) GetIcd10Codes(ctx context.Context, codes []string) (map[string]models.Icd10Code, error) {
+	icd10Codes := map[string]models.Icd10Code{}
+	query := "SELECT code, description FROM icd10_codes WHERE code = ANY($1)"
+
+	rows, err := r.db.Query(ctx, query, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var icd10Code models.Icd10Code
+		if err := rows.Scan(&icd10Code.Code, &icd10Code.Description); err != nil {
+			return nil, err
+		}
+		icd10Codes[icd10Code.Code] = icd10Code
+	}
+
+	return icd10Codes, rows.Err()
+}
+
+// SearchIcd10Codes matches the query as a code prefix or anywhere in the
+// description. Code matches come first so typing a code autocompletes it.
+func (r *diagnosisRepositories) SearchIcd10Codes(ctx context.Context, filter models.GetIcd10Queries) ([]models.Icd10Code, error) {
+	var icd10Codes []models.Icd10Code
+
+	qb := querybuilder.New()
+	orderBy := " ORDER BY code"
+
+	if filter.Query != "" {
+		escaped := querybuilder.EscapeLike(filter.Query)
+		codePrefix := qb.Arg(escaped + "%")
+		qb.Where("(code ILIKE "+codePrefix+" OR description ILIKE ?)", "%"+escaped+"%")
+		orderBy = " ORDER BY code ILIKE " + codePrefix + " DESC, code"
+	}
+
+	query := "SELECT code, description FROM icd10_codes" + qb.WhereClause() + orderBy + qb.Limit(filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var icd10Code models.Icd10Code
+		if err := rows.Scan(&icd10Code.Code, &icd10Code.Description); err != nil {
+			return nil, err
+		}
+		icd10Codes = append(icd10Codes, icd10Code)
+	}
+
+	return icd10Codes, rows.Err()
+}
+
+func createDiagnoses(ctx context.Context, tx pgx.Tx, recordId string, diagnoses []models.DiagnosisPayload) error {
+	statement := "INSERT INTO record_diagnoses (record_id, code, diagnosis_type) VALUES ($1, $2, $3)"
+
+	for _, diagnosis := range diagnoses {
+		if _, err := tx.Exec(ctx, statement, recordId, diagnosis.Code, diagnosis.Type); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
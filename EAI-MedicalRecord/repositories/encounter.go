@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
+)
+
+type EncounterRepositories interface {
+	CreateEncounter(ctx context.Context, encounter *models.EncounterRegistrationPayload, openedBy string) (string, error)
+	GetEncounter(ctx context.Context, encounterId string) (*models.Encounter, error)
+	GetOpenEncounter(ctx context.Context, identityNumber int64) (*models.Encounter, error)
+	GetEncounters(ctx context.Context, filter models.GetEncounterQueries) ([]models.GetEncounterResponse, error)
+	DischargeEncounter(ctx context.Context, encounterId string, summary string, dischargedBy string) (pgconn.CommandTag, error)
+	CountUsers(ctx context.Context, userIds []string) (int, error)
+}
+
+type encounterRepositories struct {
+	db      *pgxpool.Pool
+	keyring *envelope.Keyring
+}
+
+func NewEncounterRepo(db *pgxpool.Pool, keyring *envelope.Keyring) EncounterRepositories {
+	return &encounterRepositories{db, keyring}
+}
+
+// CreateEncounter opens the encounter and assigns its attending staff in a
+// single transaction.
+func (r *encounterRepositories) CreateEncounter(ctx context.Context, encounter *models.EncounterRegistrationPayload, openedBy string) (string, error) {
+	var id string
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	statement := "INSERT INTO encounters (identity_number, encounter_type, admitting_reason, ward, opened_by_user_id) VALUES ($1, $2, $3, NULLIF($4, ''), $5) RETURNING id"
+
+	row := tx.QueryRow(ctx, statement, encounter.IdentityNumber, encounter.EncounterType, encounter.AdmittingReason, encounter.Ward, openedBy)
+	if err := row.Scan(&id); err != nil {
+		return "", err
+	}
+
+	for _, userId := range encounter.AttendingStaffIds {
+		_, err := tx.Exec(ctx, "INSERT INTO encounter_staff (encounter_id, user_id) VALUES ($1, $2)", id, userId)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return id, tx.Commit(ctx)
+}
+
+func (r *encounterRepositories) GetEncounter(ctx context.Context, encounterId string) (*models.Encounter, error) {
+	var encounter models.Encounter
+	query := "SELECT id, identity_number, status FROM encounters WHERE id = $1"
+
+	row := r.db.QueryRow(ctx, query, encounterId)
+	if err := row.Scan(&encounter.ID, &encounter.IdentityNumber, &encounter.Status); err != nil {
+		return nil, err
+	}
+
+	return &encounter, nil
+}
+
+func (r *encounterRepositories) GetOpenEncounter(ctx context.Context, identityNumber int64) (*models.Encounter, error) {
+	var encounter models.Encounter
+	query := "SELECT id, identity_number, status FROM encounters WHERE identity_number = $1 AND status = 'open'"
+
+	row := r.db.QueryRow(ctx, query, identityNumber)
+	if err := row.Scan(&encounter.ID, &encounter.IdentityNumber, &encounter.Status); err != nil {
+		return nil, err
+	}
+
+	return &encounter, nil
+}
+
+// GetEncounters returns the encounters with their staff and records nested.
+// Staff and records are loaded with one query each for the whole page.
+func (r *encounterRepositories) GetEncounters(ctx context.Context, filter models.GetEncounterQueries) ([]models.GetEncounterResponse, error) {
+	var encounters []models.GetEncounterResponse
+
+	query := "SELECT id, identity_number, encounter_type, admitting_reason, ward, status, opened_by_user_id, admitted_at, discharge_summary, discharged_by_user_id, discharged_at FROM encounters listed"
+
+	qb := getEncounterConstructWhereQuery(filter)
+	query += qb.WhereClause()
+	query += " ORDER BY admitted_at DESC"
+	query += qb.Limit(filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := map[string]int{}
+	ids := []string{}
+
+	for rows.Next() {
+		encounter := models.GetEncounterResponse{
+			AttendingStaffIds: []string{},
+			Records:           []models.EncounterRecord{},
+		}
+		var admittedAt time.Time
+		var dischargedAt *time.Time
+
+		err := rows.Scan(&encounter.ID, &encounter.IdentityNumber, &encounter.EncounterType, &encounter.AdmittingReason, &encounter.Ward, &encounter.Status, &encounter.OpenedByUserId, &admittedAt, &encounter.DischargeSummary, &encounter.DischargedByUserId, &dischargedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		encounter.AdmittedAt = admittedAt.Format(time.RFC3339Nano)
+		if dischargedAt != nil {
+			formatted := dischargedAt.Format(time.RFC3339Nano)
+			encounter.DischargedAt = &formatted
+		}
+
+		index[encounter.ID] = len(encounters)
+		ids = append(ids, encounter.ID)
+		encounters = append(encounters, encounter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return encounters, nil
+	}
+
+	staffRows, err := r.db.Query(ctx, "SELECT encounter_id, user_id FROM encounter_staff WHERE encounter_id = ANY($1) ORDER BY added_at", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer staffRows.Close()
+
+	for staffRows.Next() {
+		var encounterId, userId string
+		if err := staffRows.Scan(&encounterId, &userId); err != nil {
+			return nil, err
+		}
+
+		i := index[encounterId]
+		encounters[i].AttendingStaffIds = append(encounters[i].AttendingStaffIds, userId)
+	}
+	if err := staffRows.Err(); err != nil {
+		return nil, err
+	}
+
+	recordRows, err := r.db.Query(ctx, "SELECT encounter_id, id, COALESCE(latest_symptoms, symptoms), COALESCE(latest_medications, medications), created_by_nip, created_by_name, created_by_user_id, created_at FROM medical_records"+latestVersionJoin+" WHERE encounter_id = ANY($1) ORDER BY created_at", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer recordRows.Close()
+
+	for recordRows.Next() {
+		var encounterId string
+		var createdAt time.Time
+		record := models.EncounterRecord{}
+
+		err := recordRows.Scan(&encounterId, &record.ID, &record.Symptoms, &record.Medications, &record.CreatedBy.Nip, &record.CreatedBy.Name, &record.CreatedBy.UserId, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.keyring.DecryptAll(&record.Symptoms, &record.Medications); err != nil {
+			return nil, err
+		}
+		record.CreatedAt = createdAt.Format(time.RFC3339Nano)
+
+		i := index[encounterId]
+		encounters[i].Records = append(encounters[i].Records, record)
+	}
+
+	return encounters, recordRows.Err()
+}
+
+func (r *encounterRepositories) DischargeEncounter(ctx context.Context, encounterId string, summary string, dischargedBy string) (pgconn.CommandTag, error) {
+	statement := "UPDATE encounters SET status = 'discharged', discharge_summary = $1, discharged_by_user_id = $2, discharged_at = $3 WHERE id = $4 AND status = 'open'"
+
+	res, err := r.db.Exec(ctx, statement, summary, dischargedBy, time.Now(), encounterId)
+
+	return res, err
+}
+
+// CountUsers returns how many of the given ids belong to existing users.
+func (r *encounterRepositories) CountUsers(ctx context.Context, userIds []string) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM users WHERE id = ANY($1)"
+
+	row := r.db.QueryRow(ctx, query, userIds)
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func getEncounterConstructWhereQuery(filter models.GetEncounterQueries) *querybuilder.Builder {
+	qb := querybuilder.New()
+
+	if filter.AccessibleBy != "" {
+		// aliased, the condition looks up the encounters table itself
+		qb.Where(nurseAccessCondition("listed.identity_number", "?"), filter.AccessibleBy, filter.AccessibleBy, filter.AccessibleBy)
+	}
+
+	if filter.IdentityNumber != nil {
+		qb.Equal("identity_number", *filter.IdentityNumber)
+	}
+
+	if filter.Status != "" {
+		qb.Equal("status", filter.Status)
+	}
+
+	return qb
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+)
+
+// EncryptionRepositories moves the encrypted columns of the records and their
+// amendments to the active master key. Rows still in plaintext are encrypted
+// the same way.
+type EncryptionRepositories interface {
+	RebuildSearchVectors(ctx context.Context, after string, limit int) (string, int, error)
+	ReencryptRecords(ctx context.Context, limit int) (int, error)
+	ReencryptAmendments(ctx context.Context, limit int) (int, error)
+}
+
+type encryptionRepositories struct {
+	db      *pgxpool.Pool
+	keyring *envelope.Keyring
+}
+
+func NewEncryptionRepo(db *pgxpool.Pool, keyring *envelope.Keyring) EncryptionRepositories {
+	return &encryptionRepositories{db, keyring}
+}
+
+type reencryptRow struct {
+	id                string
+	symptoms          string
+	medications       string
+	latestSymptoms    string
+	latestMedications string
+}
+
+// RebuildSearchVectors replaces the plaintext search vectors of up to limit
+// records written before encryption, the ones with an id after the given one,
+// by vectors of hashed words. It returns the last id rebuilt, and leaves the
+// records themselves to the re-encryption job.
+func (r *encryptionRepositories) RebuildSearchVectors(ctx context.Context, after string, limit int) (string, int, error) {
+	query := "SELECT id, COALESCE(latest_symptoms, symptoms), COALESCE(latest_medications, medications) FROM medical_records" + latestVersionJoin +
+		" WHERE symptoms NOT LIKE 'enc:%' AND id > $1 ORDER BY id LIMIT $2"
+
+	rows, err := r.db.Query(ctx, query, after, limit)
+	if err != nil {
+		return "", 0, err
+	}
+
+	records := []reencryptRow{}
+	for rows.Next() {
+		var record reencryptRow
+		if err := rows.Scan(&record.id, &record.latestSymptoms, &record.latestMedications); err != nil {
+			rows.Close()
+			return "", 0, err
+		}
+		records = append(records, record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", 0, err
+	}
+
+	for _, record := range records {
+		// the latest version can be an amendment written since, encrypted
+		if err := r.keyring.DecryptAll(&record.latestSymptoms, &record.latestMedications); err != nil {
+			return "", 0, err
+		}
+
+		_, err := r.db.Exec(ctx, "UPDATE medical_records SET search_vector = $2::tsvector WHERE id = $1",
+			record.id, searchVector(r.keyring, record.latestSymptoms, record.latestMedications))
+		if err != nil {
+			return "", 0, err
+		}
+		after = record.id
+	}
+
+	return after, len(records), nil
+}
+
+// ReencryptRecords re-encrypts up to limit records and rebuilds their search
+// vector from the latest version, which also replaces the plaintext vectors
+// of records written before encryption.
+func (r *encryptionRepositories) ReencryptRecords(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := "SELECT id, symptoms, medications, COALESCE(latest_symptoms, symptoms), COALESCE(latest_medications, medications) FROM medical_records" + latestVersionJoin +
+		" WHERE symptoms NOT LIKE $1 || '%' OR medications NOT LIKE $1 || '%' ORDER BY id LIMIT $2 FOR UPDATE OF medical_records SKIP LOCKED"
+
+	rows, err := tx.Query(ctx, query, r.keyring.ActivePrefix(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// the rows are read before updating, the transaction runs one query at a time
+	records := []reencryptRow{}
+	for rows.Next() {
+		var record reencryptRow
+		if err := rows.Scan(&record.id, &record.symptoms, &record.medications, &record.latestSymptoms, &record.latestMedications); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		records = append(records, record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, record := range records {
+		if err := r.keyring.DecryptAll(&record.symptoms, &record.medications, &record.latestSymptoms, &record.latestMedications); err != nil {
+			return 0, err
+		}
+
+		symptoms, err := r.keyring.Encrypt(record.symptoms)
+		if err != nil {
+			return 0, err
+		}
+
+		medications, err := r.keyring.Encrypt(record.medications)
+		if err != nil {
+			return 0, err
+		}
+
+		// the immutability trigger only lets the function change them
+		_, err = tx.Exec(ctx, "SELECT reencrypt_medical_record($1, $2, $3, $4::tsvector)",
+			record.id, symptoms, medications, searchVector(r.keyring, record.latestSymptoms, record.latestMedications))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(records), tx.Commit(ctx)
+}
+
+// ReencryptAmendments re-encrypts up to limit amendments.
+func (r *encryptionRepositories) ReencryptAmendments(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := "SELECT id, symptoms, medications FROM medical_record_amendments WHERE symptoms NOT LIKE $1 || '%' OR medications NOT LIKE $1 || '%' ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED"
+
+	rows, err := tx.Query(ctx, query, r.keyring.ActivePrefix(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	amendments := []reencryptRow{}
+	for rows.Next() {
+		var amendment reencryptRow
+		if err := rows.Scan(&amendment.id, &amendment.symptoms, &amendment.medications); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		amendments = append(amendments, amendment)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, amendment := range amendments {
+		if err := r.keyring.DecryptAll(&amendment.symptoms, &amendment.medications); err != nil {
+			return 0, err
+		}
+
+		symptoms, err := r.keyring.Encrypt(amendment.symptoms)
+		if err != nil {
+			return 0, err
+		}
+
+		medications, err := r.keyring.Encrypt(amendment.medications)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, "SELECT reencrypt_medical_record_amendment($1, $2, $3)", amendment.id, symptoms, medications)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(amendments), tx.Commit(ctx)
+}
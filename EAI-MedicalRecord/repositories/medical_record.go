@@ -3,25 +3,37 @@ package repositories
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
 )
 
 type MedicalRecordRepositories interface {
 	GetPatient(ctx context.Context, patientIdentityNumber int64) (string, error)
-	CreateRecord(ctx context.Context, patient *models.RecordRegistrationPayload, createdBy *models.CreatedByDetail) error
+	CreateRecord(ctx context.Context, patient *models.RecordRegistrationPayload, createdBy *models.CreatedByDetail, vitals []models.VitalSign, orders []models.MedicationOrder, diagnoses []models.DiagnosisPayload) (string, error)
 	GetRecord(ctx context.Context, filter models.GetRecordQueries) ([]models.GetRecordResponse, error)
+	GetRecordById(ctx context.Context, recordId string) (*models.GetRecordResponse, error)
+	GetVitalSigns(ctx context.Context, filter models.GetVitalSignQueries) ([]models.VitalSignResponse, error)
+	GetRecordVersion(ctx context.Context, recordId string) (*models.RecordVersion, error)
+	CreateAmendment(ctx context.Context, recordId string, version int, amendment *models.RecordAmendmentPayload, amendedBy *models.CreatedByDetail) error
+	GetRecordHistory(ctx context.Context, recordId string) ([]models.RecordHistoryResponse, error)
+	GetPatientAllergies(ctx context.Context, patientIdentityNumber int64) ([]models.PatientAllergy, error)
+	CanNurseAccessPatient(ctx context.Context, userId string, patientIdentityNumber int64) (bool, error)
 }
 
+// medicalRecordRepositories encrypts the symptoms and medications of the
+// records, and decrypts them along with the patient phone numbers on read.
 type medicalRecordRepositories struct {
-	db *pgxpool.Pool
+	db      *pgxpool.Pool
+	keyring *envelope.Keyring
 }
 
-func NewMedicalRecordRepo(db *pgxpool.Pool) MedicalRecordRepositories {
-	return &medicalRecordRepositories{db}
+func NewMedicalRecordRepo(db *pgxpool.Pool, keyring *envelope.Keyring) MedicalRecordRepositories {
+	return &medicalRecordRepositories{db, keyring}
 }
 
 func (r *medicalRecordRepositories) GetPatient(ctx context.Context, patientIdentityNumber int64) (string, error) {
@@ -37,94 +49,385 @@ func (r *medicalRecordRepositories) GetPatient(ctx context.Context, patientIdent
 	return identityNumber, nil
 }
 
-func (r *medicalRecordRepositories) CreateRecord(ctx context.Context, record *models.RecordRegistrationPayload, createdBy *models.CreatedByDetail) error {
-	statement := "INSERT INTO medical_records (identity_number, symptoms, medications, created_by_nip, created_by_name, created_by_user_id) VALUES ($1, $2, $3, $4, $5, $6)"
+// CreateRecord inserts the record with its vital signs, medication orders and
+// diagnoses in a single transaction and returns the id of the record.
+func (r *medicalRecordRepositories) CreateRecord(ctx context.Context, record *models.RecordRegistrationPayload, createdBy *models.CreatedByDetail, vitals []models.VitalSign, orders []models.MedicationOrder, diagnoses []models.DiagnosisPayload) (string, error) {
+	var id string
+	statement := "INSERT INTO medical_records (identity_number, symptoms, medications, created_by_nip, created_by_name, created_by_user_id, created_by_role, encounter_id, search_vector) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::tsvector) RETURNING id"
 
-	_, err := r.db.Exec(ctx, statement, record.IdentityNumber, record.Symptoms, record.Medications, createdBy.Nip, createdBy.Name, createdBy.UserId)
+	var encounterId *string
+	if record.EncounterId != "" {
+		encounterId = &record.EncounterId
+	}
+
+	symptoms, err := r.keyring.Encrypt(record.Symptoms)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	medications, err := r.keyring.Encrypt(record.Medications)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, statement, record.IdentityNumber, symptoms, medications, createdBy.Nip, createdBy.Name, createdBy.UserId, createdBy.Role, encounterId, searchVector(r.keyring, record.Symptoms, record.Medications))
+	if err := row.Scan(&id); err != nil {
+		return "", err
+	}
+
+	for _, vital := range vitals {
+		_, err := tx.Exec(ctx, "INSERT INTO record_vital_signs (record_id, identity_number, vital_type, value, diastolic, unit, measured_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			id, record.IdentityNumber, vital.Type, vital.Value, vital.Diastolic, vital.Unit, vital.MeasuredAt)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := createMedicationOrders(ctx, tx, id, record.IdentityNumber, createdBy, orders); err != nil {
+		return "", err
+	}
+
+	if err := createDiagnoses(ctx, tx, id, diagnoses); err != nil {
+		return "", err
+	}
+
+	return id, tx.Commit(ctx)
 }
 
 func (r *medicalRecordRepositories) GetRecord(ctx context.Context, filter models.GetRecordQueries) ([]models.GetRecordResponse, error) {
 	var records []models.GetRecordResponse
 	var createdAt time.Time
 
-	query := "SELECT identity_number, symptoms, medications, created_by_nip, created_by_name, created_by_user_id, created_at FROM medical_records"
+	// the content comes from the latest amendment when there is one
+	query := "SELECT id, identity_number, COALESCE(latest_symptoms, symptoms), COALESCE(latest_medications, medications), created_by_nip, created_by_name, created_by_user_id, created_by_role, created_at, COALESCE(latest_version, 1) FROM medical_records" + latestVersionJoin
+
+	qb := getRecordConstructWhereQuery(r.keyring, filter)
+	query += qb.WhereClause()
+	query += RecordSort.Clause("createdAt", filter.CreatedAt)
+	query += qb.Limit(filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := map[string]int{}
+	ids := []string{}
 
-	query += getRecordConstructWhereQuery(filter)
+	for rows.Next() {
+		record := models.GetRecordResponse{
+			Vitals:           []models.VitalSignResponse{},
+			MedicationOrders: []models.MedicationOrderResponse{},
+			Diagnoses:        []models.DiagnosisResponse{},
+		}
+		var nipString string
+
+		err := rows.Scan(&record.ID, &record.IdentityDetail.IdentityNumber, &record.Symptoms, &record.Medications, &nipString, &record.CreatedBy.Name, &record.CreatedBy.UserId, &record.CreatedBy.Role, &createdAt, &record.Version)
+		if err != nil {
+			return nil, err
+		}
 
-	if filter.CreatedAt != "" {
-		if filter.CreatedAt == "asc" {
-			query += " ORDER BY created_at ASC"
-		} else if filter.CreatedAt == "desc" {
-			query += " ORDER BY created_at DESC"
+		if err := r.keyring.DecryptAll(&record.Symptoms, &record.Medications); err != nil {
+			return nil, err
 		}
-	} else {
-		query += " ORDER BY created_at DESC"
+
+		record.CreatedBy.Nip = nipString
+		// the patient columns are filled in below, a record whose patient is
+		// missing keeps this partial detail
+		record.IdentityDetail.Partial = true
+
+		record.CreatedAt = createdAt.Format(time.RFC3339Nano)
+
+		index[record.ID] = len(records)
+		ids = append(ids, record.ID)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	query += " limit $1 offset $2"
+	if len(ids) == 0 {
+		return records, nil
+	}
 
-	rows, err := r.db.Query(ctx, query, filter.Limit, filter.Offset)
-	if err != nil {
+	if err := r.fillPatientDetails(ctx, records); err != nil {
 		return nil, err
 	}
 
-	for rows.Next() {
-		record := models.GetRecordResponse{}
-		var identityNumber int64
-		var birthDate time.Time
-		var nipString string
+	vitalRows, err := r.db.Query(ctx, "SELECT record_id, "+vitalSignColumns+" FROM record_vital_signs WHERE record_id = ANY($1) ORDER BY measured_at, id", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer vitalRows.Close()
 
-		err := rows.Scan(&identityNumber, &record.Symptoms, &record.Medications, &nipString, &record.CreatedBy.Name, &record.CreatedBy.UserId, &createdAt)
+	for vitalRows.Next() {
+		var recordId string
+		vital, err := scanVitalSign(vitalRows, &recordId)
 		if err != nil {
 			return nil, err
 		}
 
-		queryPatient := "SELECT phone_number, name, birth_date, gender, identity_card_scan_img FROM patients WHERE identity_number = $1"
+		i := index[recordId]
+		records[i].Vitals = append(records[i].Vitals, *vital)
+	}
+	if err := vitalRows.Err(); err != nil {
+		return nil, err
+	}
 
-		row := r.db.QueryRow(ctx, queryPatient, identityNumber)
-		err = row.Scan(&record.IdentityDetail.PhoneNumber, &record.IdentityDetail.Name, &birthDate, &record.IdentityDetail.Gender, &record.IdentityDetail.IdentityCardScanImg)
+	orderRows, err := r.db.Query(ctx, "SELECT record_id, "+medicationOrderColumns+" FROM medication_orders WHERE record_id = ANY($1) ORDER BY start_at, id", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer orderRows.Close()
+
+	for orderRows.Next() {
+		var recordId string
+		order, err := scanMedicationOrder(orderRows, &recordId)
 		if err != nil {
-			return []models.GetRecordResponse{}, err
+			return nil, err
 		}
 
-		record.CreatedBy.Nip = nipString
+		i := index[recordId]
+		records[i].MedicationOrders = append(records[i].MedicationOrders, *order)
+	}
+	if err := orderRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// primary diagnosis first
+	diagnosisRows, err := r.db.Query(ctx, "SELECT record_id, record_diagnoses.code, description, diagnosis_type FROM record_diagnoses JOIN icd10_codes ON icd10_codes.code = record_diagnoses.code WHERE record_id = ANY($1) ORDER BY diagnosis_type = 'primary' DESC, record_diagnoses.code", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer diagnosisRows.Close()
+
+	for diagnosisRows.Next() {
+		var recordId string
+		diagnosis := models.DiagnosisResponse{}
+		if err := diagnosisRows.Scan(&recordId, &diagnosis.Code, &diagnosis.Description, &diagnosis.Type); err != nil {
+			return nil, err
+		}
+
+		i := index[recordId]
+		records[i].Diagnoses = append(records[i].Diagnoses, diagnosis)
+	}
+
+	return records, diagnosisRows.Err()
+}
+
+// fillPatientDetails loads the patients of a page of records in one query.
+// Records whose patient row is missing keep their partial detail instead of
+// failing the whole page.
+func (r *medicalRecordRepositories) fillPatientDetails(ctx context.Context, records []models.GetRecordResponse) error {
+	index := map[int64][]int{}
+	identityNumbers := []int64{}
+
+	for i, record := range records {
+		identityNumber := record.IdentityDetail.IdentityNumber
+		if _, ok := index[identityNumber]; !ok {
+			identityNumbers = append(identityNumbers, identityNumber)
+		}
+		index[identityNumber] = append(index[identityNumber], i)
+	}
+
+	rows, err := r.db.Query(ctx, "SELECT identity_number, phone_number, name, birth_date, gender FROM patients WHERE identity_number = ANY($1)", identityNumbers)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		detail := models.PatientDetail{}
+		var birthDate time.Time
+
+		if err := rows.Scan(&detail.IdentityNumber, &detail.PhoneNumber, &detail.Name, &birthDate, &detail.Gender); err != nil {
+			return err
+		}
+
+		// the phone number is encrypted by the patient service with the same keys
+		if err := r.keyring.DecryptAll(&detail.PhoneNumber); err != nil {
+			return err
+		}
+
+		detail.BirthDate = birthDate.Format(time.RFC3339Nano)
+		// the scan itself is private, point to the endpoint that signs it
+		detail.IdentityCardScanImg = fmt.Sprintf("/v1/medical/patient/%d/identity-card", detail.IdentityNumber)
+
+		for _, i := range index[detail.IdentityNumber] {
+			records[i].IdentityDetail = detail
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *medicalRecordRepositories) GetRecordById(ctx context.Context, recordId string) (*models.GetRecordResponse, error) {
+	records, err := r.GetRecord(ctx, models.GetRecordQueries{ID: recordId, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	return &records[0], nil
+}
+
+const vitalSignColumns = "vital_type, value, diastolic, unit, measured_at"
+
+// GetVitalSigns returns the vital signs of a patient ordered by type then
+// measurement time.
+func (r *medicalRecordRepositories) GetVitalSigns(ctx context.Context, filter models.GetVitalSignQueries) ([]models.VitalSignResponse, error) {
+	var vitals []models.VitalSignResponse
+
+	qb := querybuilder.New()
+	qb.Equal("identity_number", *filter.IdentityNumber)
+
+	if filter.Type != "" {
+		qb.Equal("vital_type", filter.Type)
+	}
+
+	if filter.From != "" {
+		qb.Where("measured_at >= ?", filter.From)
+	}
+
+	if filter.To != "" {
+		qb.Where("measured_at <= ?", filter.To)
+	}
+
+	query := "SELECT record_id, " + vitalSignColumns + " FROM record_vital_signs" + qb.WhereClause() + " ORDER BY vital_type, measured_at, id"
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recordId string
+		vital, err := scanVitalSign(rows, &recordId)
 		if err != nil {
-			return []models.GetRecordResponse{}, err
+			return nil, err
 		}
-		record.IdentityDetail.IdentityNumber = identityNumber
-		record.IdentityDetail.BirthDate = birthDate.Format(time.RFC3339Nano)
+		vital.RecordId = recordId
+		vitals = append(vitals, *vital)
+	}
 
-		record.CreatedAt = createdAt.Format(time.RFC3339Nano)
+	return vitals, rows.Err()
+}
 
-		records = append(records, record)
+// scanVitalSign scans vitalSignColumns, preceded by any extra destinations.
+func scanVitalSign(row pgx.Row, extra ...interface{}) (*models.VitalSignResponse, error) {
+	var vital models.VitalSignResponse
+	var value float64
+	var measuredAt time.Time
+
+	dest := append(extra, &vital.Type, &value, &vital.Diastolic, &vital.Unit, &measuredAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if vital.Type == models.VitalBloodPressure {
+		vital.Systolic = &value
+	} else {
+		vital.Value = &value
+	}
+	vital.MeasuredAt = measuredAt.Format(time.RFC3339Nano)
+
+	return &vital, nil
+}
+
+func (r *medicalRecordRepositories) GetPatientAllergies(ctx context.Context, patientIdentityNumber int64) ([]models.PatientAllergy, error) {
+	var allergies []models.PatientAllergy
+	query := "SELECT substance, reaction, severity FROM patient_allergies WHERE identity_number = $1"
+
+	rows, err := r.db.Query(ctx, query, patientIdentityNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		allergy := models.PatientAllergy{}
+		if err := rows.Scan(&allergy.Substance, &allergy.Reaction, &allergy.Severity); err != nil {
+			return nil, err
+		}
+		allergies = append(allergies, allergy)
 	}
 
-	return records, nil
+	return allergies, rows.Err()
+}
+
+// RecordSort whitelists what the list is sorted on, the handler rejects anything
+// else.
+var RecordSort = querybuilder.Sort{
+	Columns:          map[string]string{"createdAt": "created_at"},
+	DefaultColumn:    "createdAt",
+	DefaultDirection: "desc",
 }
 
-func getRecordConstructWhereQuery(filter models.GetRecordQueries) string {
-	whereSQL := []string{}
+func getRecordConstructWhereQuery(keyring *envelope.Keyring, filter models.GetRecordQueries) *querybuilder.Builder {
+	qb := querybuilder.New()
+
+	if filter.ID != "" {
+		qb.Equal("id", filter.ID)
+	}
+
+	if filter.AccessibleBy != "" {
+		// the condition uses the user id three times
+		qb.Where(nurseAccessCondition("medical_records.identity_number", "?"), filter.AccessibleBy, filter.AccessibleBy, filter.AccessibleBy)
+	}
 
 	if filter.IdentityNumber != nil {
-		whereSQL = append(whereSQL, fmt.Sprintf(" identity_number = %d", *filter.IdentityNumber))
+		qb.Equal("identity_number", *filter.IdentityNumber)
 	}
 
 	if filter.CreatedByNip != "" {
-		whereSQL = append(whereSQL, " created_by_nip = '"+filter.CreatedByNip+"'")
+		qb.Equal("created_by_nip", filter.CreatedByNip)
 	}
 
 	if filter.CreatedByUserId != "" {
-		whereSQL = append(whereSQL, " created_by_user_id = '"+filter.CreatedByUserId+"'")
+		qb.Equal("created_by_user_id", filter.CreatedByUserId)
+	}
+
+	if filter.CreatedByRole != "" {
+		qb.Equal("created_by_role", filter.CreatedByRole)
+	}
+
+	if filter.CreatedFrom != "" {
+		qb.Where("created_at >= ?::timestamptz", filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != "" {
+		qb.Where("created_at < ?::timestamptz", filter.CreatedTo)
+	}
+
+	if filter.Search != "" {
+		// a query without any word matches nothing
+		if query := searchQuery(keyring, filter.Search); query != "" {
+			qb.Where("search_vector @@ ?::tsquery", query)
+		} else {
+			qb.Where("false")
+		}
+	}
+
+	if filter.EncounterId != "" {
+		qb.Equal("encounter_id", filter.EncounterId)
 	}
 
-	if len(whereSQL) > 0 {
-		return " WHERE " + strings.Join(whereSQL, " AND ")
+	// a category such as J18 also matches its subcodes (J18.0, J18.9, ...)
+	if filter.DiagnosisCode != "" {
+		qb.Where("EXISTS (SELECT 1 FROM record_diagnoses WHERE record_diagnoses.record_id = medical_records.id AND (record_diagnoses.code = ? OR record_diagnoses.code LIKE ?))",
+			filter.DiagnosisCode, querybuilder.EscapeLike(filter.DiagnosisCode)+".%")
 	}
 
-	return ""
+	return qb
 }
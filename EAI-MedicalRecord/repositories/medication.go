@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
+)
+
+type MedicationRepositories interface {
+	GetDrugs(ctx context.Context, codes []string) (map[string]models.Drug, error)
+	SearchDrugs(ctx context.Context, filter models.GetDrugQueries) ([]models.Drug, error)
+	GetActiveMedications(ctx context.Context, identityNumber int64, at time.Time) ([]models.MedicationOrderResponse, error)
+}
+
+type medicationRepositories struct {
+	db *pgxpool.Pool
+}
+
+func NewMedicationRepo(db *pgxpool.Pool) MedicationRepositories {
+	return &medicationRepositories{db}
+}
+
+const (
+	drugColumns            = "code, name, form, units, routes"
+	medicationOrderColumns = "id, drug_code, drug_name, dose, dose_unit, route, frequency, start_at, stop_at, prescribed_by_user_id, prescribed_by_name, prescribed_by_nip"
+)
+
+// GetDrugs returns the catalog entries of the given codes keyed by code,
+// unknown codes are simply missing from the map.
+func (r *medicationRepositories) GetDrugs(ctx context.Context, codes []string) (map[string]models.Drug, error) {
+	drugs := map[string]models.Drug{}
+	query := "SELECT " + drugColumns + " FROM drug_catalog WHERE code = ANY($1)"
+
+	rows, err := r.db.Query(ctx, query, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var drug models.Drug
+		if err := rows.Scan(&drug.Code, &drug.Name, &drug.Form, &drug.Units, &drug.Routes); err != nil {
+			return nil, err
+		}
+		drugs[drug.Code] = drug
+	}
+
+	return drugs, rows.Err()
+}
+
+func (r *medicationRepositories) SearchDrugs(ctx context.Context, filter models.GetDrugQueries) ([]models.Drug, error) {
+	var drugs []models.Drug
+
+	qb := querybuilder.New()
+	if filter.Name != "" {
+		qb.Where("lower(name) LIKE ?", querybuilder.EscapeLike(strings.ToLower(filter.Name))+"%")
+	}
+
+	query := "SELECT " + drugColumns + " FROM drug_catalog" + qb.WhereClause() + " ORDER BY name" + qb.Limit(filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var drug models.Drug
+		if err := rows.Scan(&drug.Code, &drug.Name, &drug.Form, &drug.Units, &drug.Routes); err != nil {
+			return nil, err
+		}
+		drugs = append(drugs, drug)
+	}
+
+	return drugs, rows.Err()
+}
+
+// GetActiveMedications returns the orders of a patient that have started and
+// not stopped yet at the given time, newest first.
+func (r *medicationRepositories) GetActiveMedications(ctx context.Context, identityNumber int64, at time.Time) ([]models.MedicationOrderResponse, error) {
+	var orders []models.MedicationOrderResponse
+	query := "SELECT record_id, " + medicationOrderColumns + " FROM medication_orders WHERE identity_number = $1 AND start_at <= $2 AND (stop_at IS NULL OR stop_at > $2) ORDER BY start_at DESC"
+
+	rows, err := r.db.Query(ctx, query, identityNumber, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recordId string
+		order, err := scanMedicationOrder(rows, &recordId)
+		if err != nil {
+			return nil, err
+		}
+		order.RecordId = recordId
+		orders = append(orders, *order)
+	}
+
+	return orders, rows.Err()
+}
+
+func createMedicationOrders(ctx context.Context, tx pgx.Tx, recordId string, identityNumber int64, createdBy *models.CreatedByDetail, orders []models.MedicationOrder) error {
+	statement := "INSERT INTO medication_orders (record_id, identity_number, drug_code, drug_name, dose, dose_unit, route, frequency, start_at, stop_at, prescribed_by_user_id, prescribed_by_name, prescribed_by_nip) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)"
+
+	for _, order := range orders {
+		_, err := tx.Exec(ctx, statement, recordId, identityNumber, order.DrugCode, order.DrugName, order.Dose, order.DoseUnit, order.Route, order.Frequency, order.StartAt, order.StopAt, createdBy.UserId, createdBy.Name, createdBy.Nip)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanMedicationOrder scans medicationOrderColumns, preceded by any extra
+// destinations.
+func scanMedicationOrder(row pgx.Row, extra ...interface{}) (*models.MedicationOrderResponse, error) {
+	var order models.MedicationOrderResponse
+	var startAt time.Time
+	var stopAt *time.Time
+
+	dest := append(extra, &order.ID, &order.DrugCode, &order.DrugName, &order.Dose, &order.DoseUnit, &order.Route, &order.Frequency, &startAt, &stopAt, &order.PrescribedBy.UserId, &order.PrescribedBy.Name, &order.PrescribedBy.Nip)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	order.StartAt = startAt.Format(time.RFC3339Nano)
+	if stopAt != nil {
+		formatted := stopAt.Format(time.RFC3339Nano)
+		order.StopAt = &formatted
+	}
+
+	return &order, nil
+}
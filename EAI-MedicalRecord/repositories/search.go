@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+)
+
+// The search vector of a record holds blind indexes of its words instead of
+// the words themselves, so it doesn't give away the encrypted symptoms and
+// medications. Queries are hashed the same way before they are matched.
+
+// searchWords splits the text in lower cased words of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// searchVector returns the tsvector literal of the symptoms and medications,
+// with the word positions kept for phrase queries.
+func searchVector(keyring *envelope.Keyring, symptoms string, medications string) string {
+	lexemes := []string{}
+	for i, word := range searchWords(symptoms + " " + medications) {
+		// positions above the tsvector limit are clamped by postgres
+		lexemes = append(lexemes, "'"+keyring.BlindIndex(word)+"':"+strconv.Itoa(i+1))
+	}
+
+	return strings.Join(lexemes, " ")
+}
+
+// searchQuery turns a web search style query into a tsquery literal over the
+// hashed words. Words are all required, "quoted words" are a phrase, a leading
+// - excludes a word or phrase and or between two terms accepts either. It
+// returns an empty string when the query has no words.
+func searchQuery(keyring *envelope.Keyring, q string) string {
+	groups := []string{}
+	terms := []string{}
+	or := false
+
+	addTerm := func(text string, negate bool) {
+		words := searchWords(text)
+		if len(words) == 0 {
+			return
+		}
+
+		hashed := make([]string, len(words))
+		for i, word := range words {
+			hashed[i] = "'" + keyring.BlindIndex(word) + "'"
+		}
+
+		term := strings.Join(hashed, " <-> ")
+		if len(hashed) > 1 {
+			term = "(" + term + ")"
+		}
+		if negate {
+			term = "!" + term
+		}
+
+		if or && len(terms) > 0 {
+			groups = append(groups, strings.Join(terms, " & "))
+			terms = nil
+		}
+		or = false
+		terms = append(terms, term)
+	}
+
+	for rest := strings.TrimSpace(q); rest != ""; rest = strings.TrimSpace(rest) {
+		negate := false
+		if rest[0] == '-' {
+			negate = true
+			rest = rest[1:]
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			addTerm(phrase, negate)
+			rest = after
+			continue
+		}
+
+		word, after, _ := strings.Cut(rest, " ")
+		rest = after
+
+		if !negate && strings.EqualFold(word, "or") {
+			or = true
+			continue
+		}
+		addTerm(word, negate)
+	}
+
+	if len(terms) > 0 {
+		groups = append(groups, strings.Join(terms, " & "))
+	}
+
+	if len(groups) > 1 {
+		for i, group := range groups {
+			groups[i] = "(" + group + ")"
+		}
+	}
+
+	return strings.Join(groups, " | ")
+}
@@ -0,0 +1,43 @@
+package responses
+
+// The codes are part of the API, clients branch on them. Never rename one,
+// add a new code instead.
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeValidationFailed   = "VALIDATION_FAILED"
+	CodeInvalidBody        = "INVALID_BODY"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeMissingToken       = "MISSING_TOKEN"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeTokenExpired       = "TOKEN_EXPIRED"
+	CodeAdminOnly          = "ADMIN_ONLY"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+)
+
+const (
+	CodeInvalidIdentityNumber  = "INVALID_IDENTITY_NUMBER"
+	CodePatientNotFound        = "PATIENT_NOT_FOUND"
+	CodeNurseNotFound          = "NURSE_NOT_FOUND"
+	CodeStaffNotFound          = "STAFF_NOT_FOUND"
+	CodeInvalidStaffId         = "INVALID_STAFF_ID"
+	CodeInvalidFilter          = "INVALID_FILTER"
+	CodeRecordNotFound         = "RECORD_NOT_FOUND"
+	CodeRecordVersionConflict  = "RECORD_VERSION_CONFLICT"
+	CodeEmptyAmendment         = "EMPTY_AMENDMENT"
+	CodeFieldNotAmendable      = "FIELD_NOT_AMENDABLE"
+	CodeInvalidVitalSigns      = "INVALID_VITAL_SIGNS"
+	CodeInvalidDiagnosis       = "INVALID_DIAGNOSIS"
+	CodeInvalidMedicationOrder = "INVALID_MEDICATION_ORDER"
+	CodeEncounterNotFound      = "ENCOUNTER_NOT_FOUND"
+	CodeEncounterConflict      = "ENCOUNTER_CONFLICT"
+	CodeEncounterMismatch      = "ENCOUNTER_MISMATCH"
+	CodeEncounterDischarged    = "ENCOUNTER_DISCHARGED"
+	CodeAccessDenied           = "ACCESS_DENIED"
+	CodeAccessReasonRequired   = "ACCESS_REASON_REQUIRED"
+	CodeGrantNotFound          = "GRANT_NOT_FOUND"
+	CodeGrantAlreadyReviewed   = "GRANT_ALREADY_REVIEWED"
+)
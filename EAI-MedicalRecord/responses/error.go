@@ -1,8 +1,22 @@
 package responses
 
+// CustomError is the error of a service, the ErrorHandler writes it as a
+// problem. Callers compare it to CustomError{}, its fields must stay
+// comparable.
 type CustomError struct {
 	Message    string `json:"message"`
 	StatusCode int    `json:"status"`
+	Code       string `json:"code"`
+	// the invalid fields of a validation error, behind a pointer to keep the
+	// struct comparable
+	Fields *[]FieldError `json:"fields,omitempty"`
+}
+
+// FieldError is a field of the request that failed a validation rule.
+type FieldError struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
 }
 
 func (e CustomError) Error() string {
@@ -13,26 +27,42 @@ func (e CustomError) Status() int {
 	return e.StatusCode
 }
 
+// WithCode returns a copy of the error with a more specific code than the one
+// of its status.
+func (e CustomError) WithCode(code string) CustomError {
+	e.Code = code
+	return e
+}
+
 func NewBadRequestError(message string) CustomError {
-	return CustomError{Message: message, StatusCode: 400}
+	return CustomError{Message: message, StatusCode: 400, Code: CodeBadRequest}
+}
+
+// NewValidationError is a 400 listing the fields that failed validation.
+func NewValidationError(message string, fields []FieldError) CustomError {
+	return CustomError{Message: message, StatusCode: 400, Code: CodeValidationFailed, Fields: &fields}
 }
 
 func NewUnauthorizedError(message string) CustomError {
-	return CustomError{Message: message, StatusCode: 401}
+	return CustomError{Message: message, StatusCode: 401, Code: CodeUnauthorized}
 }
 
 func NewForbiddenError(message string) CustomError {
-	return CustomError{Message: message, StatusCode: 403}
+	return CustomError{Message: message, StatusCode: 403, Code: CodeForbidden}
 }
 
 func NewNotFoundError(message string) CustomError {
-	return CustomError{Message: message, StatusCode: 404}
+	return CustomError{Message: message, StatusCode: 404, Code: CodeNotFound}
 }
 
 func NewConflictError(message string) CustomError {
-	return CustomError{Message: message, StatusCode: 409}
+	return CustomError{Message: message, StatusCode: 409, Code: CodeConflict}
 }
 
 func NewInternalServerError(message string) CustomError {
-	return CustomError{Message: message, StatusCode: 500}
-}
\ No newline at end of file
+	return CustomError{Message: message, StatusCode: 500, Code: CodeInternal}
+}
+
+func NewServiceUnavailableError(message string) CustomError {
+	return CustomError{Message: message, StatusCode: 503, Code: CodeServiceUnavailable}
+}
@@ -0,0 +1,578 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/medicalrecord"
+	"github.com/ravenocx/hospital-mgt/sdk/sdktest"
+)
+
+// The contract tests run the medicalrecord client of the sdk against the real
+// routes and handlers, on top of repositories returning completely filled
+// rows. A response field renamed or dropped on either side fails them.
+
+const (
+	testSecret         = "contract-test-secret"
+	testIdentityNumber = int64(3201234567890001)
+	testRecordId       = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a001"
+	testEncounterId    = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a002"
+	testGrantId        = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a003"
+	testNurseId        = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a004"
+	testAdminId        = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a005"
+	testReason         = "follow up of the referral"
+	testTime           = "2024-05-01T08:00:00Z"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testAuthor() models.CreatedByDetail {
+	return models.CreatedByDetail{Nip: "303123456789", Name: "nurse one", UserId: testNurseId, Role: "nurse"}
+}
+
+func testRecord() models.GetRecordResponse {
+	return models.GetRecordResponse{
+		ID: testRecordId,
+		IdentityDetail: models.PatientDetail{
+			IdentityNumber:      testIdentityNumber,
+			PhoneNumber:         "+6281234567890",
+			Name:                "patient one",
+			BirthDate:           "1990-01-01T00:00:00Z",
+			Gender:              "female",
+			IdentityCardScanImg: "https://storage.example.com/card.png",
+		},
+		Symptoms:    "fever",
+		Medications: "paracetamol 500 mg oral tid",
+		Vitals: []models.VitalSignResponse{
+			{Type: models.VitalBloodPressure, Value: ptr(120.0), Systolic: ptr(120.0), Diastolic: ptr(80.0), Unit: "mmHg", MeasuredAt: testTime},
+		},
+		MedicationOrders: []models.MedicationOrderResponse{testMedicationOrder()},
+		Diagnoses:        []models.DiagnosisResponse{{Code: "J06.9", Description: "Acute upper respiratory infection", Type: models.DiagnosisPrimary}},
+		Version:          2,
+		CreatedBy:        testAuthor(),
+		CreatedAt:        testTime,
+	}
+}
+
+func testMedicationOrder() models.MedicationOrderResponse {
+	return models.MedicationOrderResponse{
+		ID:           "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a006",
+		DrugCode:     "PCT500",
+		DrugName:     "Paracetamol",
+		Dose:         500,
+		DoseUnit:     "mg",
+		Route:        "oral",
+		Frequency:    "tid",
+		StartAt:      testTime,
+		StopAt:       ptr("2024-05-08T08:00:00Z"),
+		PrescribedBy: testAuthor(),
+	}
+}
+
+func testGrant() models.BreakGlassGrant {
+	return models.BreakGlassGrant{
+		ID:             testGrantId,
+		UserId:         testNurseId,
+		Role:           "nurse",
+		IdentityNumber: testIdentityNumber,
+		Justification:  "patient unconscious in the emergency room",
+		GrantedAt:      testTime,
+		ExpiresAt:      "2024-05-01T09:00:00Z",
+		ReviewStatus:   models.BreakGlassAcknowledged,
+		ReviewNote:     ptr("justified"),
+		ReviewedBy:     ptr(testAdminId),
+		ReviewedAt:     ptr("2024-05-02T08:00:00Z"),
+		Reads:          3,
+	}
+}
+
+// fakeRepositories implements every repository of the routes, it keeps what
+// the handlers wrote so the tests can check the requests were read.
+type fakeRepositories struct {
+	createdRecord *models.RecordRegistrationPayload
+	amendment     *models.RecordAmendmentPayload
+	encounter     *models.EncounterRegistrationPayload
+}
+
+func (r *fakeRepositories) GetPatient(ctx context.Context, patientIdentityNumber int64) (string, error) {
+	return "patient one", nil
+}
+
+func (r *fakeRepositories) CreateRecord(ctx context.Context, patient *models.RecordRegistrationPayload, createdBy *models.CreatedByDetail, vitals []models.VitalSign, orders []models.MedicationOrder, diagnoses []models.DiagnosisPayload) (string, error) {
+	r.createdRecord = patient
+	return testRecordId, nil
+}
+
+func (r *fakeRepositories) GetRecord(ctx context.Context, filter models.GetRecordQueries) ([]models.GetRecordResponse, error) {
+	return []models.GetRecordResponse{testRecord()}, nil
+}
+
+func (r *fakeRepositories) GetRecordById(ctx context.Context, recordId string) (*models.GetRecordResponse, error) {
+	record := testRecord()
+	return &record, nil
+}
+
+func (r *fakeRepositories) GetVitalSigns(ctx context.Context, filter models.GetVitalSignQueries) ([]models.VitalSignResponse, error) {
+	return testRecord().Vitals, nil
+}
+
+func (r *fakeRepositories) GetRecordVersion(ctx context.Context, recordId string) (*models.RecordVersion, error) {
+	return &models.RecordVersion{IdentityNumber: testIdentityNumber, Version: 1, Symptoms: "fever", Medications: "paracetamol"}, nil
+}
+
+func (r *fakeRepositories) CreateAmendment(ctx context.Context, recordId string, version int, amendment *models.RecordAmendmentPayload, amendedBy *models.CreatedByDetail) error {
+	r.amendment = amendment
+	return nil
+}
+
+func (r *fakeRepositories) GetRecordHistory(ctx context.Context, recordId string) ([]models.RecordHistoryResponse, error) {
+	return []models.RecordHistoryResponse{
+		{Version: 1, Symptoms: "fever", Medications: "paracetamol", Author: testAuthor(), CreatedAt: testTime},
+		{Version: 2, Symptoms: "high fever", Medications: "paracetamol", Reason: ptr("symptoms worsened"), Author: testAuthor(), CreatedAt: testTime},
+	}, nil
+}
+
+func (r *fakeRepositories) GetPatientAllergies(ctx context.Context, patientIdentityNumber int64) ([]models.PatientAllergy, error) {
+	return []models.PatientAllergy{{Substance: "paracetamol", Reaction: "rash", Severity: "moderate"}}, nil
+}
+
+func (r *fakeRepositories) CanNurseAccessPatient(ctx context.Context, userId string, patientIdentityNumber int64) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeRepositories) CreateEncounter(ctx context.Context, encounter *models.EncounterRegistrationPayload, openedBy string) (string, error) {
+	r.encounter = encounter
+	return testEncounterId, nil
+}
+
+func (r *fakeRepositories) GetEncounter(ctx context.Context, encounterId string) (*models.Encounter, error) {
+	return &models.Encounter{ID: encounterId, IdentityNumber: testIdentityNumber, Status: models.EncounterStatusOpen}, nil
+}
+
+func (r *fakeRepositories) GetOpenEncounter(ctx context.Context, identityNumber int64) (*models.Encounter, error) {
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeRepositories) GetEncounters(ctx context.Context, filter models.GetEncounterQueries) ([]models.GetEncounterResponse, error) {
+	return []models.GetEncounterResponse{{
+		ID:                 testEncounterId,
+		IdentityNumber:     testIdentityNumber,
+		EncounterType:      models.EncounterTypeInpatient,
+		AdmittingReason:    "pneumonia",
+		Ward:               ptr("ICU"),
+		Status:             models.EncounterStatusDischarged,
+		AttendingStaffIds:  []string{testNurseId},
+		OpenedByUserId:     testNurseId,
+		AdmittedAt:         testTime,
+		DischargeSummary:   ptr("recovered"),
+		DischargedByUserId: ptr(testNurseId),
+		DischargedAt:       ptr("2024-05-05T08:00:00Z"),
+		Records: []models.EncounterRecord{
+			{ID: testRecordId, Symptoms: "fever", Medications: "paracetamol", CreatedBy: testAuthor(), CreatedAt: testTime},
+		},
+	}}, nil
+}
+
+func (r *fakeRepositories) DischargeEncounter(ctx context.Context, encounterId string, summary string, dischargedBy string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (r *fakeRepositories) CountUsers(ctx context.Context, userIds []string) (int, error) {
+	return len(userIds), nil
+}
+
+func (r *fakeRepositories) GetDrugs(ctx context.Context, codes []string) (map[string]models.Drug, error) {
+	drugs := map[string]models.Drug{}
+	for _, code := range codes {
+		drugs[code] = models.Drug{Code: code, Name: "Paracetamol", Form: "tablet", Units: []string{"mg"}, Routes: []string{"oral"}}
+	}
+	return drugs, nil
+}
+
+func (r *fakeRepositories) SearchDrugs(ctx context.Context, filter models.GetDrugQueries) ([]models.Drug, error) {
+	return []models.Drug{{Code: "PCT500", Name: "Paracetamol", Form: "tablet", Units: []string{"mg"}, Routes: []string{"oral"}}}, nil
+}
+
+func (r *fakeRepositories) GetActiveMedications(ctx context.Context, identityNumber int64, at time.Time) ([]models.MedicationOrderResponse, error) {
+	return []models.MedicationOrderResponse{testMedicationOrder()}, nil
+}
+
+func (r *fakeRepositories) GetIcd10Codes(ctx context.Context, codes []string) (map[string]models.Icd10Code, error) {
+	known := map[string]models.Icd10Code{}
+	for _, code := range codes {
+		known[code] = models.Icd10Code{Code: code, Description: "Acute upper respiratory infection"}
+	}
+	return known, nil
+}
+
+func (r *fakeRepositories) SearchIcd10Codes(ctx context.Context, filter models.GetIcd10Queries) ([]models.Icd10Code, error) {
+	return []models.Icd10Code{{Code: "J06.9", Description: "Acute upper respiratory infection"}}, nil
+}
+
+func (r *fakeRepositories) CreateGrant(ctx context.Context, requester models.RecordRequester, grant *models.BreakGlassPayload, duration time.Duration) (*models.BreakGlassGrant, error) {
+	created := testGrant()
+	return &created, nil
+}
+
+func (r *fakeRepositories) GetGrant(ctx context.Context, grantId string) (*models.BreakGlassGrant, error) {
+	grant := testGrant()
+	return &grant, nil
+}
+
+func (r *fakeRepositories) GetGrants(ctx context.Context, filter models.GetBreakGlassQueries) ([]models.BreakGlassGrant, error) {
+	return []models.BreakGlassGrant{testGrant()}, nil
+}
+
+func (r *fakeRepositories) GetActiveGrants(ctx context.Context, userId string) (map[int64]string, error) {
+	return map[int64]string{testIdentityNumber: testGrantId}, nil
+}
+
+func (r *fakeRepositories) CreateReads(ctx context.Context, grantId string, recordIds []string) error {
+	return nil
+}
+
+func (r *fakeRepositories) ReviewGrant(ctx context.Context, grantId string, review *models.BreakGlassReviewPayload, reviewedBy string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (r *fakeRepositories) CreateAccessLogs(ctx context.Context, entries []models.RecordAccessLog) error {
+	return nil
+}
+
+func (r *fakeRepositories) GetAccessLogs(ctx context.Context, filter models.GetAccessLogQueries) ([]models.RecordAccessLog, error) {
+	return []models.RecordAccessLog{{
+		ID:             1,
+		ReaderUserId:   testAdminId,
+		ReaderRole:     "admin",
+		IdentityNumber: testIdentityNumber,
+		RecordIds:      []string{testRecordId},
+		AccessType:     models.AccessTypeSingle,
+		Reason:         ptr(testReason),
+		ClientIP:       "10.0.0.1",
+		AccessedAt:     testTime,
+		PrevHash:       "0000",
+		Hash:           "abcd",
+	}}, nil
+}
+
+func (r *fakeRepositories) VerifyAccessLogs(ctx context.Context) (*models.AccessLogVerification, error) {
+	return &models.AccessLogVerification{Valid: false, Entries: 2, BrokenAtId: ptr(int64(2))}, nil
+}
+
+// stubServices serves the Patient and NurseManagement endpoints the service
+// calls while recording, onCall sees every call when set.
+func stubServices(t *testing.T, onCall func(r *http.Request)) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/medical/patient", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"success","data":[{"identityNumber":3201234567890001,"phoneNumber":"+6281234567890","name":"patient one","birthDate":"1990-01-01T00:00:00Z","gender":"female","createdAt":"2024-01-01T00:00:00Z"}]}`))
+	})
+	mux.HandleFunc("/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"success","data":[{"userId":"` + testNurseId + `","nip":303123456789,"name":"nurse one","access":true,"createdAt":"2024-01-01T00:00:00Z"}]}`))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if onCall != nil {
+			onCall(r)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func testToken(t *testing.T, userId string, role string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"id":      userId,
+		"role":    role,
+		"expires": time.Now().Add(time.Hour).Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token : %+v", err)
+	}
+
+	return token
+}
+
+// startServer serves the routes on a random port and returns a strict client
+// of it, without a token.
+func startServer(t *testing.T, repos *fakeRepositories) *medicalrecord.Client {
+	t.Helper()
+
+	return startServerWith(t, repos, stubServices(t, nil), httpclient.DefaultConfig(""))
+}
+
+// startServerWith is startServer calling the given services, with a client
+// built from clientConfig.
+func startServerWith(t *testing.T, repos *fakeRepositories, services *httptest.Server, clientConfig httpclient.Config) *medicalrecord.Client {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	s := NewServer(nil, nil, config.Config{
+		BreakGlassDuration:   60,
+		PatientServiceURL:    services.URL,
+		NurseServiceURL:      services.URL,
+		HttpClientTimeout:    1000,
+		HttpBreakerThreshold: 5,
+		HttpBreakerCooldown:  30,
+	})
+	s.registerRoutes(Repositories{repos, repos, repos, repos, repos, repos})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen : %+v", err)
+	}
+	go s.app.Listener(ln)
+	t.Cleanup(func() { s.app.Shutdown() })
+
+	clientConfig.BaseURL = "http://" + ln.Addr().String()
+	clientConfig.MaxRetries = 0
+
+	return medicalrecord.New(api.NewClient(httpclient.New(clientConfig)).Strict())
+}
+
+func TestMedicalRecordContract(t *testing.T) {
+	repos := &fakeRepositories{}
+	client := startServer(t, repos)
+	nurse := client.WithToken(testToken(t, testNurseId, "nurse"))
+	ctx := context.Background()
+
+	t.Run("CreateRecord", func(t *testing.T) {
+		record, warnings, err := nurse.CreateRecord(ctx, medicalrecord.NewRecord{
+			IdentityNumber: testIdentityNumber,
+			Symptoms:       "fever",
+			Medications:    "paracetamol",
+			MedicationOrders: []medicalrecord.MedicationOrderRequest{
+				{DrugCode: "PCT500", Dose: 500, DoseUnit: "mg", Route: "oral", Frequency: "tid", StartAt: testTime, StopAt: "2024-05-08T08:00:00Z"},
+			},
+			EncounterId: testEncounterId,
+			Vitals: []medicalrecord.VitalSignRequest{
+				{Type: "blood_pressure", Value: ptr(120.0), Systolic: ptr(120.0), Diastolic: ptr(80.0), Unit: "mmHg", MeasuredAt: testTime},
+			},
+			Diagnoses: []medicalrecord.DiagnosisRequest{{Code: "J06.9", Type: "primary"}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, record, "Partial")
+		sdktest.RequireFilled(t, warnings)
+		sdktest.RequireFilled(t, repos.createdRecord)
+	})
+
+	t.Run("GetRecords", func(t *testing.T) {
+		records, err := nurse.GetRecords(ctx, medicalrecord.GetRecordsQuery{IdentityNumber: testIdentityNumber})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, records, "Partial")
+	})
+
+	t.Run("GetRecord", func(t *testing.T) {
+		record, err := nurse.GetRecord(ctx, testRecordId, "")
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, record, "Partial")
+	})
+
+	t.Run("AmendRecord", func(t *testing.T) {
+		amended, warnings, err := nurse.AmendRecord(ctx, testRecordId, medicalrecord.Amendment{
+			Symptoms:    "high fever",
+			Medications: "paracetamol 1 g",
+			Reason:      "symptoms worsened",
+			BaseVersion: 1,
+		}, "")
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, amended)
+		sdktest.RequireFilled(t, warnings)
+		// the structured content can't be amended, the client never sends it
+		sdktest.RequireFilled(t, repos.amendment, "Vitals", "MedicationOrders", "Diagnoses")
+	})
+
+	t.Run("GetRecordHistory", func(t *testing.T) {
+		history, err := nurse.GetRecordHistory(ctx, testRecordId, "")
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("got %d versions, want 2", len(history))
+		}
+
+		// the first version has no reason and nothing to compare with
+		sdktest.RequireFilled(t, history[1])
+	})
+
+	t.Run("GetVitalSigns", func(t *testing.T) {
+		series, err := nurse.GetVitalSigns(ctx, medicalrecord.GetVitalSignsQuery{IdentityNumber: testIdentityNumber, Type: "blood_pressure"})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, series)
+	})
+
+	t.Run("SearchDrugs", func(t *testing.T) {
+		drugs, err := nurse.SearchDrugs(ctx, medicalrecord.SearchQuery{Query: "para"})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, drugs)
+	})
+
+	t.Run("GetActiveMedications", func(t *testing.T) {
+		orders, err := nurse.GetActiveMedications(ctx, testIdentityNumber, "")
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, orders)
+	})
+
+	t.Run("SearchDiagnosisCodes", func(t *testing.T) {
+		codes, err := nurse.SearchDiagnosisCodes(ctx, medicalrecord.SearchQuery{Query: "J06"})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, codes)
+	})
+
+	t.Run("OpenEncounter", func(t *testing.T) {
+		encounter, err := nurse.OpenEncounter(ctx, medicalrecord.NewEncounter{
+			IdentityNumber:    testIdentityNumber,
+			EncounterType:     "inpatient",
+			AdmittingReason:   "pneumonia",
+			Ward:              "ICU",
+			AttendingStaffIds: []string{testNurseId},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, encounter)
+		sdktest.RequireFilled(t, repos.encounter)
+	})
+
+	t.Run("GetEncounters", func(t *testing.T) {
+		encounters, err := nurse.GetEncounters(ctx, medicalrecord.GetEncountersQuery{IdentityNumber: testIdentityNumber})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, encounters, "Role")
+	})
+
+	t.Run("DischargeEncounter", func(t *testing.T) {
+		if err := nurse.DischargeEncounter(ctx, testEncounterId, "recovered"); err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+	})
+
+	t.Run("BreakGlass", func(t *testing.T) {
+		grant, err := nurse.BreakGlass(ctx, medicalrecord.BreakGlassRequest{
+			IdentityNumber: testIdentityNumber,
+			Justification:  "patient unconscious in the emergency room",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, grant)
+	})
+}
+
+func TestMedicalRecordAdminContract(t *testing.T) {
+	client := startServer(t, &fakeRepositories{})
+	admin := client.WithToken(testToken(t, testAdminId, "admin"))
+	ctx := context.Background()
+
+	t.Run("GetRecord", func(t *testing.T) {
+		record, err := admin.GetRecord(ctx, testRecordId, testReason)
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, record, "Partial")
+	})
+
+	t.Run("GetBreakGlassGrants", func(t *testing.T) {
+		grants, err := admin.GetBreakGlassGrants(ctx, medicalrecord.GetBreakGlassGrantsQuery{ReviewStatus: "acknowledged"})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, grants)
+	})
+
+	t.Run("ReviewBreakGlass", func(t *testing.T) {
+		grant, err := admin.ReviewBreakGlass(ctx, testGrantId, medicalrecord.BreakGlassReview{Decision: "acknowledged", Note: "justified"})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, grant)
+	})
+
+	t.Run("GetAccessLogs", func(t *testing.T) {
+		logs, err := admin.GetAccessLogs(ctx, medicalrecord.GetAccessLogsQuery{IdentityNumber: testIdentityNumber})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, logs)
+	})
+
+	t.Run("VerifyAccessLogs", func(t *testing.T) {
+		verification, err := admin.VerifyAccessLogs(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		// Valid is false so BrokenAtId is sent
+		sdktest.RequireFilled(t, verification, "Valid")
+	})
+}
+
+func TestMedicalRecordClientErrors(t *testing.T) {
+	client := startServer(t, &fakeRepositories{})
+	admin := client.WithToken(testToken(t, testAdminId, "admin"))
+
+	_, err := admin.GetRecord(context.Background(), testRecordId, "")
+	if api.StatusCode(err) != http.StatusForbidden || api.Code(err) != responses.CodeAccessReasonRequired {
+		t.Errorf("got error %v, want a 403 %s without a reason", err, responses.CodeAccessReasonRequired)
+	}
+
+	_, err = client.GetRecord(context.Background(), testRecordId, "")
+	if api.StatusCode(err) != http.StatusBadRequest || api.Code(err) != responses.CodeMissingToken {
+		t.Errorf("got error %v, want the JWT middleware to reject the call with %s", err, responses.CodeMissingToken)
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+)
+
+// StartReencryption moves the encrypted columns still in plaintext or under
+// an old master key to the active one in the background. The search vectors
+// of the records still in plaintext are rebuilt first, searches are made of
+// hashed words and would miss those records until the job gets to them.
+func (s *Server) StartReencryption(ctx context.Context) {
+	repo := repositories.NewEncryptionRepo(s.dbPool, s.keyring)
+	interval := time.Duration(s.config.ReencryptInterval) * time.Second
+	limit := s.config.ReencryptBatchSize
+
+	total := 0
+	for after := uuid.Nil.String(); ; {
+		last, n, err := repo.RebuildSearchVectors(ctx, after, limit)
+		if err != nil {
+			logging.Fatal("failed to rebuild the search vectors", "error", err)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+		after = last
+	}
+	if total > 0 {
+		slog.InfoContext(ctx, "rebuilt the search vectors", "total", total)
+	}
+
+	go envelope.RunReencryption(ctx, "medical records", interval, func(ctx context.Context) (int, error) {
+		return repo.ReencryptRecords(ctx, limit)
+	})
+
+	go envelope.RunReencryption(ctx, "medical record amendments", interval, func(ctx context.Context) (int, error) {
+		return repo.ReencryptAmendments(ctx, limit)
+	})
+}
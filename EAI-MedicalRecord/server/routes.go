@@ -1,25 +1,100 @@
 package server
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/controller"
 	"github.com/ravenocx/hospital-mgt/middleware"
 	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/nurse"
+	"github.com/ravenocx/hospital-mgt/sdk/patient"
 	"github.com/ravenocx/hospital-mgt/service"
 )
 
+// Repositories are the data access of the routes, on the database in the
+// service and faked in the contract tests.
+type Repositories struct {
+	MedicalRecord repositories.MedicalRecordRepositories
+	Encounter     repositories.EncounterRepositories
+	Medication    repositories.MedicationRepositories
+	Diagnosis     repositories.DiagnosisRepositories
+	BreakGlass    repositories.BreakGlassRepositories
+	AccessLog     repositories.AccessLogRepositories
+}
+
+func NewRepositories(db *pgxpool.Pool, keyring *envelope.Keyring) Repositories {
+	return Repositories{
+		MedicalRecord: repositories.NewMedicalRecordRepo(db, keyring),
+		Encounter:     repositories.NewEncounterRepo(db, keyring),
+		Medication:    repositories.NewMedicationRepo(db),
+		Diagnosis:     repositories.NewDiagnosisRepo(db),
+		BreakGlass:    repositories.NewBreakGlassRepo(db),
+		AccessLog:     repositories.NewAccessLogRepo(db),
+	}
+}
+
 func (s *Server) RegisterRoute() {
+	s.registerRoutes(NewRepositories(s.dbPool, s.keyring))
+}
+
+func (s *Server) registerRoutes(repos Repositories) {
 	mainRoute := s.app.Group("/v1")
 
-	MedicalRoute(mainRoute, s.dbPool)
+	MedicalRoute(mainRoute, repos, s.patientClient, s.nurseClient, s.validate)
+	BreakGlassRoute(mainRoute, repos, s.config, s.validate)
+	EncounterRoute(mainRoute, repos, s.validate)
 }
 
-func MedicalRoute(r fiber.Router, db *pgxpool.Pool) {
-	c := controller.NewUserController(service.NewMedicalServiceService(repositories.NewMedicalRecordRepo(db)))
+func MedicalRoute(r fiber.Router, repos Repositories, patientClient *patient.Client, nurseClient *nurse.Client, validate fiber.Handler) {
+	c := controller.NewUserController(service.NewMedicalServiceService(repos.MedicalRecord, repos.Encounter, repos.Medication, repos.Diagnosis, repos.BreakGlass, repos.AccessLog, patientClient, nurseClient))
 
 	medicalRoute := r.Group("/medical")
 
-	medicalRoute.Post("/record", middleware.JWTProtected(), middleware.UserAuth(), c.RegisterRecord)
-	medicalRoute.Get("/record", middleware.JWTProtected(), middleware.UserAuth(), c.GetRecord)
+	medicalRoute.Post("/record", middleware.JWTProtected(), middleware.UserAuth(), validate, c.RegisterRecord)
+	medicalRoute.Get("/record", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetRecord)
+	medicalRoute.Get("/record/:id", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetRecordById)
+	medicalRoute.Post("/record/:id/amend", middleware.JWTProtected(), middleware.UserAuth(), validate, c.AmendRecord)
+	medicalRoute.Get("/record/:id/history", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetRecordHistory)
+
+	vc := controller.NewVitalSignController(service.NewVitalSignService(repos.MedicalRecord, repos.BreakGlass, repos.AccessLog))
+
+	medicalRoute.Get("/vitals", middleware.JWTProtected(), middleware.UserAuth(), validate, vc.GetVitalSignSeries)
+
+	mc := controller.NewMedicationController(service.NewMedicationService(repos.Medication, repos.MedicalRecord, repos.BreakGlass, repos.AccessLog))
+
+	medicalRoute.Get("/drug", middleware.JWTProtected(), middleware.UserAuth(), validate, mc.SearchDrugs)
+	medicalRoute.Get("/medication/active", middleware.JWTProtected(), middleware.UserAuth(), validate, mc.GetActiveMedications)
+
+	dc := controller.NewDiagnosisController(service.NewDiagnosisService(repos.Diagnosis))
+
+	medicalRoute.Get("/diagnosis-code", middleware.JWTProtected(), middleware.UserAuth(), validate, dc.SearchIcd10Codes)
+
+	ac := controller.NewAccessLogController(service.NewAccessLogService(repos.AccessLog))
+
+	medicalRoute.Get("/access-log", middleware.JWTProtected(), middleware.AdminAuth(), validate, ac.GetAccessLogs)
+	medicalRoute.Get("/access-log/verify", middleware.JWTProtected(), middleware.AdminAuth(), validate, ac.VerifyAccessLogs)
+}
+
+func BreakGlassRoute(r fiber.Router, repos Repositories, config config.Config, validate fiber.Handler) {
+	c := controller.NewBreakGlassController(service.NewBreakGlassService(repos.BreakGlass, repos.MedicalRecord, time.Duration(config.BreakGlassDuration)*time.Minute))
+
+	breakGlassRoute := r.Group("/medical/break-glass")
+
+	breakGlassRoute.Post("/", middleware.JWTProtected(), middleware.UserAuth(), validate, c.BreakGlass)
+	breakGlassRoute.Get("/", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.GetBreakGlassGrants)
+	breakGlassRoute.Post("/:id/review", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.ReviewBreakGlass)
+}
+
+func EncounterRoute(r fiber.Router, repos Repositories, validate fiber.Handler) {
+	c := controller.NewEncounterController(service.NewEncounterService(repos.Encounter, repos.MedicalRecord, repos.BreakGlass, repos.AccessLog))
+
+	encounterRoute := r.Group("/medical/encounter")
+
+	encounterRoute.Post("/", middleware.JWTProtected(), middleware.UserAuth(), validate, c.OpenEncounter)
+	encounterRoute.Get("/", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetEncounters)
+	encounterRoute.Post("/:encounterId/discharge", middleware.JWTProtected(), middleware.UserAuth(), validate, c.DischargeEncounter)
 }
@@ -1,23 +1,42 @@
 package server
 
 import (
-	"log"
+	"net/http"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/middleware"
+	"github.com/ravenocx/hospital-mgt/openapi"
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+	"github.com/ravenocx/hospital-mgt/sdk/nurse"
+	"github.com/ravenocx/hospital-mgt/sdk/patient"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type Server struct {
-	dbPool *pgxpool.Pool
-	app    *fiber.App
+	dbPool  *pgxpool.Pool
+	keyring *envelope.Keyring
+	config  config.Config
+	app     *fiber.App
+
+	// validates the requests against the OpenAPI document, placed after
+	// the authentication so only authenticated callers see the schema errors
+	validate fiber.Handler
+
+	// shared by the routes so they share connections and circuit breakers
+	patientClient *patient.Client
+	nurseClient   *nurse.Client
 }
 
-func NewServer(db *pgxpool.Pool, config config.Config) *Server {
+func NewServer(db *pgxpool.Pool, keyring *envelope.Keyring, config config.Config) *Server {
 	fiberConfig := fiber.Config{
 		ReadTimeout: time.Duration(config.ServerReadTimeout) * time.Second,
+		ErrorHandler: middleware.ErrorHandler,
 	}
 
 	app := fiber.New(fiberConfig)
@@ -28,14 +47,48 @@ func NewServer(db *pgxpool.Pool, config config.Config) *Server {
 
 	middleware.FiberMiddleware(app)
 
+	doc, err := openapi.Load()
+	if err != nil {
+		logging.Fatal("Failed to load the OpenAPI document", "error", err)
+	}
+
+	validate, err := middleware.OpenAPIValidator(doc)
+	if err != nil {
+		logging.Fatal("Failed to create the OpenAPI validator", "error", err)
+	}
+
+	app.Get("/openapi.json", func(c *fiber.Ctx) error {
+		return c.JSON(doc)
+	})
+
 	return &Server{
 		dbPool: db,
+		keyring: keyring,
+		config: config,
 		app : app,
+		validate: validate,
+
+		patientClient: patient.New(api.NewClient(httpclient.New(httpClientConfig(config, config.PatientServiceURL)))),
+		nurseClient:   nurse.New(api.NewClient(httpclient.New(httpClientConfig(config, config.NurseServiceURL)))),
+	}
+}
+
+func httpClientConfig(config config.Config, baseURL string) httpclient.Config {
+	clientConfig := httpclient.DefaultConfig(baseURL)
+	clientConfig.Timeout = time.Duration(config.HttpClientTimeout) * time.Millisecond
+	clientConfig.MaxRetries = config.HttpClientMaxRetries
+	clientConfig.BreakerThreshold = config.HttpBreakerThreshold
+	clientConfig.BreakerCooldown = time.Duration(config.HttpBreakerCooldown) * time.Second
+	// a client span per attempt, its traceparent sent along
+	clientConfig.WrapTransport = func(transport http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(transport)
 	}
+
+	return clientConfig
 }
 
 func (s *Server) StarApp(config config.Config) {
 	if err := s.app.Listen(config.ServerHost + ":" + config.ServerPort); err != nil {
-		log.Fatalf("Oops... Server is not running! Reason: %v", err)
+		logging.Fatal("Oops... Server is not running!", "error", err)
 	}
 }
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/medicalrecord"
+	"github.com/ravenocx/hospital-mgt/sdk/tracing"
+)
+
+func TestTraceparentReachesCalledServices(t *testing.T) {
+	if err := tracing.Init(context.Background(), "medical-record", ""); err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	var mu sync.Mutex
+	var traceparents []string
+	services := stubServices(t, func(r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		traceparents = append(traceparents, r.Header.Get("Traceparent"))
+	})
+
+	// the caller is in a trace already
+	clientConfig := httpclient.DefaultConfig("")
+	clientConfig.WrapTransport = func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Traceparent", "00-"+traceId+"-00f067aa0ba902b7-01")
+			return next.RoundTrip(req)
+		})
+	}
+
+	client := startServerWith(t, &fakeRepositories{}, services, clientConfig)
+	nurse := client.WithToken(testToken(t, testNurseId, "nurse"))
+
+	_, _, err := nurse.CreateRecord(context.Background(), medicalrecord.NewRecord{
+		IdentityNumber: testIdentityNumber,
+		Symptoms:       "fever",
+		Medications:    "paracetamol",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(traceparents) == 0 {
+		t.Fatal("the services were not called")
+	}
+	for _, traceparent := range traceparents {
+		if !strings.HasPrefix(traceparent, "00-"+traceId+"-") {
+			t.Errorf("got traceparent %q, want one in trace %s", traceparent, traceId)
+		}
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
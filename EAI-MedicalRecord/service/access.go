@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+const minAccessReasonLength = 10
+
+// checkRecordAccess evaluates the access policy of medical records:
+//   - admins can read every record but have to give a reason, which is kept
+//     in the access log
+//   - nurses can read the records of the patients admitted in one of their
+//     wards, or in an open encounter they are part of, or the ones they broke
+//     the glass for
+//   - everyone else is denied
+//
+// identityNumber is the patient being read, nil when listing. For a nurse
+// listing records, the returned user id is the one the listing must be
+// restricted to.
+func checkRecordAccess(ctx context.Context, repo repositories.MedicalRecordRepositories, requester models.RecordRequester, identityNumber *int64) (string, responses.CustomError) {
+	switch requester.Role {
+	case "admin":
+		reason := strings.TrimSpace(requester.Reason)
+		if len(reason) < minAccessReasonLength {
+			return "", responses.NewForbiddenError(fmt.Sprintf("admins must give a reason of at least %d characters to access medical records", minAccessReasonLength)).WithCode(responses.CodeAccessReasonRequired)
+		}
+
+		return "", responses.CustomError{}
+
+	case "nurse":
+		if identityNumber == nil {
+			return requester.UserId, responses.CustomError{}
+		}
+
+		allowed, err := repo.CanNurseAccessPatient(ctx, requester.UserId, *identityNumber)
+		if err != nil {
+			return "", responses.NewInternalServerError(fmt.Sprintf("failed to check medical record access : %+v", err.Error()))
+		}
+
+		if !allowed {
+			return "", responses.NewForbiddenError("patient is not in your ward or in an encounter you are part of, break the glass to access it").WithCode(responses.CodeAccessDenied)
+		}
+		return requester.UserId, responses.CustomError{}
+	}
+
+	return "", responses.NewForbiddenError("only admin and nurse can access medical records").WithCode(responses.CodeAccessDenied)
+}
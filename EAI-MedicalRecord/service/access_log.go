@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type AccessLogService interface {
+	GetAccessLogs(ctx context.Context, GetAccessLogQueries models.GetAccessLogQueries) ([]models.RecordAccessLog, responses.CustomError)
+	VerifyAccessLogs(ctx context.Context) (*models.AccessLogVerification, responses.CustomError)
+}
+
+type accessLogService struct {
+	repo repositories.AccessLogRepositories
+}
+
+func NewAccessLogService(repo repositories.AccessLogRepositories) AccessLogService {
+	return &accessLogService{repo}
+}
+
+// GetAccessLogs is the access history of the chart of a patient, latest first.
+func (s *accessLogService) GetAccessLogs(ctx context.Context, GetAccessLogQueries models.GetAccessLogQueries) ([]models.RecordAccessLog, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&GetAccessLogQueries); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	entries, err := s.repo.GetAccessLogs(ctx, GetAccessLogQueries)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get access logs : %+v", err.Error()))
+	}
+
+	return entries, responses.CustomError{}
+}
+
+func (s *accessLogService) VerifyAccessLogs(ctx context.Context) (*models.AccessLogVerification, responses.CustomError) {
+	verification, err := s.repo.VerifyAccessLogs(ctx)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to verify access logs : %+v", err.Error()))
+	}
+
+	return verification, responses.CustomError{}
+}
+
+// logRecordReads writes a read of medical records to the access log, one entry
+// per patient, and flags the reads made under a break-the-glass grant. reads
+// maps the patients read to their records, a record can be given more than
+// once. Records are not handed out when the read can not be logged.
+func logRecordReads(ctx context.Context, accessLogRepo repositories.AccessLogRepositories, breakGlassRepo repositories.BreakGlassRepositories, requester models.RecordRequester, accessType string, reads map[int64][]string) responses.CustomError {
+	var reason *string
+	if requester.Role == "admin" {
+		trimmed := strings.TrimSpace(requester.Reason)
+		reason = &trimmed
+	}
+
+	entries := []models.RecordAccessLog{}
+	for identityNumber, recordIds := range reads {
+		sort.Strings(recordIds)
+		recordIds = slices.Compact(recordIds)
+		reads[identityNumber] = recordIds
+		entries = append(entries, models.RecordAccessLog{
+			ReaderUserId:   requester.UserId,
+			ReaderRole:     requester.Role,
+			IdentityNumber: identityNumber,
+			RecordIds:      recordIds,
+			AccessType:     accessType,
+			Reason:         reason,
+			ClientIP:       requester.ClientIP,
+		})
+	}
+
+	// same order on every run, the map order is random
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].IdentityNumber < entries[j].IdentityNumber
+	})
+
+	if err := accessLogRepo.CreateAccessLogs(ctx, entries); err != nil {
+		return responses.NewInternalServerError(fmt.Sprintf("failed to write access log : %+v", err.Error()))
+	}
+
+	return flagBreakGlassReads(ctx, breakGlassRepo, requester, reads)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+// TestChartReadsLogged checks every path writes the records it hands out to
+// the access log, once each and with its own access type.
+func TestChartReadsLogged(t *testing.T) {
+	tests := []struct {
+		call       string
+		accessType string
+		recordIds  []string
+	}{
+		{call: "GetEncounters", accessType: models.AccessTypeEncounters, recordIds: []string{testRecordId, testOtherRecordId}},
+		{call: "GetVitalSignSeries", accessType: models.AccessTypeVitalSigns, recordIds: []string{testRecordId, testOtherRecordId}},
+		{call: "GetActiveMedications", accessType: models.AccessTypeMedications, recordIds: []string{testRecordId, testOtherRecordId}},
+		{call: "AmendRecord", accessType: models.AccessTypeAmendment, recordIds: []string{testRecordId}},
+	}
+
+	requesters := []models.RecordRequester{
+		{UserId: testNurseId, Role: "nurse", ClientIP: "10.0.0.7"},
+		{UserId: testNurseId, Role: "admin", Reason: "quarterly chart audit", ClientIP: "10.0.0.7"},
+	}
+
+	for _, tc := range tests {
+		for _, requester := range requesters {
+			t.Run(tc.call+"/"+requester.Role, func(t *testing.T) {
+				repo := &chartRepositories{allowed: true}
+
+				if custErr := chartCalls[tc.call](repo, requester); (custErr != responses.CustomError{}) {
+					t.Fatalf("unexpected error : %+v", custErr)
+				}
+
+				if len(repo.logs) != 1 {
+					t.Fatalf("got %d access log entries, want 1", len(repo.logs))
+				}
+
+				entry := repo.logs[0]
+				if entry.AccessType != tc.accessType || entry.IdentityNumber != testIdentityNumber || entry.ReaderRole != requester.Role || entry.ClientIP != requester.ClientIP {
+					t.Errorf("got entry %+v, want a %s read of the patient by the %s", entry, tc.accessType, requester.Role)
+				}
+				if !reflect.DeepEqual(entry.RecordIds, tc.recordIds) {
+					t.Errorf("got records %v, want %v", entry.RecordIds, tc.recordIds)
+				}
+				if (requester.Role == "admin") != (entry.Reason != nil) {
+					t.Errorf("got reason %v, want it logged for admins only", entry.Reason)
+				}
+			})
+		}
+	}
+}
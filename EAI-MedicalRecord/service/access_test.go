@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+const (
+	testIdentityNumber = int64(3201234567890001)
+	testRecordId       = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a001"
+	testOtherRecordId  = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a002"
+	testNurseId        = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a004"
+)
+
+// chartRepositories holds the chart of a single patient, readable by nurses
+// only when allowed is set, and remembers whether it was read or written and
+// the access log written.
+type chartRepositories struct {
+	repositories.MedicalRecordRepositories
+	repositories.EncounterRepositories
+	repositories.MedicationRepositories
+	repositories.BreakGlassRepositories
+	repositories.AccessLogRepositories
+	allowed bool
+	touched bool
+	logs    []models.RecordAccessLog
+}
+
+func (r *chartRepositories) CanNurseAccessPatient(ctx context.Context, userId string, patientIdentityNumber int64) (bool, error) {
+	return r.allowed, nil
+}
+
+func (r *chartRepositories) CreateAccessLogs(ctx context.Context, entries []models.RecordAccessLog) error {
+	r.logs = append(r.logs, entries...)
+	return nil
+}
+
+func (r *chartRepositories) GetActiveGrants(ctx context.Context, userId string) (map[int64]string, error) {
+	return map[int64]string{}, nil
+}
+
+func (r *chartRepositories) GetRecordVersion(ctx context.Context, recordId string) (*models.RecordVersion, error) {
+	return &models.RecordVersion{IdentityNumber: testIdentityNumber, Version: 1, Symptoms: "fever", Medications: "paracetamol"}, nil
+}
+
+func (r *chartRepositories) CreateAmendment(ctx context.Context, recordId string, version int, amendment *models.RecordAmendmentPayload, amendedBy *models.CreatedByDetail) error {
+	r.touched = true
+	return nil
+}
+
+func (r *chartRepositories) GetVitalSigns(ctx context.Context, filter models.GetVitalSignQueries) ([]models.VitalSignResponse, error) {
+	r.touched = true
+	return []models.VitalSignResponse{
+		{Type: models.VitalPulse, Unit: "bpm", RecordId: testRecordId},
+		{Type: models.VitalPulse, Unit: "bpm", RecordId: testOtherRecordId},
+		{Type: models.VitalTemperature, Unit: "celsius", RecordId: testRecordId},
+	}, nil
+}
+
+func (r *chartRepositories) GetActiveMedications(ctx context.Context, identityNumber int64, at time.Time) ([]models.MedicationOrderResponse, error) {
+	r.touched = true
+	return []models.MedicationOrderResponse{
+		{DrugCode: "N02BE01", RecordId: testOtherRecordId},
+		{DrugCode: "J01CA04", RecordId: testRecordId},
+	}, nil
+}
+
+func (r *chartRepositories) GetEncounters(ctx context.Context, filter models.GetEncounterQueries) ([]models.GetEncounterResponse, error) {
+	r.touched = true
+	return []models.GetEncounterResponse{
+		{IdentityNumber: testIdentityNumber, Records: []models.EncounterRecord{{ID: testOtherRecordId}}},
+		{IdentityNumber: testIdentityNumber, Records: []models.EncounterRecord{{ID: testRecordId}}},
+	}, nil
+}
+
+// chartCalls are the paths handing out or changing the chart of the patient
+// outside of the record endpoints.
+var chartCalls = map[string]func(repo *chartRepositories, requester models.RecordRequester) responses.CustomError{
+	"GetEncounters": func(repo *chartRepositories, requester models.RecordRequester) responses.CustomError {
+		identityNumber := testIdentityNumber
+		_, custErr := NewEncounterService(repo, repo, repo, repo).GetEncounters(context.Background(), models.GetEncounterQueries{IdentityNumber: &identityNumber, Limit: 5}, requester)
+		return custErr
+	},
+	"GetVitalSignSeries": func(repo *chartRepositories, requester models.RecordRequester) responses.CustomError {
+		identityNumber := testIdentityNumber
+		_, custErr := NewVitalSignService(repo, repo, repo).GetVitalSignSeries(context.Background(), models.GetVitalSignQueries{IdentityNumber: &identityNumber}, requester)
+		return custErr
+	},
+	"GetActiveMedications": func(repo *chartRepositories, requester models.RecordRequester) responses.CustomError {
+		_, custErr := NewMedicationService(repo, repo, repo, repo).GetActiveMedications(context.Background(), testIdentityNumber, requester)
+		return custErr
+	},
+	"AmendRecord": func(repo *chartRepositories, requester models.RecordRequester) responses.CustomError {
+		service := NewMedicalServiceService(repo, repo, repo, nil, repo, repo, nil, nil)
+		amendment := models.RecordAmendmentPayload{Symptoms: "high fever", Reason: "typo in the symptoms", BaseVersion: 1}
+		amendedBy := models.CreatedByDetail{UserId: requester.UserId, Nip: "303123456789", Name: "nurse one"}
+		_, _, custErr := service.AmendRecord(context.Background(), testRecordId, amendment, amendedBy, requester)
+		return custErr
+	},
+}
+
+// TestChartAccessDenied goes through every path with the requesters the
+// access policy turns away.
+func TestChartAccessDenied(t *testing.T) {
+	requesters := []struct {
+		name      string
+		requester models.RecordRequester
+		code      string
+	}{
+		{
+			name:      "nurse outside the ward",
+			requester: models.RecordRequester{UserId: testNurseId, Role: "nurse"},
+			code:      responses.CodeAccessDenied,
+		},
+		{
+			name:      "admin without a reason",
+			requester: models.RecordRequester{UserId: testNurseId, Role: "admin"},
+			code:      responses.CodeAccessReasonRequired,
+		},
+		{
+			name:      "admin with a short reason",
+			requester: models.RecordRequester{UserId: testNurseId, Role: "admin", Reason: "  audit   "},
+			code:      responses.CodeAccessReasonRequired,
+		},
+		{
+			name:      "other role",
+			requester: models.RecordRequester{UserId: testNurseId, Role: "patient"},
+			code:      responses.CodeAccessDenied,
+		},
+	}
+
+	for name, call := range chartCalls {
+		for _, tc := range requesters {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				repo := &chartRepositories{}
+
+				custErr := call(repo, tc.requester)
+				if custErr.StatusCode != http.StatusForbidden || custErr.Code != tc.code {
+					t.Errorf("got %d %s (%s), want 403 %s", custErr.StatusCode, custErr.Code, custErr.Message, tc.code)
+				}
+				if repo.touched {
+					t.Error("the chart was read or written before the access was denied")
+				}
+				if len(repo.logs) != 0 {
+					t.Errorf("got %d access log entries for a denied access", len(repo.logs))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+// AmendRecord adds a new version of the record content. The original and the
+// previous versions are kept untouched, fields left empty keep their current
+// value.
+func (s *medicalRecordService) AmendRecord(ctx context.Context, recordId string, amendment models.RecordAmendmentPayload, amendedBy models.CreatedByDetail, requester models.RecordRequester) (*models.RecordAmendmentResponse, []models.AllergyWarning, responses.CustomError) {
+	// a wrong vital sign, medication order or diagnosis is corrected with a
+	// new record, they aren't part of the versions
+	if len(amendment.Vitals) > 0 || len(amendment.MedicationOrders) > 0 || len(amendment.Diagnoses) > 0 {
+		return nil, nil, responses.NewBadRequestError("vitals, medication orders and diagnoses can't be amended, register a new record with the corrected values").WithCode(responses.CodeFieldNotAmendable)
+	}
+
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&amendment); err != nil {
+		return nil, nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, err := uuid.Parse(recordId); err != nil {
+		return nil, nil, responses.NewNotFoundError("medical record not found or id is not in valid format").WithCode(responses.CodeRecordNotFound)
+	}
+
+	current, err := s.repo.GetRecordVersion(ctx, recordId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, responses.NewNotFoundError("medical record not found").WithCode(responses.CodeRecordNotFound)
+		}
+		return nil, nil, responses.NewInternalServerError(fmt.Sprintf("failed to get medical record : %+v", err.Error()))
+	}
+
+	if _, custErr := checkRecordAccess(ctx, s.repo, requester, &current.IdentityNumber); (custErr != responses.CustomError{}) {
+		return nil, nil, custErr
+	}
+
+	if amendment.BaseVersion != current.Version {
+		return nil, nil, responses.NewConflictError(fmt.Sprintf("medical record has been amended since version %d, the current version is %d", amendment.BaseVersion, current.Version)).WithCode(responses.CodeRecordVersionConflict)
+	}
+
+	if amendment.Symptoms == "" {
+		amendment.Symptoms = current.Symptoms
+	}
+	if amendment.Medications == "" {
+		amendment.Medications = current.Medications
+	}
+
+	if amendment.Symptoms == current.Symptoms && amendment.Medications == current.Medications {
+		return nil, nil, responses.NewBadRequestError("amendment doesn't change anything").WithCode(responses.CodeEmptyAmendment)
+	}
+
+	// the amended record is handed back, with the content of the current
+	// version for the fields left out
+	reads := map[int64][]string{current.IdentityNumber: {recordId}}
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeAmendment, reads); (custErr != responses.CustomError{}) {
+		return nil, nil, custErr
+	}
+
+	version := current.Version + 1
+	err = s.repo.CreateAmendment(ctx, recordId, version, &amendment, &amendedBy)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, nil, responses.NewConflictError(fmt.Sprintf("medical record has been amended since version %d", amendment.BaseVersion)).WithCode(responses.CodeRecordVersionConflict)
+		}
+		return nil, nil, responses.NewInternalServerError(fmt.Sprintf("failed to amend medical record : %+v", err.Error()))
+	}
+
+	warnings := []models.AllergyWarning{}
+	if amendment.Medications != current.Medications {
+		allergies, err := s.repo.GetPatientAllergies(ctx, current.IdentityNumber)
+		if err != nil {
+			return nil, nil, responses.NewInternalServerError(fmt.Sprintf("failed to get patient allergies : %+v", err.Error()))
+		}
+		warnings = checkAllergyConflicts(amendment.Medications, allergies)
+	}
+
+	return &models.RecordAmendmentResponse{
+		RecordId:    recordId,
+		Version:     version,
+		Symptoms:    amendment.Symptoms,
+		Medications: amendment.Medications,
+		Reason:      amendment.Reason,
+	}, warnings, responses.CustomError{}
+}
+
+// GetRecordHistory returns every version of the record with the fields that
+// changed compared to the version before it.
+func (s *medicalRecordService) GetRecordHistory(ctx context.Context, recordId string, requester models.RecordRequester) ([]models.RecordHistoryResponse, responses.CustomError) {
+	if _, err := uuid.Parse(recordId); err != nil {
+		return nil, responses.NewNotFoundError("medical record not found or id is not in valid format").WithCode(responses.CodeRecordNotFound)
+	}
+
+	current, err := s.repo.GetRecordVersion(ctx, recordId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, responses.NewNotFoundError("medical record not found").WithCode(responses.CodeRecordNotFound)
+		}
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get medical record : %+v", err.Error()))
+	}
+
+	if _, custErr := checkRecordAccess(ctx, s.repo, requester, &current.IdentityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	reads := map[int64][]string{current.IdentityNumber: {recordId}}
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeHistory, reads); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	history, err := s.repo.GetRecordHistory(ctx, recordId)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get medical record history : %+v", err.Error()))
+	}
+
+	if len(history) == 0 {
+		return nil, responses.NewNotFoundError("medical record not found").WithCode(responses.CodeRecordNotFound)
+	}
+
+	for i := 1; i < len(history); i++ {
+		history[i].Changes = diffRecordVersions(history[i-1], history[i])
+	}
+
+	return history, responses.CustomError{}
+}
+
+func diffRecordVersions(from, to models.RecordHistoryResponse) []models.FieldChange {
+	changes := []models.FieldChange{}
+
+	if from.Symptoms != to.Symptoms {
+		changes = append(changes, models.FieldChange{Field: "symptoms", From: from.Symptoms, To: to.Symptoms})
+	}
+
+	if from.Medications != to.Medications {
+		changes = append(changes, models.FieldChange{Field: "medications", From: from.Medications, To: to.Medications})
+	}
+
+	return changes
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+// TestAmendStructuredContent checks an amendment touching the vital signs,
+// medication orders or diagnoses is turned away before the record is read.
+func TestAmendStructuredContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		amendment models.RecordAmendmentPayload
+	}{
+		{"vitals", models.RecordAmendmentPayload{Vitals: []models.VitalSignPayload{{Type: models.VitalPulse, Unit: "bpm"}}}},
+		{"medication orders", models.RecordAmendmentPayload{MedicationOrders: []models.MedicationOrderPayload{{DrugCode: "N02BE01"}}}},
+		{"diagnoses", models.RecordAmendmentPayload{Diagnoses: []models.DiagnosisPayload{{Code: "J18.9"}}}},
+	}
+
+	requester := models.RecordRequester{UserId: testNurseId, Role: "nurse"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &chartRepositories{allowed: true}
+			service := NewMedicalServiceService(repo, repo, repo, nil, repo, repo, nil, nil)
+
+			tt.amendment.Symptoms = "high fever"
+			tt.amendment.Reason = "typo in the chart"
+			tt.amendment.BaseVersion = 1
+
+			_, _, custErr := service.AmendRecord(context.Background(), testRecordId, tt.amendment, models.CreatedByDetail{UserId: testNurseId}, requester)
+			if custErr.StatusCode != http.StatusBadRequest || custErr.Code != responses.CodeFieldNotAmendable {
+				t.Errorf("got %d %s (%s), want 400 %s", custErr.StatusCode, custErr.Code, custErr.Message, responses.CodeFieldNotAmendable)
+			}
+			if repo.touched || len(repo.logs) != 0 {
+				t.Error("the record was amended or read")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type BreakGlassService interface {
+	BreakGlass(ctx context.Context, newGrant models.BreakGlassPayload, requester models.RecordRequester) (*models.BreakGlassGrant, responses.CustomError)
+	GetBreakGlassGrants(ctx context.Context, GetBreakGlassQueries models.GetBreakGlassQueries) ([]models.BreakGlassGrant, responses.CustomError)
+	ReviewBreakGlass(ctx context.Context, grantId string, review models.BreakGlassReviewPayload, reviewedBy string) (*models.BreakGlassGrant, responses.CustomError)
+}
+
+type breakGlassService struct {
+	repo       repositories.BreakGlassRepositories
+	recordRepo repositories.MedicalRecordRepositories
+	duration   time.Duration
+}
+
+func NewBreakGlassService(repo repositories.BreakGlassRepositories, recordRepo repositories.MedicalRecordRepositories, duration time.Duration) BreakGlassService {
+	return &breakGlassService{repo, recordRepo, duration}
+}
+
+// BreakGlass gives a nurse access to the records of a patient outside of their
+// wards and encounters for a limited time. Admins already read every record
+// with a reason, they do not need it.
+func (s *breakGlassService) BreakGlass(ctx context.Context, newGrant models.BreakGlassPayload, requester models.RecordRequester) (*models.BreakGlassGrant, responses.CustomError) {
+	if requester.Role != "nurse" {
+		return nil, responses.NewForbiddenError("only nurses can break the glass").WithCode(responses.CodeAccessDenied)
+	}
+
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&newGrant); err != nil {
+		return nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, err := s.recordRepo.GetPatient(ctx, newGrant.IdentityNumber); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, responses.NewNotFoundError("patient with identity_number is not exist").WithCode(responses.CodePatientNotFound)
+		}
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to check patient : %+v", err.Error()))
+	}
+
+	grant, err := s.repo.CreateGrant(ctx, requester, &newGrant, s.duration)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to break the glass : %+v", err.Error()))
+	}
+
+	return grant, responses.CustomError{}
+}
+
+func (s *breakGlassService) GetBreakGlassGrants(ctx context.Context, GetBreakGlassQueries models.GetBreakGlassQueries) ([]models.BreakGlassGrant, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&GetBreakGlassQueries); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	grants, err := s.repo.GetGrants(ctx, GetBreakGlassQueries)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get break the glass grants : %+v", err.Error()))
+	}
+
+	return grants, responses.CustomError{}
+}
+
+func (s *breakGlassService) ReviewBreakGlass(ctx context.Context, grantId string, review models.BreakGlassReviewPayload, reviewedBy string) (*models.BreakGlassGrant, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&review); err != nil {
+		return nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, err := uuid.Parse(grantId); err != nil {
+		return nil, responses.NewNotFoundError("break the glass grant not found or id is not in valid format").WithCode(responses.CodeGrantNotFound)
+	}
+
+	res, err := s.repo.ReviewGrant(ctx, grantId, &review, reviewedBy)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to review break the glass grant : %+v", err.Error()))
+	}
+
+	grant, err := s.repo.GetGrant(ctx, grantId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, responses.NewNotFoundError("break the glass grant not found").WithCode(responses.CodeGrantNotFound)
+		}
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get break the glass grant : %+v", err.Error()))
+	}
+
+	if res.RowsAffected() == 0 {
+		return nil, responses.NewConflictError(fmt.Sprintf("break the glass grant is already %s", grant.ReviewStatus)).WithCode(responses.CodeGrantAlreadyReviewed)
+	}
+
+	return grant, responses.CustomError{}
+}
+
+// flagBreakGlassReads writes the records read by a nurse under an active
+// break-the-glass grant to the access log of the grant. reads maps the
+// patients read to their records.
+func flagBreakGlassReads(ctx context.Context, repo repositories.BreakGlassRepositories, requester models.RecordRequester, reads map[int64][]string) responses.CustomError {
+	if requester.Role != "nurse" || len(reads) == 0 {
+		return responses.CustomError{}
+	}
+
+	grants, err := repo.GetActiveGrants(ctx, requester.UserId)
+	if err != nil {
+		return responses.NewInternalServerError(fmt.Sprintf("failed to get break the glass grants : %+v", err.Error()))
+	}
+
+	for identityNumber, recordIds := range reads {
+		grantId, ok := grants[identityNumber]
+		if !ok || len(recordIds) == 0 {
+			continue
+		}
+
+		if err := repo.CreateReads(ctx, grantId, recordIds); err != nil {
+			return responses.NewInternalServerError(fmt.Sprintf("failed to log break the glass read : %+v", err.Error()))
+		}
+	}
+
+	return responses.CustomError{}
+}
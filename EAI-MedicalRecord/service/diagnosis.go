@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+type DiagnosisService interface {
+	SearchIcd10Codes(ctx context.Context, GetIcd10Queries models.GetIcd10Queries) ([]models.Icd10Code, responses.CustomError)
+}
+
+type diagnosisService struct {
+	repo repositories.DiagnosisRepositories
+}
+
+func NewDiagnosisService(repo repositories.DiagnosisRepositories) DiagnosisService {
+	return &diagnosisService{repo}
+}
+
+func (s *diagnosisService) SearchIcd10Codes(ctx context.Context, GetIcd10Queries models.GetIcd10Queries) ([]models.Icd10Code, responses.CustomError) {
+	codes, err := s.repo.SearchIcd10Codes(ctx, GetIcd10Queries)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get ICD-10 codes : %+v", err.Error()))
+	}
+
+	return codes, responses.CustomError{}
+}
+
+// a category (letter, two digits) optionally followed by a subcode
+var icd10CodeRegex = regexp.MustCompile(`^[A-Z][0-9][0-9A-Z](\.[0-9A-Z]{1,4})?$`)
+
+// normalizeIcd10Code upper-cases the code and adds the dot after the category
+// when it was left out, so "j189" becomes "J18.9".
+func normalizeIcd10Code(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) > 3 && !strings.Contains(code, ".") {
+		code = code[:3] + "." + code[3:]
+	}
+
+	return code, icd10CodeRegex.MatchString(code)
+}
+
+// checkDiagnoses validates the diagnoses against the ICD-10 catalog. When a
+// record has diagnoses exactly one of them must be the primary one.
+func checkDiagnoses(ctx context.Context, repo repositories.DiagnosisRepositories, payloads []models.DiagnosisPayload) ([]models.DiagnosisPayload, responses.CustomError) {
+	if len(payloads) == 0 {
+		return nil, responses.CustomError{}
+	}
+
+	diagnoses := make([]models.DiagnosisPayload, 0, len(payloads))
+	codes := make([]string, 0, len(payloads))
+	seen := map[string]bool{}
+	primaries := 0
+
+	for i, payload := range payloads {
+		code, ok := normalizeIcd10Code(payload.Code)
+		if !ok {
+			return nil, responses.NewBadRequestError(fmt.Sprintf("diagnoses[%d]: %s is not a valid ICD-10 code", i, payload.Code)).WithCode(responses.CodeInvalidDiagnosis)
+		}
+		if seen[code] {
+			return nil, responses.NewBadRequestError(fmt.Sprintf("diagnoses[%d]: %s is listed more than once", i, code)).WithCode(responses.CodeInvalidDiagnosis)
+		}
+		seen[code] = true
+
+		if payload.Type == models.DiagnosisPrimary {
+			primaries++
+		}
+
+		diagnoses = append(diagnoses, models.DiagnosisPayload{Code: code, Type: payload.Type})
+		codes = append(codes, code)
+	}
+
+	if primaries != 1 {
+		return nil, responses.NewBadRequestError("diagnoses must have exactly one primary diagnosis").WithCode(responses.CodeInvalidDiagnosis)
+	}
+
+	known, err := repo.GetIcd10Codes(ctx, codes)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get ICD-10 codes : %+v", err.Error()))
+	}
+
+	for i, code := range codes {
+		if _, ok := known[code]; !ok {
+			return nil, responses.NewBadRequestError(fmt.Sprintf("diagnoses[%d]: %s is not in the ICD-10 catalog", i, code)).WithCode(responses.CodeInvalidDiagnosis)
+		}
+	}
+
+	return diagnoses, responses.CustomError{}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type EncounterService interface {
+	OpenEncounter(ctx context.Context, newEncounter models.EncounterRegistrationPayload, openedBy string) (string, responses.CustomError)
+	DischargeEncounter(ctx context.Context, encounterId string, discharge models.EncounterDischargePayload, dischargedBy string) responses.CustomError
+	GetEncounters(ctx context.Context, GetEncounterQueries models.GetEncounterQueries, requester models.RecordRequester) ([]models.GetEncounterResponse, responses.CustomError)
+}
+
+type encounterService struct {
+	repo           repositories.EncounterRepositories
+	recordRepo     repositories.MedicalRecordRepositories
+	breakGlassRepo repositories.BreakGlassRepositories
+	accessLogRepo  repositories.AccessLogRepositories
+}
+
+func NewEncounterService(repo repositories.EncounterRepositories, recordRepo repositories.MedicalRecordRepositories, breakGlassRepo repositories.BreakGlassRepositories, accessLogRepo repositories.AccessLogRepositories) EncounterService {
+	return &encounterService{repo, recordRepo, breakGlassRepo, accessLogRepo}
+}
+
+func (s *encounterService) OpenEncounter(ctx context.Context, newEncounter models.EncounterRegistrationPayload, openedBy string) (string, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&newEncounter); err != nil {
+		return "", responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	staffIds := []string{}
+	seen := map[string]bool{}
+	for _, id := range newEncounter.AttendingStaffIds {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return "", responses.NewBadRequestError(fmt.Sprintf("attending staff id %s is not in valid format", id)).WithCode(responses.CodeInvalidStaffId)
+		}
+		if !seen[parsed.String()] {
+			seen[parsed.String()] = true
+			staffIds = append(staffIds, parsed.String())
+		}
+	}
+	newEncounter.AttendingStaffIds = staffIds
+
+	if _, err := s.recordRepo.GetPatient(ctx, newEncounter.IdentityNumber); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", responses.NewNotFoundError("patient with identity_number is not exist").WithCode(responses.CodePatientNotFound)
+		}
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to check patient : %+v", err.Error()))
+	}
+
+	count, err := s.repo.CountUsers(ctx, staffIds)
+	if err != nil {
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to check attending staff : %+v", err.Error()))
+	}
+	if count != len(staffIds) {
+		return "", responses.NewNotFoundError("one or more attending staff is not exist").WithCode(responses.CodeStaffNotFound)
+	}
+
+	if _, err := s.repo.GetOpenEncounter(ctx, newEncounter.IdentityNumber); err == nil {
+		return "", responses.NewConflictError("patient already has an open encounter").WithCode(responses.CodeEncounterConflict)
+	} else if err != pgx.ErrNoRows {
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to check open encounter : %+v", err.Error()))
+	}
+
+	id, err := s.repo.CreateEncounter(ctx, &newEncounter, openedBy)
+	if err != nil {
+		// lost the race against another request opening an encounter
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return "", responses.NewConflictError("patient already has an open encounter").WithCode(responses.CodeEncounterConflict)
+		}
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to open encounter : %+v", err.Error()))
+	}
+
+	return id, responses.CustomError{}
+}
+
+func (s *encounterService) DischargeEncounter(ctx context.Context, encounterId string, discharge models.EncounterDischargePayload, dischargedBy string) responses.CustomError {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&discharge); err != nil {
+		return responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, err := uuid.Parse(encounterId); err != nil {
+		return responses.NewNotFoundError("encounter not found or encounterId is not in valid format").WithCode(responses.CodeEncounterNotFound)
+	}
+
+	res, err := s.repo.DischargeEncounter(ctx, encounterId, discharge.DischargeSummary, dischargedBy)
+	if err != nil {
+		return responses.NewInternalServerError(fmt.Sprintf("failed to discharge encounter : %+v", err.Error()))
+	}
+
+	if res.RowsAffected() == 0 {
+		return responses.NewNotFoundError("encounter not found or already discharged").WithCode(responses.CodeEncounterNotFound)
+	}
+
+	return responses.CustomError{}
+}
+
+func (s *encounterService) GetEncounters(ctx context.Context, GetEncounterQueries models.GetEncounterQueries, requester models.RecordRequester) ([]models.GetEncounterResponse, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&GetEncounterQueries); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	// the encounters carry the records of the patient, they follow the same
+	// access policy
+	accessibleBy, custErr := checkRecordAccess(ctx, s.recordRepo, requester, GetEncounterQueries.IdentityNumber)
+	if (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+	GetEncounterQueries.AccessibleBy = accessibleBy
+
+	encounters, err := s.repo.GetEncounters(ctx, GetEncounterQueries)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get encounters : %+v", err.Error()))
+	}
+
+	reads := map[int64][]string{*GetEncounterQueries.IdentityNumber: {}}
+	for _, encounter := range encounters {
+		for _, record := range encounter.Records {
+			reads[encounter.IdentityNumber] = append(reads[encounter.IdentityNumber], record.ID)
+		}
+	}
+
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeEncounters, reads); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	return encounters, responses.CustomError{}
+}
+
+// checkEncounter makes sure a record can be attached to the encounter: it has
+// to exist, belong to the same patient and still be open.
+func checkEncounter(ctx context.Context, repo repositories.EncounterRepositories, encounterId string, identityNumber int64) responses.CustomError {
+	if _, err := uuid.Parse(encounterId); err != nil {
+		return responses.NewNotFoundError("encounter not found or encounterId is not in valid format").WithCode(responses.CodeEncounterNotFound)
+	}
+
+	encounter, err := repo.GetEncounter(ctx, encounterId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return responses.NewNotFoundError("encounter not found").WithCode(responses.CodeEncounterNotFound)
+		}
+		return responses.NewInternalServerError(fmt.Sprintf("failed to get encounter : %+v", err.Error()))
+	}
+
+	if encounter.IdentityNumber != identityNumber {
+		return responses.NewBadRequestError("encounter doesn't belong to the patient").WithCode(responses.CodeEncounterMismatch)
+	}
+
+	if encounter.Status != models.EncounterStatusOpen {
+		return responses.NewConflictError("encounter is already discharged").WithCode(responses.CodeEncounterDischarged)
+	}
+
+	return responses.CustomError{}
+}
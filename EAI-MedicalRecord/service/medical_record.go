@@ -2,75 +2,139 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"net/url"
-	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/ravenocx/hospital-mgt/models"
 	"github.com/ravenocx/hospital-mgt/repositories"
 	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/nurse"
+	"github.com/ravenocx/hospital-mgt/sdk/patient"
 	"github.com/ravenocx/hospital-mgt/utils"
 )
 
 type MedicalRecordService interface {
-	RegisterRecord(ctx context.Context, newRecord models.RecordRegistrationPayload, createdByDetail models.CreatedByDetail, jwtToken string) responses.CustomError
-	GetRecord(ctx context.Context, GetRecordQueries models.GetRecordQueries) ([]models.GetRecordResponse, responses.CustomError)
-	GetNurseDetail(nurseId string, jwtToken string) ([]models.Nurse, responses.CustomError)
+	RegisterRecord(ctx context.Context, newRecord models.RecordRegistrationPayload, createdByDetail models.CreatedByDetail, jwtToken string) (*models.GetRecordResponse, []models.AllergyWarning, responses.CustomError)
+	GetRecord(ctx context.Context, GetRecordQueries models.GetRecordQueries, requester models.RecordRequester) ([]models.GetRecordResponse, responses.CustomError)
+	GetRecordById(ctx context.Context, recordId string, requester models.RecordRequester) (*models.GetRecordResponse, responses.CustomError)
+	GetNurseDetail(ctx context.Context, nurseId string, jwtToken string) (*nurse.User, responses.CustomError)
+	AmendRecord(ctx context.Context, recordId string, amendment models.RecordAmendmentPayload, amendedBy models.CreatedByDetail, requester models.RecordRequester) (*models.RecordAmendmentResponse, []models.AllergyWarning, responses.CustomError)
+	GetRecordHistory(ctx context.Context, recordId string, requester models.RecordRequester) ([]models.RecordHistoryResponse, responses.CustomError)
 }
 
 type medicalRecordService struct {
-	repo repositories.MedicalRecordRepositories
+	repo           repositories.MedicalRecordRepositories
+	encounterRepo  repositories.EncounterRepositories
+	medicationRepo repositories.MedicationRepositories
+	diagnosisRepo  repositories.DiagnosisRepositories
+	breakGlassRepo repositories.BreakGlassRepositories
+	accessLogRepo  repositories.AccessLogRepositories
+	patientClient  *patient.Client
+	nurseClient    *nurse.Client
 }
 
-func NewMedicalServiceService(repo repositories.MedicalRecordRepositories) MedicalRecordService {
-	return &medicalRecordService{repo}
+func NewMedicalServiceService(repo repositories.MedicalRecordRepositories, encounterRepo repositories.EncounterRepositories, medicationRepo repositories.MedicationRepositories, diagnosisRepo repositories.DiagnosisRepositories, breakGlassRepo repositories.BreakGlassRepositories, accessLogRepo repositories.AccessLogRepositories, patientClient *patient.Client, nurseClient *nurse.Client) MedicalRecordService {
+	return &medicalRecordService{repo, encounterRepo, medicationRepo, diagnosisRepo, breakGlassRepo, accessLogRepo, patientClient, nurseClient}
 }
 
-func (s *medicalRecordService) RegisterRecord(ctx context.Context, newRecord models.RecordRegistrationPayload, createdByDetail models.CreatedByDetail, jwtToken string) responses.CustomError {
+func (s *medicalRecordService) RegisterRecord(ctx context.Context, newRecord models.RecordRegistrationPayload, createdByDetail models.CreatedByDetail, jwtToken string) (*models.GetRecordResponse, []models.AllergyWarning, responses.CustomError) {
 	validate := utils.NewValidator()
 
 	if err := validate.Struct(&newRecord); err != nil {
-		return responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+		return nil, nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
 	}
 
-	existingPatient, err := GetPatient(newRecord.IdentityNumber, jwtToken) // TODO : get patient should consume endpoint get patientn
+	vitals, err := normalizeVitalSigns(newRecord.Vitals, time.Now())
 	if err != nil {
-		if err.Error() == "patient with identityNumber is not exist" {
-			return responses.NewNotFoundError("patient with identity_number is not exist")
+		return nil, nil, responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidVitalSigns)
+	}
+
+	if custErr := s.checkPatient(ctx, newRecord.IdentityNumber, jwtToken); (custErr != responses.CustomError{}) {
+		return nil, nil, custErr
+	}
+
+	if newRecord.EncounterId != "" {
+		if custErr := checkEncounter(ctx, s.encounterRepo, newRecord.EncounterId, newRecord.IdentityNumber); (custErr != responses.CustomError{}) {
+			return nil, nil, custErr
 		}
+	}
 
-		log.Println(err.Error())
-		return responses.NewInternalServerError(err.Error())
+	orders, custErr := checkMedicationOrders(ctx, s.medicationRepo, newRecord.MedicationOrders, time.Now())
+	if (custErr != responses.CustomError{}) {
+		return nil, nil, custErr
+	}
 
+	diagnoses, custErr := checkDiagnoses(ctx, s.diagnosisRepo, newRecord.Diagnoses)
+	if (custErr != responses.CustomError{}) {
+		return nil, nil, custErr
 	}
 
-	if existingPatient == nil {
-		return responses.NewNotFoundError("patient with identity_number is not exist")
+	// the orders are checked for allergies along with the free text
+	orderedMedications := summarizeMedicationOrders(orders)
+	if newRecord.Medications == "" {
+		newRecord.Medications = orderedMedications
 	}
 
-	err = s.repo.CreateRecord(ctx, &newRecord, &createdByDetail)
+	allergies, err := s.repo.GetPatientAllergies(ctx, newRecord.IdentityNumber)
 	if err != nil {
-		return responses.NewInternalServerError(fmt.Sprintf("failed to create new medical record : %+v", err.Error()))
+		return nil, nil, responses.NewInternalServerError(fmt.Sprintf("failed to get patient allergies : %+v", err.Error()))
 	}
 
-	return responses.CustomError{}
+	id, err := s.repo.CreateRecord(ctx, &newRecord, &createdByDetail, vitals, orders, diagnoses)
+	if err != nil {
+		return nil, nil, responses.NewInternalServerError(fmt.Sprintf("failed to create new medical record : %+v", err.Error()))
+	}
+
+	record, err := s.repo.GetRecordById(ctx, id)
+	if err != nil {
+		return nil, nil, responses.NewInternalServerError(fmt.Sprintf("failed to get created medical record : %+v", err.Error()))
+	}
+
+	return record, checkAllergyConflicts(newRecord.Medications+"; "+orderedMedications, allergies), responses.CustomError{}
 }
 
-func (s *medicalRecordService) GetRecord(ctx context.Context, GetRecordQueries models.GetRecordQueries) ([]models.GetRecordResponse, responses.CustomError) {
+func (s *medicalRecordService) GetRecord(ctx context.Context, GetRecordQueries models.GetRecordQueries, requester models.RecordRequester) ([]models.GetRecordResponse, responses.CustomError) {
 
 	validate := utils.NewValidator()
 
-	if GetRecordQueries.IdentityNumber != nil {
-		if err := validate.Struct(&GetRecordQueries); err != nil {
-			return nil, responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+	if err := validate.Struct(&GetRecordQueries); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if GetRecordQueries.CreatedByUserId != "" {
+		if _, err := uuid.Parse(GetRecordQueries.CreatedByUserId); err != nil {
+			return nil, responses.NewBadRequestError("createdBy.userId is not in valid format").WithCode(responses.CodeInvalidFilter)
 		}
 	}
 
+	if GetRecordQueries.EncounterId != "" {
+		if _, err := uuid.Parse(GetRecordQueries.EncounterId); err != nil {
+			return nil, responses.NewBadRequestError("encounterId is not in valid format").WithCode(responses.CodeInvalidFilter)
+		}
+	}
+
+	if GetRecordQueries.DiagnosisCode != "" {
+		code, ok := normalizeIcd10Code(GetRecordQueries.DiagnosisCode)
+		if !ok {
+			return nil, responses.NewBadRequestError("diagnosisCode is not a valid ICD-10 code").WithCode(responses.CodeInvalidFilter)
+		}
+		GetRecordQueries.DiagnosisCode = code
+	}
+
+	accessibleBy, custErr := checkRecordAccess(ctx, s.repo, requester, GetRecordQueries.IdentityNumber)
+	if (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+	GetRecordQueries.AccessibleBy = accessibleBy
+
 	patients, err := s.repo.GetRecord(ctx, GetRecordQueries)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -79,119 +143,134 @@ func (s *medicalRecordService) GetRecord(ctx context.Context, GetRecordQueries m
 		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get medical record : %+v", err.Error()))
 	}
 
-	return patients, responses.CustomError{}
-}
-
-func GetPatient(identityNumber int64, jwtToken string) ([]models.Patient, error) {
-	medicalUserUrl := "http://localhost:5000/v1/medical/patient"
-	params := url.Values{}
-	params.Add("identityNumber", strconv.FormatInt(identityNumber, 10))
-
-	reqURL := fmt.Sprintf("%s?%s", medicalUserUrl, params.Encode())
+	reads := map[int64][]string{}
+	// looking for the records of a patient is logged even when there are none
+	if GetRecordQueries.IdentityNumber != nil {
+		reads[*GetRecordQueries.IdentityNumber] = []string{}
+	}
+	for _, record := range patients {
+		identityNumber := record.IdentityDetail.IdentityNumber
+		reads[identityNumber] = append(reads[identityNumber], record.ID)
+	}
 
-	req, err := http.NewRequest("GET", reqURL, nil)
-	if err != nil {
-		return nil, err
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeList, reads); (custErr != responses.CustomError{}) {
+		return nil, custErr
 	}
 
-	req.Header.Set("Authorization", "Bearer "+jwtToken)
+	return patients, responses.CustomError{}
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+func (s *medicalRecordService) GetRecordById(ctx context.Context, recordId string, requester models.RecordRequester) (*models.GetRecordResponse, responses.CustomError) {
+	if _, err := uuid.Parse(recordId); err != nil {
+		return nil, responses.NewNotFoundError("medical record not found or id is not in valid format").WithCode(responses.CodeRecordNotFound)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, responses.NewNotFoundError("patient with identityNumber is not exist")
+	record, err := s.repo.GetRecordById(ctx, recordId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, responses.NewNotFoundError("medical record not found").WithCode(responses.CodeRecordNotFound)
 		}
-		return nil, responses.NewInternalServerError("failed to consume get patient endpoint")
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get medical record : %+v", err.Error()))
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if _, custErr := checkRecordAccess(ctx, s.repo, requester, &record.IdentityDetail.IdentityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
 	}
 
-	var patient models.PatientResponse
-	err = json.Unmarshal(body, &patient)
-	if err != nil {
-		log.Printf("errJson : %+v", err)
-		return nil, err
+	reads := map[int64][]string{record.IdentityDetail.IdentityNumber: {record.ID}}
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeSingle, reads); (custErr != responses.CustomError{}) {
+		return nil, custErr
 	}
 
-	// if patient.Message == "success" {
-	// 	for _, patient := range patient.Data {
-	// 		fmt.Printf("User ID: %s, NIP: %s, Name: %s", )
-	// 	}
+	return record, responses.CustomError{}
+}
 
-	// 	// If you only want the first (newest) patient's details:
-	// 	if len(patient.Data) > 0 {
-	// 		firstpatient := patient.Data[0]
-	// 		fmt.Printf("Newest patient - User ID: %s, NIP: %s, Name: %s\n", firstpatient.UserID, firstpatient.NIP, firstpatient.Name)
-	// 	}
-	// } else {
-	// 	log.Println("Failed to fetch patient details")
-	// }
+// checkAllergyConflicts returns a warning for every recorded allergy whose
+// substance is mentioned in the medications text. It never blocks the record,
+// the nurse stays responsible for the final decision.
+func checkAllergyConflicts(medications string, allergies []models.PatientAllergy) []models.AllergyWarning {
+	warnings := []models.AllergyWarning{}
+	medications = strings.ToLower(medications)
+
+	for _, allergy := range allergies {
+		substance := strings.ToLower(strings.TrimSpace(allergy.Substance))
+		if substance == "" || !containsWord(medications, substance) {
+			continue
+		}
 
-	return patient.Data, nil
-}
+		warnings = append(warnings, models.AllergyWarning{
+			Substance: allergy.Substance,
+			Reaction:  allergy.Reaction,
+			Severity:  allergy.Severity,
+			Message:   fmt.Sprintf("patient is recorded as allergic to %s (%s reaction: %s)", allergy.Substance, allergy.Severity, allergy.Reaction),
+		})
+	}
 
-func (s *medicalRecordService) GetNurseDetail(nurseId string, jwtToken string) ([]models.Nurse, responses.CustomError) {
-	nurseUrl := "http://localhost:4000/v1/user"
-	params := url.Values{}
-	params.Add("userId", nurseId)
+	return warnings
+}
 
-	reqURL := fmt.Sprintf("%s?%s", nurseUrl, params.Encode())
+// containsWord reports whether substr appears in s on word boundaries, so an
+// allergy to "sulfa" matches "sulfa drugs" but not "sulfate".
+func containsWord(s, substr string) bool {
+	for i := 0; ; {
+		idx := strings.Index(s[i:], substr)
+		if idx < 0 {
+			return false
+		}
+		start := i + idx
+		end := start + len(substr)
 
-	req, err := http.NewRequest("GET", reqURL, nil)
-	if err != nil {
-		return nil, responses.NewInternalServerError(err.Error())
+		if (start == 0 || !isWordChar(s[start-1])) && (end == len(s) || !isWordChar(s[end])) {
+			return true
+		}
+		i = start + 1
 	}
+}
 
-	req.Header.Set("Authorization", "Bearer "+jwtToken)
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, responses.NewInternalServerError(err.Error())
+// checkPatient makes sure the patient is registered in the Patient service.
+func (s *medicalRecordService) checkPatient(ctx context.Context, identityNumber int64, jwtToken string) responses.CustomError {
+	patients, err := s.patientClient.WithToken(jwtToken).GetPatients(ctx, patient.GetPatientsQuery{IdentityNumber: identityNumber})
+	if err != nil && api.StatusCode(err) != http.StatusNotFound {
+		return downstreamError("patient", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, responses.NewNotFoundError("nurse with nurse_id is not exist")
-		}
-		log.Println(resp.StatusCode)
-		return nil, responses.NewInternalServerError("failed to consume get user endpoint")
+	if len(patients) == 0 {
+		return responses.NewNotFoundError("patient with identity_number is not exist").WithCode(responses.CodePatientNotFound)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, responses.NewInternalServerError(err.Error())
+	return responses.CustomError{}
+}
+
+func (s *medicalRecordService) GetNurseDetail(ctx context.Context, nurseId string, jwtToken string) (*nurse.User, responses.CustomError) {
+	users, err := s.nurseClient.WithToken(jwtToken).GetUsers(ctx, nurse.GetUsersQuery{UserId: nurseId})
+	if err != nil && api.StatusCode(err) != http.StatusNotFound {
+		return nil, downstreamError("nurse", err)
 	}
 
-	var nurse models.NurseResponse
-	err = json.Unmarshal(body, &nurse)
-	if err != nil {
-		return nil, responses.NewInternalServerError(err.Error())
+	if len(users) == 0 {
+		return nil, responses.NewNotFoundError("nurse with nurse_id is not exist").WithCode(responses.CodeNurseNotFound)
 	}
 
-	// if nurse.Message == "success" {
-	// 	for _, nurse := range nurse.Data {
-	// 		fmt.Printf("User ID: %s, NIP: %s, Name: %s\n", nurse.UserID, nurse.NIP, nurse.Name)
-	// 	}
+	return &users[0], responses.CustomError{}
+}
+
+// downstreamError reports a failed call to another service, a service behind
+// an open circuit breaker or that can't be reached is reported unavailable.
+func downstreamError(service string, err error) responses.CustomError {
+	slog.Warn("failed to call a service", "service", service, "error", err)
 
-	// 	// If you only want the first (newest) nurse's details:
-	// 	if len(nurse.Data) > 0 {
-	// 		firstNurse := nurse.Data[0]
-	// 		fmt.Printf("Newest Nurse - User ID: %s, NIP: %s, Name: %s\n", firstNurse.UserID, firstNurse.NIP, firstNurse.Name)
-	// 	}
-	// } else {
-	// 	log.Println("Failed to fetch nurse details")
-	// }
+	// the service answered, but not with what was asked
+	if api.StatusCode(err) != 0 || errors.Is(err, api.ErrInvalidResponse) {
+		return responses.NewInternalServerError(fmt.Sprintf("failed to consume the %s service", service))
+	}
+
+	if errors.Is(err, httpclient.ErrCircuitOpen) {
+		return responses.NewServiceUnavailableError(fmt.Sprintf("%s service is unavailable, try again later", service))
+	}
 
-	return nurse.Data, responses.CustomError{}
+	return responses.NewServiceUnavailableError(fmt.Sprintf("failed to reach the %s service", service))
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+type MedicationService interface {
+	SearchDrugs(ctx context.Context, GetDrugQueries models.GetDrugQueries) ([]models.Drug, responses.CustomError)
+	GetActiveMedications(ctx context.Context, identityNumber int64, requester models.RecordRequester) ([]models.MedicationOrderResponse, responses.CustomError)
+}
+
+type medicationService struct {
+	repo           repositories.MedicationRepositories
+	recordRepo     repositories.MedicalRecordRepositories
+	breakGlassRepo repositories.BreakGlassRepositories
+	accessLogRepo  repositories.AccessLogRepositories
+}
+
+func NewMedicationService(repo repositories.MedicationRepositories, recordRepo repositories.MedicalRecordRepositories, breakGlassRepo repositories.BreakGlassRepositories, accessLogRepo repositories.AccessLogRepositories) MedicationService {
+	return &medicationService{repo, recordRepo, breakGlassRepo, accessLogRepo}
+}
+
+func (s *medicationService) SearchDrugs(ctx context.Context, GetDrugQueries models.GetDrugQueries) ([]models.Drug, responses.CustomError) {
+	drugs, err := s.repo.SearchDrugs(ctx, GetDrugQueries)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get drugs : %+v", err.Error()))
+	}
+
+	return drugs, responses.CustomError{}
+}
+
+func (s *medicationService) GetActiveMedications(ctx context.Context, identityNumber int64, requester models.RecordRequester) ([]models.MedicationOrderResponse, responses.CustomError) {
+	if _, custErr := checkRecordAccess(ctx, s.recordRepo, requester, &identityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	orders, err := s.repo.GetActiveMedications(ctx, identityNumber, time.Now())
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get active medications : %+v", err.Error()))
+	}
+
+	reads := map[int64][]string{identityNumber: {}}
+	for _, order := range orders {
+		reads[identityNumber] = append(reads[identityNumber], order.RecordId)
+	}
+
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeMedications, reads); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	return orders, responses.CustomError{}
+}
+
+// checkMedicationOrders validates the orders against the drug catalog: the
+// drug has to exist and the dose unit and route have to be allowed for it.
+// Orders without startAt start now.
+func checkMedicationOrders(ctx context.Context, repo repositories.MedicationRepositories, payloads []models.MedicationOrderPayload, now time.Time) ([]models.MedicationOrder, responses.CustomError) {
+	if len(payloads) == 0 {
+		return nil, responses.CustomError{}
+	}
+
+	codes := make([]string, 0, len(payloads))
+	for _, payload := range payloads {
+		codes = append(codes, strings.ToUpper(strings.TrimSpace(payload.DrugCode)))
+	}
+
+	drugs, err := repo.GetDrugs(ctx, codes)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get drug catalog : %+v", err.Error()))
+	}
+
+	orders := make([]models.MedicationOrder, 0, len(payloads))
+	for i, payload := range payloads {
+		drug, ok := drugs[codes[i]]
+		if !ok {
+			return nil, responses.NewBadRequestError(fmt.Sprintf("medicationOrders[%d]: drug %s is not in the catalog", i, payload.DrugCode)).WithCode(responses.CodeInvalidMedicationOrder)
+		}
+
+		doseUnit := strings.ToLower(payload.DoseUnit)
+		if !contains(drug.Units, doseUnit) {
+			return nil, responses.NewBadRequestError(fmt.Sprintf("medicationOrders[%d]: %s can't be dosed in %s, allowed units are %s", i, drug.Name, payload.DoseUnit, strings.Join(drug.Units, ", "))).WithCode(responses.CodeInvalidMedicationOrder)
+		}
+
+		route := strings.ToLower(payload.Route)
+		if !contains(drug.Routes, route) {
+			return nil, responses.NewBadRequestError(fmt.Sprintf("medicationOrders[%d]: %s can't be given by %s route, allowed routes are %s", i, drug.Name, payload.Route, strings.Join(drug.Routes, ", "))).WithCode(responses.CodeInvalidMedicationOrder)
+		}
+
+		order := models.MedicationOrder{
+			DrugCode:  drug.Code,
+			DrugName:  drug.Name,
+			Dose:      *payload.Dose,
+			DoseUnit:  doseUnit,
+			Route:     route,
+			Frequency: payload.Frequency,
+			StartAt:   now,
+		}
+
+		if payload.StartAt != "" {
+			startAt, err := time.Parse(time.RFC3339Nano, payload.StartAt)
+			if err != nil {
+				return nil, responses.NewBadRequestError(fmt.Sprintf("medicationOrders[%d]: startAt is not in valid format", i)).WithCode(responses.CodeInvalidMedicationOrder)
+			}
+			order.StartAt = startAt
+		}
+
+		if payload.StopAt != "" {
+			stopAt, err := time.Parse(time.RFC3339Nano, payload.StopAt)
+			if err != nil {
+				return nil, responses.NewBadRequestError(fmt.Sprintf("medicationOrders[%d]: stopAt is not in valid format", i)).WithCode(responses.CodeInvalidMedicationOrder)
+			}
+			if !stopAt.After(order.StartAt) {
+				return nil, responses.NewBadRequestError(fmt.Sprintf("medicationOrders[%d]: stopAt must be after startAt", i)).WithCode(responses.CodeInvalidMedicationOrder)
+			}
+			order.StopAt = &stopAt
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, responses.CustomError{}
+}
+
+// summarizeMedicationOrders renders the orders as text for the medications
+// column, e.g. "Paracetamol 500 mg oral tid; Omeprazole 20 mg oral qd".
+func summarizeMedicationOrders(orders []models.MedicationOrder) string {
+	lines := make([]string, 0, len(orders))
+	for _, order := range orders {
+		dose := strconv.FormatFloat(order.Dose, 'f', -1, 64)
+		lines = append(lines, fmt.Sprintf("%s %s %s %s %s", order.DrugName, dose, order.DoseUnit, order.Route, order.Frequency))
+	}
+
+	return strings.Join(lines, "; ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type VitalSignService interface {
+	GetVitalSignSeries(ctx context.Context, GetVitalSignQueries models.GetVitalSignQueries, requester models.RecordRequester) ([]models.VitalSignSeries, responses.CustomError)
+}
+
+type vitalSignService struct {
+	repo           repositories.MedicalRecordRepositories
+	breakGlassRepo repositories.BreakGlassRepositories
+	accessLogRepo  repositories.AccessLogRepositories
+}
+
+func NewVitalSignService(repo repositories.MedicalRecordRepositories, breakGlassRepo repositories.BreakGlassRepositories, accessLogRepo repositories.AccessLogRepositories) VitalSignService {
+	return &vitalSignService{repo, breakGlassRepo, accessLogRepo}
+}
+
+func (s *vitalSignService) GetVitalSignSeries(ctx context.Context, GetVitalSignQueries models.GetVitalSignQueries, requester models.RecordRequester) ([]models.VitalSignSeries, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&GetVitalSignQueries); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, custErr := checkRecordAccess(ctx, s.repo, requester, GetVitalSignQueries.IdentityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	vitals, err := s.repo.GetVitalSigns(ctx, GetVitalSignQueries)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get vital signs : %+v", err.Error()))
+	}
+
+	reads := map[int64][]string{*GetVitalSignQueries.IdentityNumber: {}}
+	for _, vital := range vitals {
+		reads[*GetVitalSignQueries.IdentityNumber] = append(reads[*GetVitalSignQueries.IdentityNumber], vital.RecordId)
+	}
+
+	if custErr := logRecordReads(ctx, s.accessLogRepo, s.breakGlassRepo, requester, models.AccessTypeVitalSigns, reads); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	// vitals are ordered by type then time, so every series is a single run
+	series := []models.VitalSignSeries{}
+	for _, vital := range vitals {
+		if len(series) == 0 || series[len(series)-1].Type != vital.Type {
+			series = append(series, models.VitalSignSeries{Type: vital.Type, Unit: vital.Unit, Points: []models.VitalSignPoint{}})
+		}
+
+		current := &series[len(series)-1]
+		current.Points = append(current.Points, models.VitalSignPoint{
+			Value:      vital.Value,
+			Systolic:   vital.Systolic,
+			Diastolic:  vital.Diastolic,
+			MeasuredAt: vital.MeasuredAt,
+		})
+	}
+
+	return series, responses.CustomError{}
+}
+
+type vitalSignSpec struct {
+	unit string
+	min  float64
+	max  float64
+	// converts a value in an accepted unit (lower case) to the canonical unit
+	units map[string]func(float64) float64
+}
+
+func same(v float64) float64 { return v }
+
+// vitalSignSpecs holds the canonical unit and the range of values that are
+// physiologically possible, anything outside is treated as a typo.
+var vitalSignSpecs = map[string]vitalSignSpec{
+	models.VitalTemperature: {unit: "C", min: 25, max: 45, units: map[string]func(float64) float64{
+		"c": same, "celsius": same,
+		"f": func(v float64) float64 { return (v - 32) * 5 / 9 }, "fahrenheit": func(v float64) float64 { return (v - 32) * 5 / 9 },
+	}},
+	models.VitalBloodPressure: {unit: "mmHg", min: 40, max: 300, units: map[string]func(float64) float64{
+		"mmhg": same,
+		"kpa":  func(v float64) float64 { return v * 7.50062 },
+	}},
+	models.VitalPulse: {unit: "bpm", min: 20, max: 300, units: map[string]func(float64) float64{
+		"bpm": same, "/min": same,
+	}},
+	models.VitalRespiratoryRate: {unit: "breaths/min", min: 4, max: 80, units: map[string]func(float64) float64{
+		"breaths/min": same, "/min": same,
+	}},
+	models.VitalSpO2: {unit: "%", min: 50, max: 100, units: map[string]func(float64) float64{
+		"%": same,
+	}},
+	models.VitalWeight: {unit: "kg", min: 0.3, max: 500, units: map[string]func(float64) float64{
+		"kg": same,
+		"g":  func(v float64) float64 { return v / 1000 },
+		"lb": func(v float64) float64 { return v * 0.45359237 },
+	}},
+	models.VitalHeight: {unit: "cm", min: 20, max: 280, units: map[string]func(float64) float64{
+		"cm": same,
+		"m":  func(v float64) float64 { return v * 100 },
+		"in": func(v float64) float64 { return v * 2.54 },
+	}},
+	models.VitalPainScore: {unit: "0-10", min: 0, max: 10, units: map[string]func(float64) float64{
+		"0-10": same,
+	}},
+}
+
+// diastolic pressure has its own range, systolic uses the blood pressure spec
+const minDiastolic, maxDiastolic = 20, 200
+
+// normalizeVitalSigns converts the entries to their canonical unit and checks
+// them against the physiological ranges. Entries without measuredAt are
+// measured at now.
+func normalizeVitalSigns(payloads []models.VitalSignPayload, now time.Time) ([]models.VitalSign, error) {
+	vitals := make([]models.VitalSign, 0, len(payloads))
+
+	for i, payload := range payloads {
+		spec := vitalSignSpecs[payload.Type]
+
+		unit := strings.ToLower(strings.TrimSpace(payload.Unit))
+		if unit == "" {
+			unit = strings.ToLower(spec.unit)
+		}
+
+		convert, ok := spec.units[unit]
+		if !ok {
+			return nil, fmt.Errorf("vitals[%d]: unit %q is not supported for %s", i, payload.Unit, payload.Type)
+		}
+
+		vital := models.VitalSign{Type: payload.Type, Unit: spec.unit, MeasuredAt: now}
+
+		if payload.MeasuredAt != "" {
+			measuredAt, err := time.Parse(time.RFC3339Nano, payload.MeasuredAt)
+			if err != nil {
+				return nil, fmt.Errorf("vitals[%d]: measuredAt is not in valid format", i)
+			}
+			if measuredAt.After(now) {
+				return nil, fmt.Errorf("vitals[%d]: measuredAt can't be in the future", i)
+			}
+			vital.MeasuredAt = measuredAt
+		}
+
+		if payload.Type == models.VitalBloodPressure {
+			systolic := round2(convert(*payload.Systolic))
+			diastolic := round2(convert(*payload.Diastolic))
+
+			if systolic < spec.min || systolic > spec.max {
+				return nil, fmt.Errorf("vitals[%d]: systolic pressure of %v %s is outside the physiological range %v-%v %s", i, systolic, spec.unit, spec.min, spec.max, spec.unit)
+			}
+			if diastolic < minDiastolic || diastolic > maxDiastolic {
+				return nil, fmt.Errorf("vitals[%d]: diastolic pressure of %v %s is outside the physiological range %v-%v %s", i, diastolic, spec.unit, minDiastolic, maxDiastolic, spec.unit)
+			}
+			if diastolic >= systolic {
+				return nil, fmt.Errorf("vitals[%d]: diastolic pressure must be lower than systolic pressure", i)
+			}
+
+			vital.Value = systolic
+			vital.Diastolic = &diastolic
+			vitals = append(vitals, vital)
+			continue
+		}
+
+		value := round2(convert(*payload.Value))
+		if value < spec.min || value > spec.max {
+			return nil, fmt.Errorf("vitals[%d]: %s of %v %s is outside the physiological range %v-%v %s", i, payload.Type, value, spec.unit, spec.min, spec.max, spec.unit)
+		}
+		if payload.Type == models.VitalPainScore && value != math.Trunc(value) {
+			return nil, fmt.Errorf("vitals[%d]: pain score must be a whole number", i)
+		}
+
+		vital.Value = value
+		vitals = append(vitals, vital)
+	}
+
+	return vitals, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -4,7 +4,6 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 	"strings"
@@ -37,7 +36,6 @@ func GenerateNewTokens(id string, role string) (*Tokens, error) {
 
 func generateNewAccessToken(id string, role string) (string, error) {
 	secret := os.Getenv("JWT_SECRET_KEY")
-	log.Println(secret)
 
 	minutesCount, _ := strconv.Atoi(os.Getenv("JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT"))
 
@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestDatetimeRule(t *testing.T) {
+	type payload struct {
+		At   string `validate:"omitempty,datetime"`
+		Date string `validate:"omitempty,datetime=2006-01-02"`
+	}
+
+	tests := []struct {
+		value string
+		date  string
+		valid bool
+	}{
+		{"2024-05-01T08:00:00Z", "", true},
+		{"2024-05-01T08:00:00.123+07:00", "", true},
+		{"", "2024-05-01", true},
+		{"2024-05-01T08:00:00", "", false},
+		{"2024-05-01", "", false},
+		{"2024-13-01T08:00:00Z", "", false},
+		{"", "2024-05-01T08:00:00Z", false},
+	}
+
+	validate := NewValidator()
+	for _, tt := range tests {
+		err := validate.Struct(payload{At: tt.value, Date: tt.date})
+		if (err == nil) != tt.valid {
+			t.Errorf("%q %q: got error %v, want valid %t", tt.value, tt.date, err, tt.valid)
+		}
+	}
+}
@@ -1,15 +1,16 @@
 package controller
 
 import (
-	"context"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
 	"github.com/ravenocx/hospital-mgt/responses"
 	"github.com/ravenocx/hospital-mgt/service"
+	"github.com/ravenocx/hospital-mgt/utils"
 )
 
 type UserController struct {
@@ -30,7 +31,7 @@ type registerResponse struct {
 func (c *UserController) NurseRegister(ctx *fiber.Ctx) error {
 	var newNurse models.NurseRegistrationPayload
 	if err := ctx.BodyParser(&newNurse); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
 	imgFile, err := ctx.FormFile("identityCardScanImg")
@@ -40,12 +41,10 @@ func (c *UserController) NurseRegister(ctx *fiber.Ctx) error {
 
 	newNurse.IdentityCardScanImg = imgFile
 
-	context := context.Background()
+	context := ctx.UserContext()
 	userId, custErr := c.service.NurseRegister(context, newNurse)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
-		})
+		return custErr
 	}
 
 	responseData := registerResponse{
@@ -64,15 +63,13 @@ func (c *UserController) NurseUpdate(ctx *fiber.Ctx) error {
 	id := ctx.Params("userId")
 	var updatePayload models.NurseUpdatePayload
 	if err := ctx.BodyParser(&updatePayload); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
-	context := context.Background()
+	context := ctx.UserContext()
 	err := c.service.UpdateNurse(context, id, updatePayload)
 	if (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -84,13 +81,11 @@ func (c *UserController) NurseUpdate(ctx *fiber.Ctx) error {
 func (c *UserController) NurseDelete(ctx *fiber.Ctx) error {
 	id := ctx.Params("userId")
 
-	context := context.Background()
+	context := ctx.UserContext()
 	err := c.service.DeleteNurse(context, id)
 
 	if (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -103,22 +98,18 @@ func (c *UserController) NurseAccess(ctx *fiber.Ctx) error {
 	id := ctx.Params("userId")
 	var accessPayload models.NurseAccessPayload
 	if err := ctx.BodyParser(&accessPayload); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
-	context := context.Background()
+	context := ctx.UserContext()
 	user, err := c.service.AccessNurse(context, id, accessPayload)
 
 	if (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
-	if err = c.service.PublishToRabbitmq(user); (err != responses.CustomError{}) {
-		return ctx.Status(err.Status()).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+	if err = c.service.PublishToRabbitmq(context, user); (err != responses.CustomError{}) {
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -149,6 +140,11 @@ func (c *UserController) GetUser(ctx *fiber.Ctx) error {
 
 	role := ctx.Query("role")
 	createdAt := ctx.Query("createdAt")
+	if !repositories.UserSort.Valid("createdAt", createdAt) {
+		return responses.NewValidationError("query params doesn't meet requirement", []responses.FieldError{
+			{Field: "createdAt", Rule: "oneof", Detail: "must be one of asc desc"},
+		})
+	}
 
 	userQuery := models.GetUserQueries{
 		UserId:    userId,
@@ -160,14 +156,10 @@ func (c *UserController) GetUser(ctx *fiber.Ctx) error {
 		CreatedAt: createdAt,
 	}
 
-	log.Println(userQuery.UserId)
-
-	context := context.Background()
+	context := ctx.UserContext()
 	resp, custErr := c.service.GetUser(context, userQuery)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
-		})
+		return custErr
 	}
 
 	if len(resp) == 0 {
@@ -182,3 +174,79 @@ func (c *UserController) GetUser(ctx *fiber.Ctx) error {
 		"data":    resp,
 	})
 }
+
+func (c *UserController) GetNurseWards(ctx *fiber.Ctx) error {
+	id := ctx.Params("userId")
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetNurseWards(context, id)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *UserController) NurseWardUpdate(ctx *fiber.Ctx) error {
+	id := ctx.Params("userId")
+	var wardPayload models.NurseWardPayload
+	if err := ctx.BodyParser(&wardPayload); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.UpdateNurseWards(context, id, wardPayload, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success updated nurse wards",
+		"data":    resp,
+	})
+}
+
+func (c *UserController) GetIdentityCard(ctx *fiber.Ctx) error {
+	id := ctx.Params("userId")
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	access := models.IdentityCardAccess{
+		UserId:    claims.UserID.String(),
+		Role:      claims.Role,
+		ClientIP:  ctx.IP(),
+		Stream:    ctx.Query("mode") == "stream",
+		Thumbnail: ctx.Query("variant") == "thumbnail",
+	}
+
+	context := ctx.UserContext()
+	card, custErr := c.service.GetIdentityCard(context, id, access)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	if card.Body != nil {
+		ctx.Set(fiber.HeaderContentType, card.ContentType)
+		return ctx.Status(fiber.StatusOK).SendStream(card.Body)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    card,
+	})
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_identity_card_access_logs CASCADE;
+
+DROP INDEX IF EXISTS idx_user_identity_card_access_logs_user_id CASCADE;
@@ -0,0 +1,11 @@
+CREATE TABLE user_identity_card_access_logs (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    accessed_by_user_id UUID NOT NULL,
+    accessed_by_role VARCHAR(20) NOT NULL,
+    access_mode VARCHAR(20) NOT NULL, -- signed_url, stream, legacy_url
+    client_ip VARCHAR(45) NOT NULL,
+    accessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_identity_card_access_logs_user_id ON user_identity_card_access_logs(user_id, accessed_at);
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN IF EXISTS identity_card_thumbnail_img;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN identity_card_thumbnail_img TEXT;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS nurse_ward_assignments;
@@ -0,0 +1,9 @@
+CREATE TABLE nurse_ward_assignments (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ward VARCHAR(50) NOT NULL, -- same name as encounters.ward, compared case-insensitively
+    assigned_by_user_id UUID NOT NULL,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, ward)
+);
+
+CREATE INDEX idx_nurse_ward_assignments_ward ON nurse_ward_assignments(lower(ward));
@@ -1,10 +1,13 @@
 package main
 
 import (
-	"log"
+	"context"
 
 	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/db"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
+	"github.com/ravenocx/hospital-mgt/sdk/tracing"
 	"github.com/ravenocx/hospital-mgt/server"
 )
 
@@ -12,16 +15,38 @@ func main() {
 	config, err := config.LoadConfig()
 	
 	if err != nil {
-		log.Fatalf("failed to load config : %+v", err)
+		logging.Fatal("failed to load config", "error", err)
+	}
+
+	if err := logging.Setup("nurse-management", config.LogLevel); err != nil {
+		logging.Fatal("failed to set up logging", "error", err)
+	}
+
+	if err := tracing.Init(context.Background(), "nurse-management", config.OtelExporterEndpoint); err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
 	}
 
 	db, err := db.OpenConnection(config)
 	if err != nil {
-		log.Fatalf("failed to connect to db: %v", err)
+		logging.Fatal("failed to connect to db", "error", err)
 	}
 	defer db.Close()
 
-	s := server.NewServer(db, config)
+	store, err := storage.New(storage.Config{
+		Driver:      config.StorageDriver,
+		LocalDir:    config.StorageLocalDir,
+		S3Endpoint:  config.S3Endpoint,
+		S3AccessKey: config.S3AccessKey,
+		S3SecretKey: config.S3SecretKey,
+		S3Bucket:    config.S3Bucket,
+		S3Region:    config.S3Region,
+		S3UseSSL:    config.S3UseSSL,
+	})
+	if err != nil {
+		logging.Fatal("failed to open blob storage", "error", err)
+	}
+
+	s := server.NewServer(db, store, config)
 
 	s.RegisterRoute()
 
@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"os"
 	"time"
 
@@ -21,23 +22,42 @@ func JWTProtected() func(*fiber.Ctx) error {
 	return jwtMiddleware.New(config)
 }
 
+func UserAuth() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		claims, err := utils.ExtractTokenMetadata(c)
+		if err != nil {
+			slog.WarnContext(c.UserContext(), "failed to read the token claims", "error", err)
+			return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+		}
+
+		expires := claims.Expires
+		now := time.Now().Unix()
+
+		if now > expires {
+			return responses.NewUnauthorizedError("token expired").WithCode(responses.CodeTokenExpired)
+		}
+
+		return c.Next()
+	}
+}
+
 func AdminAuth() func(*fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		claims, err := utils.ExtractTokenMetadata(c)
 		if err != nil {
-			log.Println(err)
-			return UnauthorizedResponse(c, "token not found")
+			slog.WarnContext(c.UserContext(), "failed to read the token claims", "error", err)
+			return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
 		}
 
 		expires := claims.Expires
 		now := time.Now().Unix()
 
 		if now > expires {
-			return UnauthorizedResponse(c, "token expired")
+			return responses.NewUnauthorizedError("token expired").WithCode(responses.CodeTokenExpired)
 		}
 
 		if claims.Role != "admin" {
-			return UnauthorizedResponse(c, "user is not admin")
+			return responses.NewUnauthorizedError("user is not admin").WithCode(responses.CodeAdminOnly)
 		}
 
 		return c.Next()
@@ -45,20 +65,9 @@ func AdminAuth() func(*fiber.Ctx) error {
 }
 
 func jwtError(c *fiber.Ctx, err error) error {
-	if err.Error() == "Missing or malformed JWT" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": true,
-			"msg":   err.Error(),
-		})
+	if errors.Is(err, jwtMiddleware.ErrJWTMissingOrMalformed) {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeMissingToken)
 	}
 
-	return UnauthorizedResponse(c, err.Error())
-}
-
-func UnauthorizedResponse(ctx *fiber.Ctx, message string) error {
-	err := responses.NewUnauthorizedError(message)
-	
-	return ctx.Status(err.Status()).JSON(fiber.Map{
-		"message" : err.Error(),
-	})
+	return responses.NewUnauthorizedError(err.Error()).WithCode(responses.CodeInvalidToken)
 }
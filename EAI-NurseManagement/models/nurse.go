@@ -1,21 +1,23 @@
 package models
 
 import (
+	"io"
 	"mime/multipart"
 	"time"
 )
 
 type User struct {
-	ID                  string     `db:"id" json:"id" validate:"required,uuid"`
-	Nip                 string     `db:"nip" json:"nip" validate:"required"`
-	Name                string     `db:"name" json:"name" validate:"required,min=5,max=50"`
-	Role                string     `db:"role" json:"role" validate:"required"`
-	Password            string     `db:"password" json:"password,omitempty" validate:"required,min=5,max=33"`
-	IdentityCardScanImg string     `db:"identity_card_scan_img" json:"identityCardScanImg" validate:"required,img_url"`
-	Access              bool       `db:"access" json:"access"`
-	RefreshToken        string     `db:"refresh_token"`
-	CreatedAt           time.Time  `db:"created_at" json:"createdAt"`
-	UpdatedAt           *time.Time `db:"updated_at" json:"-"`
+	ID                       string     `db:"id" json:"id" validate:"required,uuid"`
+	Nip                      string     `db:"nip" json:"nip" validate:"required"`
+	Name                     string     `db:"name" json:"name" validate:"required,min=5,max=50"`
+	Role                     string     `db:"role" json:"role" validate:"required"`
+	Password                 string     `db:"password" json:"password,omitempty" validate:"required,min=5,max=33"`
+	IdentityCardScanImg      string     `db:"identity_card_scan_img" json:"identityCardScanImg" validate:"required,img_url"`
+	IdentityCardThumbnailImg string     `db:"identity_card_thumbnail_img" json:"-"`
+	Access                   bool       `db:"access" json:"access"`
+	RefreshToken             string     `db:"refresh_token"`
+	CreatedAt                time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt                *time.Time `db:"updated_at" json:"-"`
 }
 
 type NurseRegistrationPayload struct {
@@ -23,6 +25,7 @@ type NurseRegistrationPayload struct {
 	Name                      string                `json:"name,omitempty" form:"name" validate:"required,min=5,max=50"`
 	IdentityCardScanImg       *multipart.FileHeader `json:"identityCardScanImg" form:"identityCardScanImg" validate:"required,img_file"`
 	IdentityCardScanImgString string                `db:"identity_card_scan_img"`
+	IdentityCardThumbnailImg  string                `db:"identity_card_thumbnail_img"`
 }
 
 type NurseUpdatePayload struct {
@@ -56,3 +59,38 @@ type Credential struct {
 	Nip      string `json:"nip" form:"nip" validate:"required,nip_admin"`
 	Password string `json:"password" form:"password" validate:"required,min=5,max=33"`
 }
+
+// NurseWardPayload replaces every ward assignment of a nurse, an empty list
+// removes them all.
+type NurseWardPayload struct {
+	Wards []string `json:"wards" form:"wards" validate:"max=20,dive,required,max=50"`
+}
+
+type NurseWardResponse struct {
+	UserId string   `json:"userId"`
+	Wards  []string `json:"wards"`
+}
+
+type IdentityCardAccess struct {
+	UserId    string
+	Role      string
+	ClientIP  string
+	Stream    bool
+	Thumbnail bool
+}
+
+type IdentityCardAccessLog struct {
+	UserId           string
+	AccessedByUserId string
+	Role             string
+	AccessMode       string
+	ClientIP         string
+}
+
+// IdentityCard is either a signed URL to the scan or its content, never both.
+type IdentityCard struct {
+	URL         string        `json:"url"`
+	ExpiresAt   *string       `json:"expiresAt"`
+	Body        io.ReadCloser `json:"-"`
+	ContentType string        `json:"-"`
+}
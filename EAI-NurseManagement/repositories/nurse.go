@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
 )
 
 type NurseRepositories interface {
@@ -21,6 +21,9 @@ type NurseRepositories interface {
 	UpdateAccessNurse(ctx context.Context, nurseId string, passwordHash string) (pgconn.CommandTag, error)
 	DeleteNurse(ctx context.Context, userId string) (pgconn.CommandTag, error)
 	GetUsers(ctx context.Context, filter models.GetUserQueries) ([]models.GetUserResponse, error)
+	CreateIdentityCardAccessLog(ctx context.Context, accessLog models.IdentityCardAccessLog) error
+	GetWardAssignments(ctx context.Context, userId string) ([]string, error)
+	ReplaceWardAssignments(ctx context.Context, userId string, wards []string, assignedBy string) error
 	BeginTx(ctx context.Context) (pgx.Tx, error)
 }
 
@@ -49,9 +52,9 @@ func (r *nurseRepositories) GetUser(ctx context.Context, nip string) (*models.Us
 func (r *nurseRepositories) CreateNurseUser(ctx context.Context, user *models.NurseRegistrationPayload) (string, error) {
 	var id string
 	role := CheckRoleForRegister(strconv.FormatInt(user.Nip, 10))
-	statement := "INSERT INTO users (name, nip, role, access, identity_card_scan_img) VALUES ($1, $2, $3, false, $4) RETURNING id"
+	statement := "INSERT INTO users (name, nip, role, access, identity_card_scan_img, identity_card_thumbnail_img) VALUES ($1, $2, $3, false, $4, $5) RETURNING id"
 
-	row := r.db.QueryRow(ctx, statement, user.Name, strconv.FormatInt(user.Nip, 10), role, user.IdentityCardScanImgString)
+	row := r.db.QueryRow(ctx, statement, user.Name, strconv.FormatInt(user.Nip, 10), role, user.IdentityCardScanImgString, user.IdentityCardThumbnailImg)
 	if err := row.Scan(&id); err != nil {
 		return "", err
 	}
@@ -61,10 +64,10 @@ func (r *nurseRepositories) CreateNurseUser(ctx context.Context, user *models.Nu
 
 func (r *nurseRepositories) GetUserNipById(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
-	query := "SELECT id,nip,name,identity_card_scan_img FROM users WHERE id = $1"
+	query := "SELECT id,nip,name,identity_card_scan_img,COALESCE(identity_card_thumbnail_img, '') FROM users WHERE id = $1"
 
 	row := r.db.QueryRow(ctx, query, id)
-	err := row.Scan(&user.ID, &user.Nip, &user.Name, &user.IdentityCardScanImg)
+	err := row.Scan(&user.ID, &user.Nip, &user.Name, &user.IdentityCardScanImg, &user.IdentityCardThumbnailImg)
 	if err != nil {
 		return nil, err
 	}
@@ -100,25 +103,16 @@ func (r *nurseRepositories) GetUsers(ctx context.Context, filter models.GetUserQ
 	var createdAt time.Time
 	query := "SELECT id, nip, name,access, created_at FROM users"
 
-	query += getUserConstructWhereQuery(filter)
+	qb := getUserConstructWhereQuery(filter)
+	query += qb.WhereClause()
+	query += UserSort.Clause("createdAt", filter.CreatedAt)
+	query += qb.Limit(filter.Limit, filter.Offset)
 
-	if filter.CreatedAt != "" {
-		if filter.CreatedAt == "asc" {
-			query += " ORDER BY created_at ASC"
-		} else if filter.CreatedAt == "desc" {
-			query += " ORDER BY created_at DESC"
-		}
-	} else {
-		query += " ORDER BY created_at DESC"
-	}
-
-	query += " limit $1 offset $2"
-	log.Printf("Get users query : %+v", query)
-
-	rows, err := r.db.Query(ctx, query, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var nip string
 	for rows.Next() {
@@ -138,6 +132,58 @@ func (r *nurseRepositories) GetUsers(ctx context.Context, filter models.GetUserQ
 	return users, nil
 }
 
+func (r *nurseRepositories) CreateIdentityCardAccessLog(ctx context.Context, accessLog models.IdentityCardAccessLog) error {
+	statement := "INSERT INTO user_identity_card_access_logs (user_id, accessed_by_user_id, accessed_by_role, access_mode, client_ip) VALUES ($1, $2, $3, $4, $5)"
+
+	_, err := r.db.Exec(ctx, statement, accessLog.UserId, accessLog.AccessedByUserId, accessLog.Role, accessLog.AccessMode, accessLog.ClientIP)
+
+	return err
+}
+
+func (r *nurseRepositories) GetWardAssignments(ctx context.Context, userId string) ([]string, error) {
+	wards := []string{}
+	query := "SELECT ward FROM nurse_ward_assignments WHERE user_id = $1 ORDER BY ward"
+
+	rows, err := r.db.Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ward string
+		if err := rows.Scan(&ward); err != nil {
+			return nil, err
+		}
+		wards = append(wards, ward)
+	}
+
+	return wards, rows.Err()
+}
+
+// ReplaceWardAssignments swaps the wards of the user for the given ones in a
+// single transaction.
+func (r *nurseRepositories) ReplaceWardAssignments(ctx context.Context, userId string, wards []string, assignedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM nurse_ward_assignments WHERE user_id = $1", userId); err != nil {
+		return err
+	}
+
+	for _, ward := range wards {
+		_, err := tx.Exec(ctx, "INSERT INTO nurse_ward_assignments (user_id, ward, assigned_by_user_id) VALUES ($1, $2, $3)", userId, ward, assignedBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (r *nurseRepositories) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return r.db.Begin(ctx)
 }
@@ -151,32 +197,32 @@ func CheckRoleForRegister(nip string) string {
 	return ""
 }
 
-func getUserConstructWhereQuery(filter models.GetUserQueries) string {
-	whereSQL := []string{}
+// UserSort whitelists what the list is sorted on, the handler rejects anything
+// else.
+var UserSort = querybuilder.Sort{
+	Columns:          map[string]string{"createdAt": "created_at"},
+	DefaultColumn:    "createdAt",
+	DefaultDirection: "desc",
+}
+
+func getUserConstructWhereQuery(filter models.GetUserQueries) *querybuilder.Builder {
+	qb := querybuilder.New()
 
 	if filter.UserId != "" {
-		whereSQL = append(whereSQL, " id = '"+filter.UserId+"'")
+		qb.Equal("id", filter.UserId)
 	}
 
 	if filter.Name != "" {
-		whereSQL = append(whereSQL, " name ILIKE '%"+filter.Name+"%'")
+		qb.Contains("name", filter.Name)
 	}
 
 	if filter.Nip != "" {
-		whereSQL = append(whereSQL, " nip ILIKE '"+filter.Nip+"%'")
+		qb.HasPrefix("nip", filter.Nip)
 	}
 
-	if filter.Role == "admin" {
-		whereSQL = append(whereSQL, " role = '"+filter.Role+"'")
+	if filter.Role == "admin" || filter.Role == "nurse" {
+		qb.Equal("role", filter.Role)
 	}
 
-	if filter.Role == "nurse" {
-		whereSQL = append(whereSQL, " role = '"+filter.Role+"'")
-	}
-
-	if len(whereSQL) > 0 {
-		return " WHERE " + strings.Join(whereSQL, " AND ")
-	}
-
-	return ""
+	return qb
 }
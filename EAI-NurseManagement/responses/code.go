@@ -0,0 +1,28 @@
+package responses
+
+// The codes are part of the API, clients branch on them. Never rename one,
+// add a new code instead.
+const (
+	CodeBadRequest       = "BAD_REQUEST"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidBody      = "INVALID_BODY"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeMissingToken     = "MISSING_TOKEN"
+	CodeInvalidToken     = "INVALID_TOKEN"
+	CodeTokenExpired     = "TOKEN_EXPIRED"
+	CodeAdminOnly        = "ADMIN_ONLY"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+const (
+	CodeUserNotFound         = "USER_NOT_FOUND"
+	CodeNurseNotFound        = "NURSE_NOT_FOUND"
+	CodeNotANurse            = "NOT_A_NURSE"
+	CodeNipConflict          = "NIP_CONFLICT"
+	CodeInvalidImage         = "INVALID_IMAGE"
+	CodeEmptyWard            = "EMPTY_WARD"
+	CodeIdentityCardNotFound = "IDENTITY_CARD_NOT_FOUND"
+)
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/nurse"
+	"github.com/ravenocx/hospital-mgt/sdk/sdktest"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
+)
+
+// The contract tests run the nurse client of the sdk against the real routes
+// and handlers, on top of a repository returning completely filled rows. A
+// response field renamed or dropped on either side fails them.
+//
+// GrantAccess is left out, the handler publishes to RabbitMQ.
+
+const (
+	testSecret   = "contract-test-secret"
+	testNurseId  = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a004"
+	testAdminId  = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a005"
+	testNurseNip = int64(3031202001001)
+	testScanKey  = "identity-card/scan.png"
+	testTime     = "2024-05-01T08:00:00Z"
+)
+
+// fakeNurseRepo knows a single nurse, it keeps what the handlers wrote so the
+// tests can check the requests were read.
+type fakeNurseRepo struct {
+	created *models.NurseRegistrationPayload
+	updated *models.NurseUpdatePayload
+	wards   []string
+}
+
+func (r *fakeNurseRepo) GetUser(ctx context.Context, nip string) (*models.User, error) {
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeNurseRepo) CreateNurseUser(ctx context.Context, user *models.NurseRegistrationPayload) (string, error) {
+	r.created = user
+	return testNurseId, nil
+}
+
+func (r *fakeNurseRepo) GetUserNipById(ctx context.Context, id string) (*models.User, error) {
+	return &models.User{
+		ID:                       id,
+		Nip:                      "3031202001001",
+		Name:                     "nurse one",
+		Role:                     "nurse",
+		IdentityCardScanImg:      testScanKey,
+		IdentityCardThumbnailImg: testScanKey,
+		Access:                   true,
+		CreatedAt:                time.Now(),
+	}, nil
+}
+
+func (r *fakeNurseRepo) UpdateNurse(ctx context.Context, nurseId string, updatePayload models.NurseUpdatePayload) (pgconn.CommandTag, error) {
+	r.updated = &updatePayload
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (r *fakeNurseRepo) UpdateAccessNurse(ctx context.Context, nurseId string, passwordHash string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (r *fakeNurseRepo) DeleteNurse(ctx context.Context, userId string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("DELETE 1"), nil
+}
+
+func (r *fakeNurseRepo) GetUsers(ctx context.Context, filter models.GetUserQueries) ([]models.GetUserResponse, error) {
+	return []models.GetUserResponse{{
+		UserId:    testNurseId,
+		Nip:       testNurseNip,
+		Name:      "nurse one",
+		Access:    true,
+		CreatedAt: testTime,
+	}}, nil
+}
+
+func (r *fakeNurseRepo) CreateIdentityCardAccessLog(ctx context.Context, accessLog models.IdentityCardAccessLog) error {
+	return nil
+}
+
+func (r *fakeNurseRepo) GetWardAssignments(ctx context.Context, userId string) ([]string, error) {
+	return []string{"ICU", "Maternity"}, nil
+}
+
+func (r *fakeNurseRepo) ReplaceWardAssignments(ctx context.Context, userId string, wards []string, assignedBy string) error {
+	r.wards = wards
+	return nil
+}
+
+func (r *fakeNurseRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return nil, pgx.ErrTxClosed
+}
+
+// signingStore is a local store handing out URLs, like the S3 store does.
+type signingStore struct {
+	*storage.LocalStore
+}
+
+func (s signingStore) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "https://storage.example.com/" + key + "?signature=test", nil
+}
+
+func testScan(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 240, 240))
+	for x := 0; x < 240; x++ {
+		for y := 0; y < 240; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode scan : %+v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func testToken(t *testing.T, userId string, role string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"id":      userId,
+		"role":    role,
+		"expires": time.Now().Add(time.Hour).Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token : %+v", err)
+	}
+
+	return token
+}
+
+// startServer serves the routes on a random port and returns a strict client
+// of it, without a token.
+func startServer(t *testing.T, repo *fakeNurseRepo) *nurse.Client {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	local, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store : %+v", err)
+	}
+	scan := testScan(t)
+	if err := local.Put(context.Background(), testScanKey, bytes.NewReader(scan), int64(len(scan)), "image/png"); err != nil {
+		t.Fatalf("failed to store scan : %+v", err)
+	}
+
+	s := NewServer(nil, signingStore{local}, config.Config{IdentityCardUrlExpiry: 300})
+	s.registerRoutes(repo)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen : %+v", err)
+	}
+	go s.app.Listener(ln)
+	t.Cleanup(func() { s.app.Shutdown() })
+
+	clientConfig := httpclient.DefaultConfig("http://" + ln.Addr().String())
+	clientConfig.MaxRetries = 0
+
+	return nurse.New(api.NewClient(httpclient.New(clientConfig)).Strict())
+}
+
+func TestNurseContract(t *testing.T) {
+	repo := &fakeNurseRepo{}
+	client := startServer(t, repo)
+	admin := client.WithToken(testToken(t, testAdminId, "admin"))
+	ctx := context.Background()
+
+	t.Run("RegisterNurse", func(t *testing.T) {
+		registered, err := admin.RegisterNurse(ctx, nurse.NurseRegistration{
+			Nip:                 testNurseNip,
+			Name:                "nurse one",
+			IdentityCardScanImg: api.File{Name: "scan.png", ContentType: "image/png", Content: testScan(t)},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, registered)
+		sdktest.RequireFilled(t, repo.created)
+	})
+
+	t.Run("GetUsers", func(t *testing.T) {
+		users, err := admin.GetUsers(ctx, nurse.GetUsersQuery{UserId: testNurseId})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, users)
+	})
+
+	t.Run("UpdateNurse", func(t *testing.T) {
+		if err := admin.UpdateNurse(ctx, testNurseId, nurse.NurseUpdate{Nip: testNurseNip, Name: "nurse two"}); err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, repo.updated)
+	})
+
+	t.Run("GetWards", func(t *testing.T) {
+		wards, err := admin.GetWards(ctx, testNurseId)
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, wards)
+	})
+
+	t.Run("UpdateWards", func(t *testing.T) {
+		wards, err := admin.UpdateWards(ctx, testNurseId, []string{"ICU"})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, wards)
+		sdktest.RequireFilled(t, repo.wards)
+	})
+
+	t.Run("GetIdentityCard", func(t *testing.T) {
+		card, err := admin.GetIdentityCard(ctx, testNurseId, nurse.IdentityCardOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, card, "Scan")
+	})
+
+	t.Run("GetIdentityCard stream", func(t *testing.T) {
+		// nurses can stream their own scan
+		own := client.WithToken(testToken(t, testNurseId, "nurse"))
+
+		card, err := own.GetIdentityCard(ctx, testNurseId, nurse.IdentityCardOptions{Stream: true})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+		if card.Scan == nil || !strings.HasPrefix(card.Scan.ContentType, "image/png") {
+			t.Fatalf("got %+v, want the scan", card)
+		}
+	})
+
+	t.Run("DeleteNurse", func(t *testing.T) {
+		if err := admin.DeleteNurse(ctx, testNurseId); err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+	})
+}
+
+func TestNurseClientErrors(t *testing.T) {
+	client := startServer(t, &fakeNurseRepo{})
+	nurseClient := client.WithToken(testToken(t, testNurseId, "nurse"))
+
+	_, err := nurseClient.GetUsers(context.Background(), nurse.GetUsersQuery{})
+	if api.StatusCode(err) != http.StatusUnauthorized || api.Code(err) != responses.CodeAdminOnly {
+		t.Errorf("got error %v, want the admin routes to refuse a nurse with %s", err, responses.CodeAdminOnly)
+	}
+}
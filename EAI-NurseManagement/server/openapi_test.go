@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/openapi"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+var routeParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// TestOpenAPICoversRoutes fails when a route is registered without being in
+// the OpenAPI document, or the other way around.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load the OpenAPI document : %+v", err)
+	}
+
+	s := NewServer(nil, nil, config.Config{})
+	s.registerRoutes(&fakeNurseRepo{})
+
+	registered := map[string]bool{}
+	for _, route := range s.app.GetRoutes(true) {
+		if route.Method == http.MethodHead {
+			continue
+		}
+
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		if len(path) > 1 {
+			path = strings.TrimRight(path, "/")
+		}
+		registered[route.Method+" "+path] = true
+
+		item := doc.Paths.Value(path)
+		if item == nil || item.GetOperation(route.Method) == nil {
+			t.Errorf("%s %s is registered but missing from the OpenAPI document", route.Method, path)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !registered[method+" "+path] {
+				t.Errorf("%s %s is in the OpenAPI document but not registered", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	s := NewServer(nil, nil, config.Config{})
+	s.registerRoutes(&fakeNurseRepo{})
+	token := testToken(t, testAdminId, "admin")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"nip as a string", http.MethodPut, "/v1/user/nurse/" + testNurseId, `{"nip": "3031202001001", "name": "nurse two"}`, http.StatusBadRequest},
+		{"too many wards", http.MethodPut, "/v1/user/nurse/" + testNurseId + "/wards", `{"wards": [` + strings.Repeat(`"ICU", `, 20) + `"ICU"]}`, http.StatusBadRequest},
+		{"limit as a string", http.MethodGet, "/v1/user?limit=ten", "", http.StatusBadRequest},
+		{"valid", http.MethodGet, "/v1/user?limit=10", "", http.StatusOK},
+		{"document", http.MethodGet, "/openapi.json", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := s.app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+// TestOpenAPIValidationAfterAuthentication checks the invalid requests of
+// callers without a valid token are turned away by the authentication, before
+// the validator describes what is wrong with them.
+func TestOpenAPIValidationAfterAuthentication(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	s := NewServer(nil, nil, config.Config{})
+	s.registerRoutes(&fakeNurseRepo{})
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+		code  string
+	}{
+		{"no token", "", http.StatusBadRequest, responses.CodeMissingToken},
+		{"invalid token", "not-a-token", http.StatusUnauthorized, responses.CodeInvalidToken},
+		{"nurse", testToken(t, testNurseId, "nurse"), http.StatusUnauthorized, responses.CodeAdminOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/user?limit=ten", strings.NewReader(``))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := s.app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+
+			var problem struct {
+				Code string `json:"code"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("failed to decode the error : %+v", err)
+			}
+			if resp.StatusCode != tt.want || problem.Code != tt.code {
+				t.Errorf("got %d %s, want %d %s", resp.StatusCode, problem.Code, tt.want, tt.code)
+			}
+		})
+	}
+}
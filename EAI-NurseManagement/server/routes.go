@@ -1,30 +1,42 @@
 package server
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/controller"
 	"github.com/ravenocx/hospital-mgt/middleware"
 	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
 	"github.com/ravenocx/hospital-mgt/service"
 )
 
 func (s *Server) RegisterRoute() {
+	s.registerRoutes(repositories.NewUserRepo(s.dbPool))
+}
+
+// registerRoutes serves the routes on top of repo, the database in the service
+// and a fake in the contract tests.
+func (s *Server) registerRoutes(repo repositories.NurseRepositories) {
 	mainRoute := s.app.Group("/v1/user")
 
-	NurseRoute(mainRoute, s.dbPool)
+	NurseRoute(mainRoute, repo, s.store, s.config, s.validate)
 }
 
-func NurseRoute(r fiber.Router, db *pgxpool.Pool) {
-	c := controller.NewUserController(service.NewNurseService(repositories.NewUserRepo(db)))
+func NurseRoute(r fiber.Router, repo repositories.NurseRepositories, store storage.BlobStore, config config.Config, validate fiber.Handler) {
+	c := controller.NewUserController(service.NewNurseService(repo, store, time.Duration(config.IdentityCardUrlExpiry)*time.Second))
 
-	r.Get("/",  middleware.JWTProtected(), middleware.AdminAuth(), c.GetUser)
+	r.Get("/",  middleware.JWTProtected(), middleware.AdminAuth(), validate, c.GetUser)
 
 	nurseRoute := r.Group("/nurse")
 
-	nurseRoute.Post("/register", middleware.JWTProtected(), middleware.AdminAuth(), c.NurseRegister)
-	nurseRoute.Put("/:userId", middleware.JWTProtected(), middleware.AdminAuth(), c.NurseUpdate)
-	nurseRoute.Delete("/:userId", middleware.JWTProtected(), middleware.AdminAuth(), c.NurseDelete)
-	nurseRoute.Post("/:userId/access", middleware.JWTProtected(), middleware.AdminAuth(), c.NurseAccess)
+	nurseRoute.Post("/register", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.NurseRegister)
+	nurseRoute.Put("/:userId", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.NurseUpdate)
+	nurseRoute.Delete("/:userId", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.NurseDelete)
+	nurseRoute.Post("/:userId/access", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.NurseAccess)
+	nurseRoute.Get("/:userId/wards", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.GetNurseWards)
+	nurseRoute.Put("/:userId/wards", middleware.JWTProtected(), middleware.AdminAuth(), validate, c.NurseWardUpdate)
+	nurseRoute.Get("/:userId/identity-card", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetIdentityCard)
 
 }
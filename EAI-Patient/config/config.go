@@ -24,6 +24,30 @@ type Config struct {
 	ServerHost        string
 	ServerPort        string
 	ServerReadTimeout int
+
+	// debug, info, warn or error
+	LogLevel string
+
+	// OTLP/HTTP endpoint of the collector, tracing is off without it
+	OtelExporterEndpoint string
+
+	StorageDriver   string
+	StorageLocalDir string
+
+	IdentityCardUrlExpiry int
+
+	S3Endpoint  string
+	S3AccessKey string
+	S3SecretKey string
+	S3Bucket    string
+	S3Region    string
+	S3UseSSL    bool
+
+	EncryptionKeys      string
+	EncryptionActiveKey int
+	EncryptionSearchKey string
+	ReencryptInterval   int // seconds
+	ReencryptBatchSize  int
 }
 
 var configOnce sync.Once
@@ -47,6 +71,15 @@ func LoadConfig() (Config, error) {
 			DBSslMode:  GetEnv("DB_SSL_MODE", "disable"),
 			ServerHost: GetEnv("SERVER_HOST", "127.0.0.1"),
 			ServerPort: GetEnv("SERVER_PORT", "3000"),
+
+			StorageDriver:   GetEnv("STORAGE_DRIVER", "local"),
+			StorageLocalDir: GetEnv("STORAGE_LOCAL_DIR", "./data/blobs"),
+
+			S3Endpoint:  GetEnv("S3_ENDPOINT", "127.0.0.1:9000"),
+			S3AccessKey: GetEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey: GetEnv("S3_SECRET_KEY", ""),
+			S3Bucket:    GetEnv("S3_BUCKET", "hospital-mgt"),
+			S3Region:    GetEnv("S3_REGION", "us-east-1"),
 		}
 
 		config.DbMaxConn, err = strconv.Atoi(GetEnv("DB_MAX_CONNECTIONS", "50"))
@@ -72,6 +105,42 @@ func LoadConfig() (Config, error) {
 			err = fmt.Errorf("failed to convert SERVER_READ_TIMEOUT to int: %v", err)
 			return
 		}
+
+		config.LogLevel = GetEnv("LOG_LEVEL", "info")
+		config.OtelExporterEndpoint = GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+
+		config.IdentityCardUrlExpiry, err = strconv.Atoi(GetEnv("IDENTITY_CARD_URL_EXPIRY_SECONDS", "300"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert IDENTITY_CARD_URL_EXPIRY_SECONDS to int: %v", err)
+			return
+		}
+
+		config.S3UseSSL, err = strconv.ParseBool(GetEnv("S3_USE_SSL", "false"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert S3_USE_SSL to bool: %v", err)
+			return
+		}
+
+		config.EncryptionKeys = GetEnv("ENCRYPTION_KEYS", "")
+		config.EncryptionSearchKey = GetEnv("ENCRYPTION_SEARCH_KEY", "")
+
+		config.EncryptionActiveKey, err = strconv.Atoi(GetEnv("ENCRYPTION_ACTIVE_KEY", "1"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert ENCRYPTION_ACTIVE_KEY to int: %v", err)
+			return
+		}
+
+		config.ReencryptInterval, err = strconv.Atoi(GetEnv("REENCRYPT_INTERVAL_SECONDS", "300"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert REENCRYPT_INTERVAL_SECONDS to int: %v", err)
+			return
+		}
+
+		config.ReencryptBatchSize, err = strconv.Atoi(GetEnv("REENCRYPT_BATCH_SIZE", "100"))
+		if err != nil {
+			err = fmt.Errorf("failed to convert REENCRYPT_BATCH_SIZE to int: %v", err)
+			return
+		}
 	})
 
 	return config, err
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type ConsentController struct {
+	service service.ConsentService
+}
+
+func NewConsentController(service service.ConsentService) *ConsentController {
+	return &ConsentController{service: service}
+}
+
+func (c *ConsentController) RegisterConsent(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	var newConsent models.ConsentRegistrationPayload
+	if err := ctx.BodyParser(&newConsent); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	id, custErr := c.service.RegisterConsent(context, identityNumber, newConsent, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Consent recorded successfully",
+		"data": fiber.Map{
+			"id":             id,
+			"identityNumber": identityNumber,
+			"consentType":    newConsent.ConsentType,
+			"version":        newConsent.Version,
+			"granted":        newConsent.Granted,
+		},
+	})
+}
+
+func (c *ConsentController) GetConsents(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	consentQuery := models.GetConsentQueries{
+		ConsentType:    ctx.Query("consentType"),
+		IncludeRevoked: ctx.QueryBool("includeRevoked", false),
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetConsents(context, identityNumber, consentQuery)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *ConsentController) RevokeConsent(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	id := ctx.Params("consentId")
+	var revocation models.ConsentRevocationPayload
+	if err := ctx.BodyParser(&revocation); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	custErr := c.service.RevokeConsent(context, identityNumber, id, revocation, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"id":      id,
+		"message": "success revoked consent",
+	})
+}
+
+func (c *ConsentController) CheckConsent(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.CheckConsent(context, identityNumber, ctx.Query("consentType"))
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
@@ -1,15 +1,16 @@
 package controller
 
 import (
-	"context"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
 	"github.com/ravenocx/hospital-mgt/responses"
 	"github.com/ravenocx/hospital-mgt/service"
+	"github.com/ravenocx/hospital-mgt/utils"
 )
 
 type PatientController struct {
@@ -28,7 +29,7 @@ type registerPatientResponse struct {
 func (c *PatientController) RegisterPatient(ctx *fiber.Ctx) error {
 	var newPatient models.PatientRegistrationPayload
 	if err := ctx.BodyParser(&newPatient); err != nil {
-		return responses.NewBadRequestError(err.Error())
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
 	}
 
 	imgFile, err := ctx.FormFile("identityCardScanImg")
@@ -38,12 +39,10 @@ func (c *PatientController) RegisterPatient(ctx *fiber.Ctx) error {
 
 	newPatient.IdentityCardScanImg = imgFile
 
-	context := context.Background()
+	context := ctx.UserContext()
 	custErr := c.service.RegisterPatient(context, newPatient)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
-		})
+		return custErr
 	}
 
 	responseData := registerPatientResponse{
@@ -69,7 +68,6 @@ func (c *PatientController) GetPatient(ctx *fiber.Ctx) error {
 	if err != nil || limit < 0 {
 		limit = 5
 	}
-	log.Printf("limit : %+v", limit)
 
 
 	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
@@ -80,6 +78,11 @@ func (c *PatientController) GetPatient(ctx *fiber.Ctx) error {
 	name := strings.ToLower(ctx.Query("name"))
 	phoneNumber := ctx.Query("phoneNumber")
 	createdAt := ctx.Query("createdAt")
+	if !repositories.PatientSort.Valid("createdAt", createdAt) {
+		return responses.NewValidationError("query params doesn't meet requirement", []responses.FieldError{
+			{Field: "createdAt", Rule: "oneof", Detail: "must be one of asc desc"},
+		})
+	}
 
 	patientQuery := models.GetPatientQueries{
 		IdentityNumber: identNumber,
@@ -90,23 +93,97 @@ func (c *PatientController) GetPatient(ctx *fiber.Ctx) error {
 		CreatedAt:      createdAt,
 	}
 
-	context := context.Background()
+	context := ctx.UserContext()
 	resp, custErr := c.service.GetPatient(context, patientQuery)
 	if (custErr != responses.CustomError{}) {
-		return ctx.Status(custErr.Status()).JSON(fiber.Map{
-			"message": custErr.Error(),
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
 		})
 	}
 
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *PatientController) SearchPatients(ctx *fiber.Ctx) error {
+	limit, err := strconv.Atoi(ctx.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	searchQuery := models.SearchPatientQueries{
+		Name:          strings.TrimSpace(ctx.Query("name")),
+		BirthDateFrom: ctx.Query("birthDateFrom"),
+		BirthDateTo:   ctx.Query("birthDateTo"),
+		Gender:        ctx.Query("gender"),
+		PhoneSuffix:   ctx.Query("phoneSuffix"),
+		Limit:         limit,
+		Cursor:        ctx.Query("cursor"),
+	}
+
+	context := ctx.UserContext()
+	resp, meta, custErr := c.service.SearchPatients(context, searchQuery)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
 	if len(resp) == 0 {
 		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 			"message": "success",
 			"data":    []interface{}{},
+			"meta":    meta,
 		})
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "success",
 		"data":    resp,
+		"meta":    meta,
+	})
+}
+
+func (c *PatientController) GetIdentityCard(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	access := models.IdentityCardAccess{
+		UserId:    claims.UserID.String(),
+		Role:      claims.Role,
+		ClientIP:  ctx.IP(),
+		Stream:    ctx.Query("mode") == "stream",
+		Thumbnail: ctx.Query("variant") == "thumbnail",
+	}
+
+	context := ctx.UserContext()
+	card, custErr := c.service.GetIdentityCard(context, identityNumber, access)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	if card.Body != nil {
+		ctx.Set(fiber.HeaderContentType, card.ContentType)
+		return ctx.Status(fiber.StatusOK).SendStream(card.Body)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    card,
 	})
 }
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/service"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type RegistryController struct {
+	service service.RegistryService
+}
+
+func NewRegistryController(service service.RegistryService) *RegistryController {
+	return &RegistryController{service: service}
+}
+
+func (c *RegistryController) RegisterAllergy(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	var newAllergy models.AllergyRegistrationPayload
+	if err := ctx.BodyParser(&newAllergy); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	id, custErr := c.service.RegisterAllergy(context, identityNumber, newAllergy, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Allergy recorded successfully",
+		"data": fiber.Map{
+			"id":             id,
+			"identityNumber": identityNumber,
+			"substance":      newAllergy.Substance,
+		},
+	})
+}
+
+func (c *RegistryController) GetAllergies(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetAllergies(context, identityNumber)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+func (c *RegistryController) RegisterCondition(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	var newCondition models.ConditionRegistrationPayload
+	if err := ctx.BodyParser(&newCondition); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	claims, err := utils.ExtractTokenMetadata(ctx)
+	if err != nil {
+		slog.WarnContext(ctx.UserContext(), "failed to read the token claims", "error", err)
+		return responses.NewUnauthorizedError("token not found").WithCode(responses.CodeInvalidToken)
+	}
+
+	context := ctx.UserContext()
+	id, custErr := c.service.RegisterCondition(context, identityNumber, newCondition, claims.UserID.String())
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Condition recorded successfully",
+		"data": fiber.Map{
+			"id":             id,
+			"identityNumber": identityNumber,
+			"condition":      newCondition.Condition,
+			"status":         newCondition.Status,
+		},
+	})
+}
+
+func (c *RegistryController) UpdateCondition(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	id := ctx.Params("conditionId")
+	var updatePayload models.ConditionUpdatePayload
+	if err := ctx.BodyParser(&updatePayload); err != nil {
+		return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidBody)
+	}
+
+	context := ctx.UserContext()
+	custErr := c.service.UpdateCondition(context, identityNumber, id, updatePayload)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"id":      id,
+		"message": "success updated condition",
+	})
+}
+
+func (c *RegistryController) GetConditions(ctx *fiber.Ctx) error {
+	identityNumber, err := strconv.ParseInt(ctx.Params("identityNumber"), 10, 64)
+	if err != nil {
+		return responses.NewBadRequestError("identityNumber is not in valid format").WithCode(responses.CodeInvalidIdentityNumber)
+	}
+
+	conditionQuery := models.GetConditionQueries{
+		Status: ctx.Query("status"),
+	}
+
+	context := ctx.UserContext()
+	resp, custErr := c.service.GetConditions(context, identityNumber, conditionQuery)
+	if (custErr != responses.CustomError{}) {
+		return custErr
+	}
+
+	if len(resp) == 0 {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "success",
+			"data":    []interface{}{},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    resp,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/config"
 )
@@ -30,6 +31,8 @@ func OpenConnection(config config.Config) (*pgxpool.Pool, error) {
 	dbconfig.HealthCheckPeriod = 5 * time.Second
 	dbconfig.MaxConns = int32(config.DbMaxConn)
 	dbconfig.MinConns = 10
+	// a span per query, under the span of the request
+	dbconfig.ConnConfig.Tracer = otelpgx.NewTracer()
 
 	pool, err := pgxpool.NewWithConfig(ctx,dbconfig)
 	if err != nil {
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS patient_allergies CASCADE;
+DROP TABLE IF EXISTS patient_conditions CASCADE;
+
+DROP INDEX IF EXISTS idx_patient_allergies_identity_number CASCADE;
+DROP INDEX IF EXISTS idx_patient_allergies_substance CASCADE;
+DROP INDEX IF EXISTS idx_patient_conditions_identity_number CASCADE;
+DROP INDEX IF EXISTS idx_patient_conditions_status CASCADE;
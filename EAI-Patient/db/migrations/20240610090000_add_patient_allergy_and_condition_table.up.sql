@@ -0,0 +1,28 @@
+CREATE TABLE patient_allergies (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number) ON DELETE CASCADE,
+    substance VARCHAR(100) NOT NULL,
+    reaction VARCHAR(255) NOT NULL,
+    severity VARCHAR(20) NOT NULL, -- mild, moderate, severe, life_threatening
+    recorded_by_user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE patient_conditions (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number) ON DELETE CASCADE,
+    condition VARCHAR(255) NOT NULL,
+    onset_date DATE,
+    status VARCHAR(20) NOT NULL, -- active, inactive, resolved
+    recorded_by_user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_patient_allergies_identity_number ON patient_allergies(identity_number);
+
+-- Each substance is recorded once per patient
+CREATE UNIQUE INDEX idx_patient_allergies_substance ON patient_allergies(identity_number, lower(substance));
+
+CREATE INDEX idx_patient_conditions_identity_number ON patient_conditions(identity_number);
+CREATE INDEX idx_patient_conditions_status ON patient_conditions(status);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS patient_consents CASCADE;
+
+DROP INDEX IF EXISTS idx_patient_consents_identity_number_type CASCADE;
@@ -0,0 +1,17 @@
+CREATE TABLE patient_consents (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    identity_number BIGINT NOT NULL REFERENCES patients(identity_number) ON DELETE CASCADE,
+    consent_type VARCHAR(20) NOT NULL, -- treatment, data_sharing, research, sms_contact
+    version VARCHAR(20) NOT NULL, -- version of the consent form the patient signed
+    granted BOOLEAN NOT NULL,
+    witness_name VARCHAR(50) NOT NULL,
+    recorded_by_user_id UUID NOT NULL,
+    signed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL,
+    revoked_by_user_id UUID NULL,
+    revocation_reason VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for looking up the current consent of a patient per type
+CREATE INDEX idx_patient_consents_identity_number_type ON patient_consents(identity_number, consent_type, signed_at DESC);
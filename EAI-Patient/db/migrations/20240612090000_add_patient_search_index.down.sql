@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_patients_phone_number_reverse CASCADE;
+DROP INDEX IF EXISTS idx_patients_name_dmetaphone CASCADE;
+DROP INDEX IF EXISTS idx_patients_birth_date CASCADE;
+
+DROP EXTENSION IF EXISTS "fuzzystrmatch";
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS "fuzzystrmatch";
+
+-- Index for suffix search on phone_number, reversed so it can be served as a prefix search
+CREATE INDEX idx_patients_phone_number_reverse ON patients(reverse(phone_number) text_pattern_ops);
+
+-- Index for phonetic search on name
+CREATE INDEX idx_patients_name_dmetaphone ON patients(dmetaphone(name));
+
+-- Index for birth date range filter
+CREATE INDEX idx_patients_birth_date ON patients(birth_date);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS patient_identity_card_access_logs CASCADE;
+
+DROP INDEX IF EXISTS idx_patient_identity_card_access_logs_identity_number CASCADE;
@@ -0,0 +1,11 @@
+CREATE TABLE patient_identity_card_access_logs (
+    id BIGSERIAL PRIMARY KEY,
+    identity_number BIGINT NOT NULL,
+    accessed_by_user_id UUID NOT NULL,
+    accessed_by_role VARCHAR(20) NOT NULL,
+    access_mode VARCHAR(20) NOT NULL, -- signed_url, stream, legacy_url
+    client_ip VARCHAR(45) NOT NULL,
+    accessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patient_identity_card_access_logs_identity_number ON patient_identity_card_access_logs(identity_number, accessed_at);
@@ -0,0 +1 @@
+ALTER TABLE patients DROP COLUMN IF EXISTS identity_card_thumbnail_img;
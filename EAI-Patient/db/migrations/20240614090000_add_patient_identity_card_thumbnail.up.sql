@@ -0,0 +1 @@
+ALTER TABLE patients ADD COLUMN identity_card_thumbnail_img TEXT;
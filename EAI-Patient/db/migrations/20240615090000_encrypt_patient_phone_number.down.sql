@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_patients_phone_number_search;
+
+ALTER TABLE patients DROP COLUMN IF EXISTS phone_number_search;
+
+-- only works once the phone numbers are back to plaintext
+ALTER TABLE patients ALTER COLUMN phone_number TYPE VARCHAR(15);
+
+CREATE INDEX idx_patients_phone_number_suffix ON patients(phone_number text_pattern_ops);
+
+CREATE INDEX idx_patients_phone_number_reverse ON patients(reverse(phone_number) text_pattern_ops);
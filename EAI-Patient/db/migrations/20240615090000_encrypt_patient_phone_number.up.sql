@@ -0,0 +1,14 @@
+-- phone_number is encrypted by the service, the ciphertext doesn't fit the
+-- old length and can't be searched in SQL. It is searched through the blind
+-- indexes of its prefixes and suffixes instead. Patients registered before
+-- get them from the re-encryption job, until then their phone number can't be
+-- searched.
+DROP INDEX IF EXISTS idx_patients_phone_number_reverse;
+
+DROP INDEX IF EXISTS idx_patients_phone_number_suffix;
+
+ALTER TABLE patients ALTER COLUMN phone_number TYPE TEXT;
+
+ALTER TABLE patients ADD COLUMN phone_number_search TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_patients_phone_number_search ON patients USING gin(phone_number_search);
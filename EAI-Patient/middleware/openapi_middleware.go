@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/openapivalidator"
+)
+
+// OpenAPIValidator rejects with a 400 the requests whose parameters or body
+// don't match the OpenAPI document. It goes on the routes after JWTProtected
+// and the role checks, so callers without a token never see the schema.
+func OpenAPIValidator(doc *openapi3.T) (fiber.Handler, error) {
+	return openapivalidator.New(doc, func(_ *fiber.Ctx, err *openapivalidator.Error) error {
+		if err.Field == nil {
+			return responses.NewBadRequestError(err.Message)
+		}
+
+		return responses.NewValidationError(err.Message, []responses.FieldError{{Field: err.Field.Field, Rule: err.Field.Rule, Detail: err.Field.Detail}})
+	})
+}
@@ -0,0 +1,48 @@
+package models
+
+const (
+	ConsentTypeTreatment   = "treatment"
+	ConsentTypeDataSharing = "data_sharing"
+	ConsentTypeResearch    = "research"
+	ConsentTypeSmsContact  = "sms_contact"
+)
+
+type ConsentRegistrationPayload struct {
+	ConsentType string `json:"consentType" form:"consentType" validate:"required,oneof='treatment' 'data_sharing' 'research' 'sms_contact'"`
+	Version     string `json:"version" form:"version" validate:"required,min=1,max=20"`
+	Granted     *bool  `json:"granted" form:"granted" validate:"required"`
+	WitnessName string `json:"witnessName" form:"witnessName" validate:"required,min=5,max=50"`
+	SignedAt    string `json:"signedAt" form:"signedAt" validate:"omitempty,datetime"`
+}
+
+type ConsentRevocationPayload struct {
+	Reason string `json:"reason" form:"reason" validate:"required,min=5,max=255"`
+}
+
+type GetConsentQueries struct {
+	ConsentType    string `json:"consentType" query:"consentType" validate:"omitempty,oneof='treatment' 'data_sharing' 'research' 'sms_contact'"`
+	IncludeRevoked bool   `json:"includeRevoked" query:"includeRevoked"`
+}
+
+type GetConsentResponse struct {
+	ID               string  `db:"id" json:"id"`
+	IdentityNumber   int64   `db:"identity_number" json:"identityNumber"`
+	ConsentType      string  `db:"consent_type" json:"consentType"`
+	Version          string  `db:"version" json:"version"`
+	Granted          bool    `db:"granted" json:"granted"`
+	WitnessName      string  `db:"witness_name" json:"witnessName"`
+	RecordedByUserId string  `db:"recorded_by_user_id" json:"recordedByUserId"`
+	SignedAt         string  `db:"signed_at" json:"signedAt"`
+	RevokedAt        *string `db:"revoked_at" json:"revokedAt"`
+	RevokedByUserId  *string `db:"revoked_by_user_id" json:"revokedByUserId"`
+	RevocationReason *string `db:"revocation_reason" json:"revocationReason"`
+}
+
+type ConsentCheckResponse struct {
+	IdentityNumber int64   `json:"identityNumber"`
+	ConsentType    string  `json:"consentType"`
+	Granted        bool    `json:"granted"`
+	ConsentId      *string `json:"consentId"`
+	Version        *string `json:"version"`
+	SignedAt       *string `json:"signedAt"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"io"
 	"mime/multipart"
 	"time"
 )
@@ -23,6 +24,7 @@ type PatientRegistrationPayload struct {
 	Gender                    string                `db:"gender" json:"gender" form:"gender" validate:"required,oneof='male' 'female'"`
 	IdentityCardScanImg       *multipart.FileHeader `json:"identityCardScanImg" form:"identityCardScanImg" validate:"required,img_file"`
 	IdentityCardScanImgString string                `db:"identity_card_scan_img"`
+	IdentityCardThumbnailImg  string                `db:"identity_card_thumbnail_img"`
 }
 
 type GetPatientQueries struct {
@@ -42,3 +44,57 @@ type GetPatientResponse struct {
 	Gender         string `db:"gender" json:"gender"`
 	CreatedAt      string `db:"created_at" json:"createdAt"`
 }
+
+type SearchPatientQueries struct {
+	Name          string               `json:"name" query:"name" validate:"omitempty,min=2,max=50"`
+	BirthDateFrom string               `json:"birthDateFrom" query:"birthDateFrom" validate:"omitempty,datetime=2006-01-02"`
+	BirthDateTo   string               `json:"birthDateTo" query:"birthDateTo" validate:"omitempty,datetime=2006-01-02"`
+	Gender        string               `json:"gender" query:"gender" validate:"omitempty,oneof='male' 'female'"`
+	PhoneSuffix   string               `json:"phoneSuffix" query:"phoneSuffix" validate:"omitempty,numeric,min=3,max=15"`
+	Limit         int                  `json:"limit" query:"limit" validate:"min=1,max=100"`
+	Cursor        string               `json:"cursor" query:"cursor"`
+	After         *PatientSearchCursor `json:"-" query:"-"`
+}
+
+// PatientSearchCursor is the position of the last returned row in the search
+// ordering (score DESC, created_at DESC, identity_number ASC).
+type PatientSearchCursor struct {
+	Score          float64 `json:"s"`
+	CreatedAt      string  `json:"c"`
+	IdentityNumber int64   `json:"i"`
+}
+
+type SearchPatientResponse struct {
+	GetPatientResponse
+	Score float64 `json:"score"`
+}
+
+type SearchPatientMeta struct {
+	Total      int64   `json:"total"`
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+type IdentityCardAccess struct {
+	UserId    string
+	Role      string
+	ClientIP  string
+	Stream    bool
+	Thumbnail bool
+}
+
+type IdentityCardAccessLog struct {
+	IdentityNumber int64
+	UserId         string
+	Role           string
+	AccessMode     string
+	ClientIP       string
+}
+
+// IdentityCard is either a signed URL to the scan or its content, never both.
+type IdentityCard struct {
+	URL         string        `json:"url"`
+	ExpiresAt   *string       `json:"expiresAt"`
+	Body        io.ReadCloser `json:"-"`
+	ContentType string        `json:"-"`
+}
@@ -0,0 +1,41 @@
+package models
+
+type AllergyRegistrationPayload struct {
+	Substance string `json:"substance" form:"substance" validate:"required,min=2,max=100"`
+	Reaction  string `json:"reaction" form:"reaction" validate:"required,min=2,max=255"`
+	Severity  string `json:"severity" form:"severity" validate:"required,oneof='mild' 'moderate' 'severe' 'life_threatening'"`
+}
+
+type GetAllergyResponse struct {
+	ID               string `db:"id" json:"id"`
+	IdentityNumber   int64  `db:"identity_number" json:"identityNumber"`
+	Substance        string `db:"substance" json:"substance"`
+	Reaction         string `db:"reaction" json:"reaction"`
+	Severity         string `db:"severity" json:"severity"`
+	RecordedByUserId string `db:"recorded_by_user_id" json:"recordedByUserId"`
+	CreatedAt        string `db:"created_at" json:"createdAt"`
+}
+
+type ConditionRegistrationPayload struct {
+	Condition string `json:"condition" form:"condition" validate:"required,min=2,max=255"`
+	OnsetDate string `json:"onsetDate" form:"onsetDate" validate:"omitempty,datetime=2006-01-02"`
+	Status    string `json:"status" form:"status" validate:"required,oneof='active' 'inactive' 'resolved'"`
+}
+
+type ConditionUpdatePayload struct {
+	Status string `json:"status" form:"status" validate:"required,oneof='active' 'inactive' 'resolved'"`
+}
+
+type GetConditionQueries struct {
+	Status string `json:"status" query:"status" validate:"omitempty,oneof='active' 'inactive' 'resolved'"`
+}
+
+type GetConditionResponse struct {
+	ID               string `db:"id" json:"id"`
+	IdentityNumber   int64  `db:"identity_number" json:"identityNumber"`
+	Condition        string `db:"condition" json:"condition"`
+	OnsetDate        string `db:"onset_date" json:"onsetDate,omitempty"`
+	Status           string `db:"status" json:"status"`
+	RecordedByUserId string `db:"recorded_by_user_id" json:"recordedByUserId"`
+	CreatedAt        string `db:"created_at" json:"createdAt"`
+}
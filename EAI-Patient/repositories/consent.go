@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+)
+
+type ConsentRepositories interface {
+	CreateConsent(ctx context.Context, identityNumber int64, consent *models.ConsentRegistrationPayload, recordedBy string) (string, error)
+	GetConsents(ctx context.Context, identityNumber int64, filter models.GetConsentQueries) ([]models.GetConsentResponse, error)
+	GetCurrentConsent(ctx context.Context, identityNumber int64, consentType string) (*models.GetConsentResponse, error)
+	RevokeConsent(ctx context.Context, identityNumber int64, consentId string, revokedBy string, reason string) (pgconn.CommandTag, error)
+}
+
+type consentRepositories struct {
+	db *pgxpool.Pool
+}
+
+func NewConsentRepo(db *pgxpool.Pool) ConsentRepositories {
+	return &consentRepositories{db}
+}
+
+const consentColumns = "id, identity_number, consent_type, version, granted, witness_name, recorded_by_user_id, signed_at, revoked_at, revoked_by_user_id, revocation_reason"
+
+func (r *consentRepositories) CreateConsent(ctx context.Context, identityNumber int64, consent *models.ConsentRegistrationPayload, recordedBy string) (string, error) {
+	var id string
+	signedAt := time.Now()
+	statement := "INSERT INTO patient_consents (identity_number, consent_type, version, granted, witness_name, recorded_by_user_id, signed_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+
+	if consent.SignedAt != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, consent.SignedAt)
+		if err != nil {
+			return "", err
+		}
+		signedAt = parsed
+	}
+
+	row := r.db.QueryRow(ctx, statement, identityNumber, consent.ConsentType, consent.Version, *consent.Granted, consent.WitnessName, recordedBy, signedAt)
+	if err := row.Scan(&id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *consentRepositories) GetConsents(ctx context.Context, identityNumber int64, filter models.GetConsentQueries) ([]models.GetConsentResponse, error) {
+	var consents []models.GetConsentResponse
+	query := "SELECT " + consentColumns + " FROM patient_consents WHERE identity_number = $1 AND ($2 = '' OR consent_type = $2) AND ($3 OR revoked_at IS NULL) ORDER BY signed_at DESC"
+
+	rows, err := r.db.Query(ctx, query, identityNumber, filter.ConsentType, filter.IncludeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		consent, err := scanConsent(rows)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, *consent)
+	}
+
+	return consents, rows.Err()
+}
+
+// GetCurrentConsent returns the latest consent of the given type, revoked or
+// not. A newer consent always supersedes the older ones, revoking it doesn't
+// bring an older grant back.
+func (r *consentRepositories) GetCurrentConsent(ctx context.Context, identityNumber int64, consentType string) (*models.GetConsentResponse, error) {
+	query := "SELECT " + consentColumns + " FROM patient_consents WHERE identity_number = $1 AND consent_type = $2 ORDER BY signed_at DESC LIMIT 1"
+
+	row := r.db.QueryRow(ctx, query, identityNumber, consentType)
+
+	return scanConsent(row)
+}
+
+func (r *consentRepositories) RevokeConsent(ctx context.Context, identityNumber int64, consentId string, revokedBy string, reason string) (pgconn.CommandTag, error) {
+	statement := "UPDATE patient_consents SET revoked_at = $1, revoked_by_user_id = $2, revocation_reason = $3 WHERE id = $4 AND identity_number = $5 AND revoked_at IS NULL"
+
+	res, err := r.db.Exec(ctx, statement, time.Now(), revokedBy, reason, consentId, identityNumber)
+
+	return res, err
+}
+
+func scanConsent(row pgx.Row) (*models.GetConsentResponse, error) {
+	var consent models.GetConsentResponse
+	var signedAt time.Time
+	var revokedAt *time.Time
+
+	err := row.Scan(&consent.ID, &consent.IdentityNumber, &consent.ConsentType, &consent.Version, &consent.Granted, &consent.WitnessName, &consent.RecordedByUserId, &signedAt, &revokedAt, &consent.RevokedByUserId, &consent.RevocationReason)
+	if err != nil {
+		return nil, err
+	}
+
+	consent.SignedAt = signedAt.Format(time.RFC3339Nano)
+	if revokedAt != nil {
+		formatted := revokedAt.Format(time.RFC3339Nano)
+		consent.RevokedAt = &formatted
+	}
+
+	return &consent, nil
+}
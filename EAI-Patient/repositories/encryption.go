@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+)
+
+// EncryptionRepositories moves the encrypted phone numbers to the active
+// master key. Phone numbers still in plaintext are encrypted the same way.
+type EncryptionRepositories interface {
+	ReencryptPatients(ctx context.Context, limit int) (int, error)
+}
+
+type encryptionRepositories struct {
+	db      *pgxpool.Pool
+	keyring *envelope.Keyring
+}
+
+func NewEncryptionRepo(db *pgxpool.Pool, keyring *envelope.Keyring) EncryptionRepositories {
+	return &encryptionRepositories{db, keyring}
+}
+
+type reencryptRow struct {
+	identityNumber int64
+	phoneNumber    string
+}
+
+// ReencryptPatients re-encrypts the phone number of up to limit patients and
+// rebuilds their search indexes, which patients registered before encryption
+// don't have yet.
+func (r *encryptionRepositories) ReencryptPatients(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := "SELECT identity_number, phone_number FROM patients WHERE phone_number NOT LIKE $1 || '%' ORDER BY identity_number LIMIT $2 FOR UPDATE SKIP LOCKED"
+
+	rows, err := tx.Query(ctx, query, r.keyring.ActivePrefix(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// the rows are read before updating, the transaction runs one query at a time
+	patients := []reencryptRow{}
+	for rows.Next() {
+		var patient reencryptRow
+		if err := rows.Scan(&patient.identityNumber, &patient.phoneNumber); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		patients = append(patients, patient)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, patient := range patients {
+		if err := r.keyring.DecryptAll(&patient.phoneNumber); err != nil {
+			return 0, err
+		}
+
+		phoneNumber, err := r.keyring.Encrypt(patient.phoneNumber)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE patients SET phone_number = $2, phone_number_search = $3 WHERE identity_number = $1",
+			patient.identityNumber, phoneNumber, phoneNumberSearch(r.keyring, patient.phoneNumber))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(patients), tx.Commit(ctx)
+}
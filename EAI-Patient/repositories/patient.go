@@ -3,25 +3,33 @@ package repositories
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/querybuilder"
 )
 
 type PatientRepositories interface {
 	GetPatient(ctx context.Context, patientIdentityNumber int64) (string, error)
 	CreatePatient(ctx context.Context, patient *models.PatientRegistrationPayload) error
 	GetPatients(ctx context.Context, filter models.GetPatientQueries) ([]models.GetPatientResponse, error)
+	SearchPatients(ctx context.Context, filter models.SearchPatientQueries) ([]models.SearchPatientResponse, int64, error)
+	GetIdentityCardKey(ctx context.Context, patientIdentityNumber int64, thumbnail bool) (string, error)
+	CreateIdentityCardAccessLog(ctx context.Context, accessLog models.IdentityCardAccessLog) error
 }
 
+// patientRepositories encrypts the phone number of the patients and decrypts
+// it on read. It is searched through phone_number_search, the blind indexes of
+// its prefixes and suffixes.
 type patientRepositories struct {
-	db *pgxpool.Pool
+	db      *pgxpool.Pool
+	keyring *envelope.Keyring
 }
 
-func NewPatientRepo(db *pgxpool.Pool) PatientRepositories {
-	return &patientRepositories{db}
+func NewPatientRepo(db *pgxpool.Pool, keyring *envelope.Keyring) PatientRepositories {
+	return &patientRepositories{db, keyring}
 }
 
 func (r *patientRepositories) GetPatient(ctx context.Context, patientIdentityNumber int64) (string, error) {
@@ -38,9 +46,14 @@ func (r *patientRepositories) GetPatient(ctx context.Context, patientIdentityNum
 }
 
 func (r *patientRepositories) CreatePatient(ctx context.Context, patient *models.PatientRegistrationPayload) error {
-	statement := "INSERT INTO patients (identity_number, phone_number, name, birth_date, gender, identity_card_scan_img) VALUES ($1, $2, $3, $4, $5, $6)"
+	statement := "INSERT INTO patients (identity_number, phone_number, phone_number_search, name, birth_date, gender, identity_card_scan_img, identity_card_thumbnail_img) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
 
-	_, err := r.db.Exec(ctx, statement, patient.IdentityNumber, patient.PhoneNumber, patient.Name, patient.BirthDate, patient.Gender, patient.IdentityCardScanImgString)
+	phoneNumber, err := r.keyring.Encrypt(patient.PhoneNumber)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, statement, patient.IdentityNumber, phoneNumber, phoneNumberSearch(r.keyring, patient.PhoneNumber), patient.Name, patient.BirthDate, patient.Gender, patient.IdentityCardScanImgString, patient.IdentityCardThumbnailImg)
 	if err != nil {
 		return err
 	}
@@ -48,30 +61,46 @@ func (r *patientRepositories) CreatePatient(ctx context.Context, patient *models
 	return nil
 }
 
+func (r *patientRepositories) GetIdentityCardKey(ctx context.Context, patientIdentityNumber int64, thumbnail bool) (string, error) {
+	var key string
+	query := "SELECT identity_card_scan_img FROM patients WHERE identity_number = $1"
+	if thumbnail {
+		// patients registered before thumbnails existed don't have one
+		query = "SELECT COALESCE(identity_card_thumbnail_img, '') FROM patients WHERE identity_number = $1"
+	}
+
+	row := r.db.QueryRow(ctx, query, patientIdentityNumber)
+	if err := row.Scan(&key); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (r *patientRepositories) CreateIdentityCardAccessLog(ctx context.Context, accessLog models.IdentityCardAccessLog) error {
+	statement := "INSERT INTO patient_identity_card_access_logs (identity_number, accessed_by_user_id, accessed_by_role, access_mode, client_ip) VALUES ($1, $2, $3, $4, $5)"
+
+	_, err := r.db.Exec(ctx, statement, accessLog.IdentityNumber, accessLog.UserId, accessLog.Role, accessLog.AccessMode, accessLog.ClientIP)
+
+	return err
+}
+
 func (r *patientRepositories) GetPatients(ctx context.Context, filter models.GetPatientQueries) ([]models.GetPatientResponse, error) {
 	var patients []models.GetPatientResponse
 	var createdAt time.Time
 	var birthDate time.Time
 	query := "SELECT identity_number, phone_number, name, birth_date, gender, created_at FROM patients"
 
-	query += getPatientConstructWhereQuery(filter)
+	qb := getPatientConstructWhereQuery(r.keyring, filter)
+	query += qb.WhereClause()
+	query += PatientSort.Clause("createdAt", filter.CreatedAt)
+	query += qb.Limit(filter.Limit, filter.Offset)
 
-	if filter.CreatedAt != "" {
-		if filter.CreatedAt == "asc" {
-			query += " ORDER BY created_at ASC"
-		} else if filter.CreatedAt == "desc" {
-			query += " ORDER BY created_at DESC"
-		}
-	} else {
-		query += " ORDER BY created_at DESC"
-	}
-
-	query += " limit $1 offset $2"
-
-	rows, err := r.db.Query(ctx, query, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		patient := models.GetPatientResponse{}
@@ -79,6 +108,10 @@ func (r *patientRepositories) GetPatients(ctx context.Context, filter models.Get
 		if err != nil {
 			return nil, err
 		}
+
+		if err := r.keyring.DecryptAll(&patient.PhoneNumber); err != nil {
+			return nil, err
+		}
 		patient.BirthDate = birthDate.Format(time.RFC3339Nano)
 		patient.CreatedAt = createdAt.Format(time.RFC3339Nano)
 		patients = append(patients, patient)
@@ -87,24 +120,130 @@ func (r *patientRepositories) GetPatients(ctx context.Context, filter models.Get
 	return patients, nil
 }
 
-func getPatientConstructWhereQuery(filter models.GetPatientQueries) string {
-	whereSQL := []string{}
+// SearchPatients returns up to filter.Limit+1 patients after the cursor so the
+// caller can tell whether there is a next page, along with the total number of
+// patients matching the filter regardless of the cursor.
+func (r *patientRepositories) SearchPatients(ctx context.Context, filter models.SearchPatientQueries) ([]models.SearchPatientResponse, int64, error) {
+	var patients []models.SearchPatientResponse
+	var total int64
+	qb := querybuilder.New()
+	score := "0::float8"
+
+	if filter.Name != "" {
+		name := qb.Arg(filter.Name)
+		qb.Where(fmt.Sprintf("(name %% %[1]s OR %[1]s <%% name OR dmetaphone(name) = dmetaphone(%[1]s) OR name ILIKE '%%' || %[2]s || '%%')", name, qb.Arg(querybuilder.EscapeLike(filter.Name))))
+		score = fmt.Sprintf("GREATEST(similarity(name, %[1]s), word_similarity(%[1]s, name), CASE WHEN dmetaphone(name) = dmetaphone(%[1]s) THEN 0.5 ELSE 0 END)::float8", name)
+	}
+
+	if filter.BirthDateFrom != "" {
+		qb.Where("birth_date >= ?::date", filter.BirthDateFrom)
+	}
+
+	if filter.BirthDateTo != "" {
+		qb.Where("birth_date <= ?::date", filter.BirthDateTo)
+	}
+
+	if filter.Gender != "" {
+		qb.Equal("gender", filter.Gender)
+	}
+
+	if filter.PhoneSuffix != "" {
+		qb.Where("phone_number_search @> ARRAY[?::text]", phoneSuffixIndex(r.keyring, filter.PhoneSuffix))
+	}
+
+	where := qb.WhereClause()
+
+	countQuery := "SELECT COUNT(*) FROM patients" + where
+	if err := r.db.QueryRow(ctx, countQuery, qb.Args()...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT identity_number, phone_number, name, birth_date, gender, created_at, score FROM (" +
+		"SELECT identity_number, phone_number, name, birth_date, gender, COALESCE(created_at, 'epoch'::timestamp) AS created_at, " + score + " AS score FROM patients" + where +
+		") p"
+
+	if filter.After != nil {
+		afterScore, afterCreatedAt, afterIdentityNumber := qb.Arg(filter.After.Score), qb.Arg(filter.After.CreatedAt), qb.Arg(filter.After.IdentityNumber)
+		query += fmt.Sprintf(" WHERE (score < %[1]s OR (score = %[1]s AND (created_at < %[2]s::timestamp OR (created_at = %[2]s::timestamp AND identity_number > %[3]s))))", afterScore, afterCreatedAt, afterIdentityNumber)
+	}
+
+	query += " ORDER BY score DESC, created_at DESC, identity_number ASC LIMIT " + qb.Arg(filter.Limit+1)
+
+	rows, err := r.db.Query(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var birthDate time.Time
+		var createdAt time.Time
+		patient := models.SearchPatientResponse{}
+		err := rows.Scan(&patient.IdentityNumber, &patient.PhoneNumber, &patient.Name, &birthDate, &patient.Gender, &createdAt, &patient.Score)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if err := r.keyring.DecryptAll(&patient.PhoneNumber); err != nil {
+			return nil, 0, err
+		}
+		patient.BirthDate = birthDate.Format(time.RFC3339Nano)
+		patient.CreatedAt = createdAt.Format(time.RFC3339Nano)
+		patients = append(patients, patient)
+	}
+
+	return patients, total, rows.Err()
+}
+
+// PatientSort whitelists what the list is sorted on, the handler rejects anything
+// else.
+var PatientSort = querybuilder.Sort{
+	Columns:          map[string]string{"createdAt": "created_at"},
+	DefaultColumn:    "createdAt",
+	DefaultDirection: "desc",
+}
+
+func getPatientConstructWhereQuery(keyring *envelope.Keyring, filter models.GetPatientQueries) *querybuilder.Builder {
+	qb := querybuilder.New()
 
 	if filter.IdentityNumber != nil {
-		whereSQL = append(whereSQL, fmt.Sprintf(" identity_number = %d", *filter.IdentityNumber))
+		qb.Equal("identity_number", *filter.IdentityNumber)
 	}
 
 	if filter.PhoneNumber != "" {
-		whereSQL = append(whereSQL, " phone_number ILIKE '+"+filter.PhoneNumber+"%'")
+		qb.Where("phone_number_search @> ARRAY[?::text]", phonePrefixIndex(keyring, "+"+filter.PhoneNumber))
 	}
 
 	if filter.Name != "" {
-		whereSQL = append(whereSQL, " name ILIKE '%"+filter.Name+"%'")
+		qb.Contains("name", filter.Name)
+	}
+
+	return qb
+}
+
+// minPhoneSuffix is the shortest phone suffix that can be searched.
+const minPhoneSuffix = 3
+
+func phonePrefixIndex(keyring *envelope.Keyring, prefix string) string {
+	return keyring.BlindIndex("prefix:" + prefix)
+}
+
+func phoneSuffixIndex(keyring *envelope.Keyring, suffix string) string {
+	return keyring.BlindIndex("suffix:" + suffix)
+}
+
+// phoneNumberSearch returns the blind indexes of every prefix and of every
+// suffix of at least minPhoneSuffix characters of the phone number.
+func phoneNumberSearch(keyring *envelope.Keyring, phoneNumber string) []string {
+	indexes := []string{}
+
+	for i := 1; i <= len(phoneNumber); i++ {
+		indexes = append(indexes, phonePrefixIndex(keyring, phoneNumber[:i]))
 	}
 
-	if len(whereSQL) > 0 {
-		return " WHERE " + strings.Join(whereSQL, " AND ")
+	for i := len(phoneNumber) - minPhoneSuffix; i >= 0; i-- {
+		indexes = append(indexes, phoneSuffixIndex(keyring, phoneNumber[i:]))
 	}
 
-	return ""
+	return indexes
 }
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/models"
+)
+
+type RegistryRepositories interface {
+	GetAllergyBySubstance(ctx context.Context, identityNumber int64, substance string) (string, error)
+	CreateAllergy(ctx context.Context, identityNumber int64, allergy *models.AllergyRegistrationPayload, recordedBy string) (string, error)
+	GetAllergies(ctx context.Context, identityNumber int64) ([]models.GetAllergyResponse, error)
+	CreateCondition(ctx context.Context, identityNumber int64, condition *models.ConditionRegistrationPayload, recordedBy string) (string, error)
+	UpdateConditionStatus(ctx context.Context, identityNumber int64, conditionId string, status string) (pgconn.CommandTag, error)
+	GetConditions(ctx context.Context, identityNumber int64, filter models.GetConditionQueries) ([]models.GetConditionResponse, error)
+}
+
+type registryRepositories struct {
+	db *pgxpool.Pool
+}
+
+func NewRegistryRepo(db *pgxpool.Pool) RegistryRepositories {
+	return &registryRepositories{db}
+}
+
+func (r *registryRepositories) GetAllergyBySubstance(ctx context.Context, identityNumber int64, substance string) (string, error) {
+	var id string
+	query := "SELECT id FROM patient_allergies WHERE identity_number = $1 AND lower(substance) = lower($2)"
+
+	row := r.db.QueryRow(ctx, query, identityNumber, substance)
+	if err := row.Scan(&id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *registryRepositories) CreateAllergy(ctx context.Context, identityNumber int64, allergy *models.AllergyRegistrationPayload, recordedBy string) (string, error) {
+	var id string
+	statement := "INSERT INTO patient_allergies (identity_number, substance, reaction, severity, recorded_by_user_id) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+
+	row := r.db.QueryRow(ctx, statement, identityNumber, allergy.Substance, allergy.Reaction, allergy.Severity, recordedBy)
+	if err := row.Scan(&id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *registryRepositories) GetAllergies(ctx context.Context, identityNumber int64) ([]models.GetAllergyResponse, error) {
+	var allergies []models.GetAllergyResponse
+	var createdAt time.Time
+	query := "SELECT id, identity_number, substance, reaction, severity, recorded_by_user_id, created_at FROM patient_allergies WHERE identity_number = $1 ORDER BY created_at DESC"
+
+	rows, err := r.db.Query(ctx, query, identityNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		allergy := models.GetAllergyResponse{}
+		err := rows.Scan(&allergy.ID, &allergy.IdentityNumber, &allergy.Substance, &allergy.Reaction, &allergy.Severity, &allergy.RecordedByUserId, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		allergy.CreatedAt = createdAt.Format(time.RFC3339Nano)
+		allergies = append(allergies, allergy)
+	}
+
+	return allergies, rows.Err()
+}
+
+func (r *registryRepositories) CreateCondition(ctx context.Context, identityNumber int64, condition *models.ConditionRegistrationPayload, recordedBy string) (string, error) {
+	var id string
+	var onsetDate *string
+	statement := "INSERT INTO patient_conditions (identity_number, condition, onset_date, status, recorded_by_user_id) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+
+	if condition.OnsetDate != "" {
+		onsetDate = &condition.OnsetDate
+	}
+
+	row := r.db.QueryRow(ctx, statement, identityNumber, condition.Condition, onsetDate, condition.Status, recordedBy)
+	if err := row.Scan(&id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *registryRepositories) UpdateConditionStatus(ctx context.Context, identityNumber int64, conditionId string, status string) (pgconn.CommandTag, error) {
+	statement := "UPDATE patient_conditions SET status = $1, updated_at = $2 WHERE id = $3 AND identity_number = $4"
+
+	res, err := r.db.Exec(ctx, statement, status, time.Now(), conditionId, identityNumber)
+
+	return res, err
+}
+
+func (r *registryRepositories) GetConditions(ctx context.Context, identityNumber int64, filter models.GetConditionQueries) ([]models.GetConditionResponse, error) {
+	var conditions []models.GetConditionResponse
+	var onsetDate *time.Time
+	var createdAt time.Time
+	query := "SELECT id, identity_number, condition, onset_date, status, recorded_by_user_id, created_at FROM patient_conditions WHERE identity_number = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC"
+
+	rows, err := r.db.Query(ctx, query, identityNumber, filter.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		condition := models.GetConditionResponse{}
+		err := rows.Scan(&condition.ID, &condition.IdentityNumber, &condition.Condition, &onsetDate, &condition.Status, &condition.RecordedByUserId, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		if onsetDate != nil {
+			condition.OnsetDate = onsetDate.Format("2006-01-02")
+		}
+		condition.CreatedAt = createdAt.Format(time.RFC3339Nano)
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, rows.Err()
+}
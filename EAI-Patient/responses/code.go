@@ -0,0 +1,30 @@
+package responses
+
+// The codes are part of the API, clients branch on them. Never rename one,
+// add a new code instead.
+const (
+	CodeBadRequest       = "BAD_REQUEST"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidBody      = "INVALID_BODY"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeMissingToken     = "MISSING_TOKEN"
+	CodeInvalidToken     = "INVALID_TOKEN"
+	CodeTokenExpired     = "TOKEN_EXPIRED"
+	CodeAdminOnly        = "ADMIN_ONLY"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+const (
+	CodeInvalidIdentityNumber = "INVALID_IDENTITY_NUMBER"
+	CodeInvalidCursor         = "INVALID_CURSOR"
+	CodeInvalidImage          = "INVALID_IMAGE"
+	CodePatientNotFound       = "PATIENT_NOT_FOUND"
+	CodePatientConflict       = "PATIENT_CONFLICT"
+	CodeIdentityCardNotFound  = "IDENTITY_CARD_NOT_FOUND"
+	CodeConsentNotFound       = "CONSENT_NOT_FOUND"
+	CodeAllergyConflict       = "ALLERGY_CONFLICT"
+	CodeConditionNotFound     = "CONDITION_NOT_FOUND"
+)
@@ -0,0 +1,413 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+	"github.com/ravenocx/hospital-mgt/sdk/patient"
+	"github.com/ravenocx/hospital-mgt/sdk/sdktest"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
+)
+
+// The contract tests run the patient client of the sdk against the real
+// routes and handlers, on top of repositories returning completely filled
+// rows. A response field renamed or dropped on either side fails them.
+
+const (
+	testSecret         = "contract-test-secret"
+	testIdentityNumber = int64(3201234567890001)
+	// the repositories don't know this patient yet
+	testNewIdentityNumber = int64(3201234567890002)
+	testNurseId           = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a004"
+	testConsentId         = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a007"
+	testConditionId       = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a008"
+	testScanKey           = "identity-card/scan.png"
+	testTime              = "2024-05-01T08:00:00Z"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testPatient(identityNumber int64) models.GetPatientResponse {
+	return models.GetPatientResponse{
+		IdentityNumber: identityNumber,
+		PhoneNumber:    "+6281234567890",
+		Name:           "patient one",
+		BirthDate:      "1990-01-01T00:00:00Z",
+		Gender:         "female",
+		CreatedAt:      testTime,
+	}
+}
+
+func testConsent() models.GetConsentResponse {
+	return models.GetConsentResponse{
+		ID:               testConsentId,
+		IdentityNumber:   testIdentityNumber,
+		ConsentType:      models.ConsentTypeTreatment,
+		Version:          "v1",
+		Granted:          true,
+		WitnessName:      "witness one",
+		RecordedByUserId: testNurseId,
+		SignedAt:         testTime,
+		RevokedAt:        ptr("2024-05-02T08:00:00Z"),
+		RevokedByUserId:  ptr(testNurseId),
+		RevocationReason: ptr("withdrawn by the patient"),
+	}
+}
+
+// fakeRepositories implements every repository of the routes, it keeps what
+// the handlers wrote so the tests can check the requests were read.
+type fakeRepositories struct {
+	createdPatient *models.PatientRegistrationPayload
+	allergy        *models.AllergyRegistrationPayload
+	condition      *models.ConditionRegistrationPayload
+	consent        *models.ConsentRegistrationPayload
+}
+
+func (r *fakeRepositories) GetPatient(ctx context.Context, patientIdentityNumber int64) (string, error) {
+	if patientIdentityNumber == testNewIdentityNumber {
+		return "", pgx.ErrNoRows
+	}
+	return "patient one", nil
+}
+
+func (r *fakeRepositories) CreatePatient(ctx context.Context, patient *models.PatientRegistrationPayload) error {
+	r.createdPatient = patient
+	return nil
+}
+
+func (r *fakeRepositories) GetPatients(ctx context.Context, filter models.GetPatientQueries) ([]models.GetPatientResponse, error) {
+	return []models.GetPatientResponse{testPatient(testIdentityNumber)}, nil
+}
+
+func (r *fakeRepositories) SearchPatients(ctx context.Context, filter models.SearchPatientQueries) ([]models.SearchPatientResponse, int64, error) {
+	// one more than the limit so a next cursor is sent
+	return []models.SearchPatientResponse{
+		{GetPatientResponse: testPatient(testIdentityNumber), Score: 0.9},
+		{GetPatientResponse: testPatient(testNewIdentityNumber), Score: 0.5},
+	}, 2, nil
+}
+
+func (r *fakeRepositories) GetIdentityCardKey(ctx context.Context, patientIdentityNumber int64, thumbnail bool) (string, error) {
+	return testScanKey, nil
+}
+
+func (r *fakeRepositories) CreateIdentityCardAccessLog(ctx context.Context, accessLog models.IdentityCardAccessLog) error {
+	return nil
+}
+
+func (r *fakeRepositories) GetAllergyBySubstance(ctx context.Context, identityNumber int64, substance string) (string, error) {
+	return "", pgx.ErrNoRows
+}
+
+func (r *fakeRepositories) CreateAllergy(ctx context.Context, identityNumber int64, allergy *models.AllergyRegistrationPayload, recordedBy string) (string, error) {
+	r.allergy = allergy
+	return "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a009", nil
+}
+
+func (r *fakeRepositories) GetAllergies(ctx context.Context, identityNumber int64) ([]models.GetAllergyResponse, error) {
+	return []models.GetAllergyResponse{{
+		ID:               "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a009",
+		IdentityNumber:   identityNumber,
+		Substance:        "penicillin",
+		Reaction:         "rash",
+		Severity:         "moderate",
+		RecordedByUserId: testNurseId,
+		CreatedAt:        testTime,
+	}}, nil
+}
+
+func (r *fakeRepositories) CreateCondition(ctx context.Context, identityNumber int64, condition *models.ConditionRegistrationPayload, recordedBy string) (string, error) {
+	r.condition = condition
+	return testConditionId, nil
+}
+
+func (r *fakeRepositories) UpdateConditionStatus(ctx context.Context, identityNumber int64, conditionId string, status string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (r *fakeRepositories) GetConditions(ctx context.Context, identityNumber int64, filter models.GetConditionQueries) ([]models.GetConditionResponse, error) {
+	return []models.GetConditionResponse{{
+		ID:               testConditionId,
+		IdentityNumber:   identityNumber,
+		Condition:        "hypertension",
+		OnsetDate:        "2020-01-01",
+		Status:           "active",
+		RecordedByUserId: testNurseId,
+		CreatedAt:        testTime,
+	}}, nil
+}
+
+func (r *fakeRepositories) CreateConsent(ctx context.Context, identityNumber int64, consent *models.ConsentRegistrationPayload, recordedBy string) (string, error) {
+	r.consent = consent
+	return testConsentId, nil
+}
+
+func (r *fakeRepositories) GetConsents(ctx context.Context, identityNumber int64, filter models.GetConsentQueries) ([]models.GetConsentResponse, error) {
+	return []models.GetConsentResponse{testConsent()}, nil
+}
+
+func (r *fakeRepositories) GetCurrentConsent(ctx context.Context, identityNumber int64, consentType string) (*models.GetConsentResponse, error) {
+	// the latest consent is a grant, not revoked
+	consent := testConsent()
+	consent.RevokedAt, consent.RevokedByUserId, consent.RevocationReason = nil, nil, nil
+	return &consent, nil
+}
+
+func (r *fakeRepositories) RevokeConsent(ctx context.Context, identityNumber int64, consentId string, revokedBy string, reason string) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+// signingStore is a local store handing out URLs, like the S3 store does.
+type signingStore struct {
+	*storage.LocalStore
+}
+
+func (s signingStore) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "https://storage.example.com/" + key + "?signature=test", nil
+}
+
+func testScan(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 240, 240))
+	for x := 0; x < 240; x++ {
+		for y := 0; y < 240; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode scan : %+v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func testToken(t *testing.T, userId string, role string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"id":      userId,
+		"role":    role,
+		"expires": time.Now().Add(time.Hour).Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token : %+v", err)
+	}
+
+	return token
+}
+
+// startServer serves the routes on a random port and returns a strict client
+// of it, calling as a nurse.
+func startServer(t *testing.T, repos *fakeRepositories) *patient.Client {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	local, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store : %+v", err)
+	}
+	scan := testScan(t)
+	if err := local.Put(context.Background(), testScanKey, bytes.NewReader(scan), int64(len(scan)), "image/png"); err != nil {
+		t.Fatalf("failed to store scan : %+v", err)
+	}
+
+	s := NewServer(nil, signingStore{local}, nil, config.Config{IdentityCardUrlExpiry: 300})
+	s.registerRoutes(Repositories{repos, repos, repos})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen : %+v", err)
+	}
+	go s.app.Listener(ln)
+	t.Cleanup(func() { s.app.Shutdown() })
+
+	clientConfig := httpclient.DefaultConfig("http://" + ln.Addr().String())
+	clientConfig.MaxRetries = 0
+
+	return patient.New(api.NewClient(httpclient.New(clientConfig)).Strict()).WithToken(testToken(t, testNurseId, "nurse"))
+}
+
+func TestPatientContract(t *testing.T) {
+	repos := &fakeRepositories{}
+	client := startServer(t, repos)
+	ctx := context.Background()
+
+	t.Run("RegisterPatient", func(t *testing.T) {
+		registered, err := client.RegisterPatient(ctx, patient.PatientRegistration{
+			IdentityNumber:      testNewIdentityNumber,
+			PhoneNumber:         "+6281234567890",
+			Name:                "patient two",
+			BirthDate:           "1990-01-01T00:00:00Z",
+			Gender:              "male",
+			IdentityCardScanImg: api.File{Name: "scan.png", ContentType: "image/png", Content: testScan(t)},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, registered)
+		sdktest.RequireFilled(t, repos.createdPatient)
+	})
+
+	t.Run("GetPatients", func(t *testing.T) {
+		patients, err := client.GetPatients(ctx, patient.GetPatientsQuery{IdentityNumber: testIdentityNumber})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, patients)
+	})
+
+	t.Run("SearchPatients", func(t *testing.T) {
+		patients, meta, err := client.SearchPatients(ctx, patient.SearchPatientsQuery{Name: "patient", Limit: 1})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, patients)
+		sdktest.RequireFilled(t, meta)
+	})
+
+	t.Run("GetIdentityCard", func(t *testing.T) {
+		card, err := client.GetIdentityCard(ctx, testIdentityNumber, patient.IdentityCardOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, card, "Scan")
+	})
+
+	t.Run("GetIdentityCard stream", func(t *testing.T) {
+		card, err := client.GetIdentityCard(ctx, testIdentityNumber, patient.IdentityCardOptions{Stream: true})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+		if card.Scan == nil || !strings.HasPrefix(card.Scan.ContentType, "image/png") {
+			t.Fatalf("got %+v, want the scan", card)
+		}
+	})
+
+	t.Run("RegisterAllergy", func(t *testing.T) {
+		allergy, err := client.RegisterAllergy(ctx, testIdentityNumber, patient.AllergyRegistration{
+			Substance: "penicillin",
+			Reaction:  "rash",
+			Severity:  "moderate",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, allergy)
+		sdktest.RequireFilled(t, repos.allergy)
+	})
+
+	t.Run("GetAllergies", func(t *testing.T) {
+		allergies, err := client.GetAllergies(ctx, testIdentityNumber)
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, allergies)
+	})
+
+	t.Run("RegisterCondition", func(t *testing.T) {
+		condition, err := client.RegisterCondition(ctx, testIdentityNumber, patient.ConditionRegistration{
+			Condition: "hypertension",
+			OnsetDate: "2020-01-01",
+			Status:    "active",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, condition)
+		sdktest.RequireFilled(t, repos.condition)
+	})
+
+	t.Run("GetConditions", func(t *testing.T) {
+		conditions, err := client.GetConditions(ctx, testIdentityNumber, "active")
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, conditions)
+	})
+
+	t.Run("UpdateCondition", func(t *testing.T) {
+		if err := client.UpdateCondition(ctx, testIdentityNumber, testConditionId, "resolved"); err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+	})
+
+	t.Run("RegisterConsent", func(t *testing.T) {
+		consent, err := client.RegisterConsent(ctx, testIdentityNumber, patient.ConsentRegistration{
+			ConsentType: patient.ConsentTypeTreatment,
+			Version:     "v1",
+			Granted:     true,
+			WitnessName: "witness one",
+			SignedAt:    testTime,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, consent)
+		sdktest.RequireFilled(t, repos.consent)
+	})
+
+	t.Run("GetConsents", func(t *testing.T) {
+		consents, err := client.GetConsents(ctx, testIdentityNumber, patient.GetConsentsQuery{IncludeRevoked: true})
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, consents)
+	})
+
+	t.Run("CheckConsent", func(t *testing.T) {
+		check, err := client.CheckConsent(ctx, testIdentityNumber, patient.ConsentTypeTreatment)
+		if err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+
+		sdktest.RequireFilled(t, check)
+	})
+
+	t.Run("RevokeConsent", func(t *testing.T) {
+		if err := client.RevokeConsent(ctx, testIdentityNumber, testConsentId, "withdrawn by the patient"); err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+	})
+}
+
+func TestPatientClientErrors(t *testing.T) {
+	client := startServer(t, &fakeRepositories{})
+
+	_, err := client.GetAllergies(context.Background(), testNewIdentityNumber)
+	if api.StatusCode(err) != http.StatusNotFound || api.Code(err) != responses.CodePatientNotFound {
+		t.Errorf("got error %v, want a 404 %s for an unknown patient", err, responses.CodePatientNotFound)
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+)
+
+// StartReencryption moves the phone numbers still in plaintext or under an
+// old master key to the active one in the background.
+func (s *Server) StartReencryption(ctx context.Context) {
+	repo := repositories.NewEncryptionRepo(s.dbPool, s.keyring)
+	interval := time.Duration(s.config.ReencryptInterval) * time.Second
+	limit := s.config.ReencryptBatchSize
+
+	go envelope.RunReencryption(ctx, "patients", interval, func(ctx context.Context) (int, error) {
+		return repo.ReencryptPatients(ctx, limit)
+	})
+}
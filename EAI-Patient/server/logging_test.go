@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+)
+
+// lockedBuffer is written by the server and read by the test.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRequestLog(t *testing.T) {
+	var out lockedBuffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(logging.New(&out, "patient", slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	client := startServer(t, &fakeRepositories{})
+
+	_, err := client.GetAllergies(context.Background(), testNewIdentityNumber)
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.RequestId == "" {
+		t.Fatalf("got error %v, want a problem with a request id", err)
+	}
+
+	var line map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("got line %q, not JSON : %+v", raw, err)
+		}
+		if line["msg"] == "request" {
+			break
+		}
+	}
+
+	if line["msg"] != "request" || line["level"] != "WARN" || line["status"] != float64(404) {
+		t.Errorf("got line %v, want the request logged as a warning", line)
+	}
+	if line["requestId"] != apiErr.RequestId || line["service"] != "patient" {
+		t.Errorf("got line %v, want request id %s", line, apiErr.RequestId)
+	}
+	if strings.Contains(out.String(), strconv.FormatInt(testNewIdentityNumber, 10)) {
+		t.Errorf("got logs %s, the identity number in the path leaked", out.String())
+	}
+}
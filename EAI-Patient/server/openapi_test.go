@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ravenocx/hospital-mgt/config"
+	"github.com/ravenocx/hospital-mgt/openapi"
+	"github.com/ravenocx/hospital-mgt/responses"
+)
+
+var routeParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// TestOpenAPICoversRoutes fails when a route is registered without being in
+// the OpenAPI document, or the other way around.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load the OpenAPI document : %+v", err)
+	}
+
+	s := NewServer(nil, nil, nil, config.Config{})
+	repos := &fakeRepositories{}
+	s.registerRoutes(Repositories{repos, repos, repos})
+
+	registered := map[string]bool{}
+	for _, route := range s.app.GetRoutes(true) {
+		if route.Method == http.MethodHead {
+			continue
+		}
+
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		if len(path) > 1 {
+			path = strings.TrimRight(path, "/")
+		}
+		registered[route.Method+" "+path] = true
+
+		item := doc.Paths.Value(path)
+		if item == nil || item.GetOperation(route.Method) == nil {
+			t.Errorf("%s %s is registered but missing from the OpenAPI document", route.Method, path)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !registered[method+" "+path] {
+				t.Errorf("%s %s is in the OpenAPI document but not registered", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	s := NewServer(nil, nil, nil, config.Config{})
+	repos := &fakeRepositories{}
+	s.registerRoutes(Repositories{repos, repos, repos})
+	token := testToken(t, testNurseId, "nurse")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"identity number not a number", http.MethodGet, "/v1/medical/patient/abc/allergy", "", http.StatusBadRequest},
+		{"unknown severity", http.MethodPost, "/v1/medical/patient/3201234567890001/allergy", `{"substance": "penicillin", "reaction": "rash", "severity": "deadly"}`, http.StatusBadRequest},
+		{"granted as a string", http.MethodPost, "/v1/medical/patient/3201234567890001/consent", `{"consentType": "treatment", "version": "1", "granted": "yes", "witnessName": "witness one"}`, http.StatusBadRequest},
+		{"missing consent type", http.MethodGet, "/v1/medical/patient/3201234567890001/consent/check", "", http.StatusBadRequest},
+		{"unknown sort direction", http.MethodGet, "/v1/medical/patient?createdAt=sideways", "", http.StatusBadRequest},
+		{"sort direction in upper case", http.MethodGet, "/v1/medical/patient?createdAt=ASC", "", http.StatusOK},
+		{"valid", http.MethodGet, "/v1/medical/patient/search?limit=20", "", http.StatusOK},
+		{"document", http.MethodGet, "/openapi.json", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := s.app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+// TestOpenAPIValidationAfterAuthentication checks the invalid requests of
+// callers without a valid token are turned away by the authentication, before
+// the validator describes what is wrong with them.
+func TestOpenAPIValidationAfterAuthentication(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+
+	s := NewServer(nil, nil, nil, config.Config{})
+	repos := &fakeRepositories{}
+	s.registerRoutes(Repositories{repos, repos, repos})
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+		code  string
+	}{
+		{"no token", "", http.StatusBadRequest, responses.CodeMissingToken},
+		{"invalid token", "not-a-token", http.StatusUnauthorized, responses.CodeInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/medical/patient/3201234567890001/allergy", strings.NewReader(`{"substance": "penicillin", "reaction": "rash", "severity": "deadly"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := s.app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+
+			var problem struct {
+				Code string `json:"code"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("failed to decode the error : %+v", err)
+			}
+			if resp.StatusCode != tt.want || problem.Code != tt.code {
+				t.Errorf("got %d %s, want %d %s", resp.StatusCode, problem.Code, tt.want, tt.code)
+			}
+		})
+	}
+}
@@ -1,25 +1,80 @@
 package server
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/controller"
 	"github.com/ravenocx/hospital-mgt/middleware"
 	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
 	"github.com/ravenocx/hospital-mgt/service"
 )
 
+// Repositories are the data access of the routes, on the database in the
+// service and faked in the contract tests.
+type Repositories struct {
+	Patient  repositories.PatientRepositories
+	Registry repositories.RegistryRepositories
+	Consent  repositories.ConsentRepositories
+}
+
+func NewRepositories(db *pgxpool.Pool, keyring *envelope.Keyring) Repositories {
+	return Repositories{
+		Patient:  repositories.NewPatientRepo(db, keyring),
+		Registry: repositories.NewRegistryRepo(db),
+		Consent:  repositories.NewConsentRepo(db),
+	}
+}
+
 func (s *Server) RegisterRoute() {
+	s.registerRoutes(NewRepositories(s.dbPool, s.keyring))
+}
+
+func (s *Server) registerRoutes(repos Repositories) {
 	mainRoute := s.app.Group("/v1")
 
-	PatientRoute(mainRoute, s.dbPool)
+	PatientRoute(mainRoute, repos, s.store, s.config, s.validate)
 }
 
-func PatientRoute(r fiber.Router, db *pgxpool.Pool) {
-	c := controller.NewUserController(service.NewUserService(repositories.NewPatientRepo(db)))
+func PatientRoute(r fiber.Router, repos Repositories, store storage.BlobStore, config config.Config, validate fiber.Handler) {
+	c := controller.NewUserController(service.NewUserService(repos.Patient, store, time.Duration(config.IdentityCardUrlExpiry)*time.Second))
 
 	medicalRoute := r.Group("/medical")
 
-	medicalRoute.Post("/patient", middleware.JWTProtected(), middleware.UserAuth(), c.RegisterPatient)
-	medicalRoute.Get("/patient", middleware.JWTProtected(), middleware.UserAuth(), c.GetPatient)
+	medicalRoute.Post("/patient", middleware.JWTProtected(), middleware.UserAuth(), validate, c.RegisterPatient)
+	medicalRoute.Get("/patient", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetPatient)
+	medicalRoute.Get("/patient/search", middleware.JWTProtected(), middleware.UserAuth(), validate, c.SearchPatients)
+	medicalRoute.Get("/patient/:identityNumber/identity-card", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetIdentityCard)
+
+	RegistryRoute(medicalRoute, repos, validate)
+	ConsentRoute(medicalRoute, repos, validate)
+}
+
+func RegistryRoute(r fiber.Router, repos Repositories, validate fiber.Handler) {
+	c := controller.NewRegistryController(service.NewRegistryService(repos.Registry, repos.Patient))
+
+	patientRoute := r.Group("/patient/:identityNumber")
+
+	patientRoute.Post("/allergy", middleware.JWTProtected(), middleware.UserAuth(), validate, c.RegisterAllergy)
+	patientRoute.Get("/allergy", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetAllergies)
+	patientRoute.Post("/condition", middleware.JWTProtected(), middleware.UserAuth(), validate, c.RegisterCondition)
+	patientRoute.Get("/condition", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetConditions)
+	patientRoute.Put("/condition/:conditionId", middleware.JWTProtected(), middleware.UserAuth(), validate, c.UpdateCondition)
+}
+
+func ConsentRoute(r fiber.Router, repos Repositories, validate fiber.Handler) {
+	c := controller.NewConsentController(service.NewConsentService(repos.Consent, repos.Patient))
+
+	consentRoute := r.Group("/patient/:identityNumber/consent")
+
+	// used by other services to check the consent before acting on patient data
+	consentRoute.Get("/check", middleware.JWTProtected(), middleware.UserAuth(), validate, c.CheckConsent)
+
+	consentRoute.Post("/", middleware.JWTProtected(), middleware.UserAuth(), validate, c.RegisterConsent)
+	consentRoute.Get("/", middleware.JWTProtected(), middleware.UserAuth(), validate, c.GetConsents)
+	consentRoute.Post("/:consentId/revoke", middleware.JWTProtected(), middleware.UserAuth(), validate, c.RevokeConsent)
 }
@@ -1,23 +1,37 @@
 package server
 
 import (
-	"log"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ravenocx/hospital-mgt/config"
 	"github.com/ravenocx/hospital-mgt/middleware"
+	"github.com/ravenocx/hospital-mgt/openapi"
+	"github.com/ravenocx/hospital-mgt/sdk/envelope"
+	"github.com/ravenocx/hospital-mgt/sdk/imageproc"
+	"github.com/ravenocx/hospital-mgt/sdk/logging"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
 )
 
 type Server struct {
-	dbPool *pgxpool.Pool
-	app    *fiber.App
+	dbPool  *pgxpool.Pool
+	store   storage.BlobStore
+	keyring *envelope.Keyring
+	config  config.Config
+	app     *fiber.App
+
+	// validates the requests against the OpenAPI document, placed after
+	// the authentication so only authenticated callers see the schema errors
+	validate fiber.Handler
 }
 
-func NewServer(db *pgxpool.Pool, config config.Config) *Server {
+func NewServer(db *pgxpool.Pool, store storage.BlobStore, keyring *envelope.Keyring, config config.Config) *Server {
 	fiberConfig := fiber.Config{
 		ReadTimeout: time.Duration(config.ServerReadTimeout) * time.Second,
+		ErrorHandler: middleware.ErrorHandler,
+		// leave room for the largest accepted image plus the other form fields
+		BodyLimit: int(imageproc.DefaultLimits.MaxBytes) + 1<<20,
 	}
 
 	app := fiber.New(fiberConfig)
@@ -28,14 +42,32 @@ func NewServer(db *pgxpool.Pool, config config.Config) *Server {
 
 	middleware.FiberMiddleware(app)
 
+	doc, err := openapi.Load()
+	if err != nil {
+		logging.Fatal("Failed to load the OpenAPI document", "error", err)
+	}
+
+	validate, err := middleware.OpenAPIValidator(doc)
+	if err != nil {
+		logging.Fatal("Failed to create the OpenAPI validator", "error", err)
+	}
+
+	app.Get("/openapi.json", func(c *fiber.Ctx) error {
+		return c.JSON(doc)
+	})
+
 	return &Server{
 		dbPool: db,
+		store:  store,
+		keyring: keyring,
+		config: config,
 		app : app,
+		validate: validate,
 	}
 }
 
 func (s *Server) StarApp(config config.Config) {
 	if err := s.app.Listen(config.ServerHost + ":" + config.ServerPort); err != nil {
-		log.Fatalf("Oops... Server is not running! Reason: %v", err)
+		logging.Fatal("Oops... Server is not running!", "error", err)
 	}
 }
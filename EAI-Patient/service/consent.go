@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type ConsentService interface {
+	RegisterConsent(ctx context.Context, identityNumber int64, newConsent models.ConsentRegistrationPayload, recordedBy string) (string, responses.CustomError)
+	GetConsents(ctx context.Context, identityNumber int64, filter models.GetConsentQueries) ([]models.GetConsentResponse, responses.CustomError)
+	RevokeConsent(ctx context.Context, identityNumber int64, consentId string, revocation models.ConsentRevocationPayload, revokedBy string) responses.CustomError
+	CheckConsent(ctx context.Context, identityNumber int64, consentType string) (*models.ConsentCheckResponse, responses.CustomError)
+}
+
+type consentService struct {
+	repo        repositories.ConsentRepositories
+	patientRepo repositories.PatientRepositories
+}
+
+func NewConsentService(repo repositories.ConsentRepositories, patientRepo repositories.PatientRepositories) ConsentService {
+	return &consentService{repo, patientRepo}
+}
+
+func (s *consentService) RegisterConsent(ctx context.Context, identityNumber int64, newConsent models.ConsentRegistrationPayload, recordedBy string) (string, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&newConsent); err != nil {
+		return "", responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return "", custErr
+	}
+
+	id, err := s.repo.CreateConsent(ctx, identityNumber, &newConsent, recordedBy)
+	if err != nil {
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to create consent : %+v", err.Error()))
+	}
+
+	return id, responses.CustomError{}
+}
+
+func (s *consentService) GetConsents(ctx context.Context, identityNumber int64, filter models.GetConsentQueries) ([]models.GetConsentResponse, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&filter); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	consents, err := s.repo.GetConsents(ctx, identityNumber, filter)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get consents : %+v", err.Error()))
+	}
+
+	return consents, responses.CustomError{}
+}
+
+func (s *consentService) RevokeConsent(ctx context.Context, identityNumber int64, consentId string, revocation models.ConsentRevocationPayload, revokedBy string) responses.CustomError {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&revocation); err != nil {
+		return responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, err := uuid.Parse(consentId); err != nil {
+		return responses.NewNotFoundError("consent not found or consentId is not in valid format").WithCode(responses.CodeConsentNotFound)
+	}
+
+	res, err := s.repo.RevokeConsent(ctx, identityNumber, consentId, revokedBy, revocation.Reason)
+	if err != nil {
+		return responses.NewInternalServerError(fmt.Sprintf("failed to revoke consent : %+v", err.Error()))
+	}
+
+	if res.RowsAffected() == 0 {
+		return responses.NewNotFoundError("consent not found or already revoked").WithCode(responses.CodeConsentNotFound)
+	}
+
+	return responses.CustomError{}
+}
+
+// CheckConsent is meant to be called by other services before they act on the
+// patient data. A patient without any consent of the given type, or whose
+// latest consent is revoked, is treated as not granted.
+func (s *consentService) CheckConsent(ctx context.Context, identityNumber int64, consentType string) (*models.ConsentCheckResponse, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Var(consentType, "required,oneof='treatment' 'data_sharing' 'research' 'sms_contact'"); err != nil {
+		// a validated variable has no name, give it the one of the query param
+		fields := utils.ValidatorErrors(err)
+		for i := range fields {
+			fields[i].Field = "consentType"
+		}
+		return nil, responses.NewValidationError("query params doesn't meet requirement", fields)
+	}
+
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	check := &models.ConsentCheckResponse{
+		IdentityNumber: identityNumber,
+		ConsentType:    consentType,
+	}
+
+	consent, err := s.repo.GetCurrentConsent(ctx, identityNumber, consentType)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return check, responses.CustomError{}
+		}
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get consent : %+v", err.Error()))
+	}
+
+	check.Granted = consent.Granted && consent.RevokedAt == nil
+	check.ConsentId = &consent.ID
+	check.Version = &consent.Version
+	check.SignedAt = &consent.SignedAt
+
+	return check, responses.CustomError{}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+)
+
+const (
+	testIdentityNumber = int64(3201234567890001)
+	testNurseId        = "8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a004"
+)
+
+// memoryConsents keeps the consents in signing order and answers like the
+// queries of the repository do.
+type memoryConsents struct {
+	repositories.PatientRepositories
+	consents []models.GetConsentResponse
+}
+
+func (r *memoryConsents) GetPatient(ctx context.Context, identityNumber int64) (string, error) {
+	return "patient one", nil
+}
+
+func (r *memoryConsents) CreateConsent(ctx context.Context, identityNumber int64, consent *models.ConsentRegistrationPayload, recordedBy string) (string, error) {
+	id := fmt.Sprintf("8d7f7a0e-5f0b-4c43-9a43-6f1de8f6a1%02d", len(r.consents))
+	signedAt := time.Date(2024, 5, 1, 8, len(r.consents), 0, 0, time.UTC).Format(time.RFC3339Nano)
+	r.consents = append(r.consents, models.GetConsentResponse{
+		ID:             id,
+		IdentityNumber: identityNumber,
+		ConsentType:    consent.ConsentType,
+		Version:        consent.Version,
+		Granted:        *consent.Granted,
+		SignedAt:       signedAt,
+	})
+
+	return id, nil
+}
+
+func (r *memoryConsents) GetConsents(ctx context.Context, identityNumber int64, filter models.GetConsentQueries) ([]models.GetConsentResponse, error) {
+	return r.consents, nil
+}
+
+func (r *memoryConsents) GetCurrentConsent(ctx context.Context, identityNumber int64, consentType string) (*models.GetConsentResponse, error) {
+	for i := len(r.consents) - 1; i >= 0; i-- {
+		if r.consents[i].ConsentType == consentType {
+			consent := r.consents[i]
+			return &consent, nil
+		}
+	}
+
+	return nil, pgx.ErrNoRows
+}
+
+func (r *memoryConsents) RevokeConsent(ctx context.Context, identityNumber int64, consentId string, revokedBy string, reason string) (pgconn.CommandTag, error) {
+	for i := range r.consents {
+		if r.consents[i].ID == consentId && r.consents[i].RevokedAt == nil {
+			revokedAt := time.Now().Format(time.RFC3339Nano)
+			r.consents[i].RevokedAt = &revokedAt
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		}
+	}
+
+	return pgconn.NewCommandTag("UPDATE 0"), nil
+}
+
+func TestCheckConsentAfterRevokingTheLatest(t *testing.T) {
+	repo := &memoryConsents{}
+	s := NewConsentService(repo, repo)
+	ctx := context.Background()
+	granted := true
+
+	grant := func(version string) string {
+		t.Helper()
+		id, custErr := s.RegisterConsent(ctx, testIdentityNumber, models.ConsentRegistrationPayload{
+			ConsentType: "treatment",
+			Version:     version,
+			Granted:     &granted,
+			WitnessName: "witness one",
+		}, testNurseId)
+		if custErr.Message != "" {
+			t.Fatalf("failed to register consent : %+v", custErr)
+		}
+		return id
+	}
+
+	check := func() *models.ConsentCheckResponse {
+		t.Helper()
+		check, custErr := s.CheckConsent(ctx, testIdentityNumber, "treatment")
+		if custErr.Message != "" {
+			t.Fatalf("failed to check consent : %+v", custErr)
+		}
+		return check
+	}
+
+	grant("v1")
+	latest := grant("v2")
+	if c := check(); !c.Granted || *c.ConsentId != latest {
+		t.Fatalf("got %+v, want the v2 consent granted", c)
+	}
+
+	if custErr := s.RevokeConsent(ctx, testIdentityNumber, latest, models.ConsentRevocationPayload{Reason: "withdrawn by the patient"}, testNurseId); custErr.Message != "" {
+		t.Fatalf("failed to revoke consent : %+v", custErr)
+	}
+
+	// the v1 grant is superseded, it doesn't come back
+	if c := check(); c.Granted || *c.ConsentId != latest {
+		t.Errorf("got %+v, want the revoked v2 consent not granted", c)
+	}
+}
@@ -2,33 +2,44 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/ravenocx/hospital-mgt/models"
 	"github.com/ravenocx/hospital-mgt/repositories"
 	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/imageproc"
+	"github.com/ravenocx/hospital-mgt/sdk/storage"
 	"github.com/ravenocx/hospital-mgt/utils"
 )
 
 type PatientService interface {
 	RegisterPatient(ctx context.Context, newPatient models.PatientRegistrationPayload) responses.CustomError
 	GetPatient(ctx context.Context, GetPatientQueries models.GetPatientQueries) ([]models.GetPatientResponse, responses.CustomError)
+	SearchPatients(ctx context.Context, searchQueries models.SearchPatientQueries) ([]models.SearchPatientResponse, models.SearchPatientMeta, responses.CustomError)
+	GetIdentityCard(ctx context.Context, identityNumber int64, access models.IdentityCardAccess) (*models.IdentityCard, responses.CustomError)
 }
 
 type patientService struct {
-	repo repositories.PatientRepositories
+	repo      repositories.PatientRepositories
+	store     storage.BlobStore
+	urlExpiry time.Duration
 }
 
-func NewUserService(repo repositories.PatientRepositories) PatientService {
-	return &patientService{repo}
+func NewUserService(repo repositories.PatientRepositories, store storage.BlobStore, urlExpiry time.Duration) PatientService {
+	return &patientService{repo, store, urlExpiry}
 }
 
 func (s *patientService) RegisterPatient(ctx context.Context, newPatient models.PatientRegistrationPayload) responses.CustomError {
 	validate := utils.NewValidator()
 
 	if err := validate.Struct(&newPatient); err != nil {
-		return responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+		return responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
 	}
 
 	existingPatient, err := s.repo.GetPatient(ctx, newPatient.IdentityNumber)
@@ -39,15 +50,19 @@ func (s *patientService) RegisterPatient(ctx context.Context, newPatient models.
 	}
 
 	if existingPatient != "" {
-		return responses.NewConflictError("patient with identity number provided is already exists")
+		return responses.NewConflictError("patient with identity number provided is already exists").WithCode(responses.CodePatientConflict)
 	}
 
-	urlImage, err := utils.UploadImage(newPatient.IdentityCardScanImg)
+	image, err := utils.UploadImage(ctx, s.store, newPatient.IdentityCardScanImg)
 	if err != nil {
+		if errors.Is(err, imageproc.ErrInvalidImage) {
+			return responses.NewBadRequestError(err.Error()).WithCode(responses.CodeInvalidImage)
+		}
 		return responses.NewInternalServerError(fmt.Sprintf("failed to upload image : %+v", err.Error()))
 	}
 
-	newPatient.IdentityCardScanImgString = urlImage
+	newPatient.IdentityCardScanImgString = image.Key
+	newPatient.IdentityCardThumbnailImg = image.ThumbnailKey
 
 	err = s.repo.CreatePatient(ctx, &newPatient)
 	if err != nil {
@@ -63,7 +78,7 @@ func (s *patientService) GetPatient(ctx context.Context, GetPatientQueries model
 
 	if GetPatientQueries.IdentityNumber != nil{
 		if err := validate.Struct(&GetPatientQueries); err != nil {
-			return nil, responses.NewBadRequestError(fmt.Sprintf("payload request doesn't meet requirement : %+v", err.Error()))
+			return nil, responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
 		}
 	}
 	patients, err := s.repo.GetPatients(ctx, GetPatientQueries)
@@ -76,3 +91,153 @@ func (s *patientService) GetPatient(ctx context.Context, GetPatientQueries model
 
 	return patients, responses.CustomError{}
 }
+
+func (s *patientService) SearchPatients(ctx context.Context, searchQueries models.SearchPatientQueries) ([]models.SearchPatientResponse, models.SearchPatientMeta, responses.CustomError) {
+	meta := models.SearchPatientMeta{Limit: searchQueries.Limit}
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&searchQueries); err != nil {
+		return nil, meta, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if searchQueries.Cursor != "" {
+		after, err := decodeSearchCursor(searchQueries.Cursor)
+		if err != nil {
+			return nil, meta, responses.NewBadRequestError("cursor is not in valid format").WithCode(responses.CodeInvalidCursor)
+		}
+		searchQueries.After = after
+	}
+
+	patients, total, err := s.repo.SearchPatients(ctx, searchQueries)
+	if err != nil {
+		return nil, meta, responses.NewInternalServerError(fmt.Sprintf("failed to search patients : %+v", err.Error()))
+	}
+
+	meta.Total = total
+
+	if len(patients) > searchQueries.Limit {
+		patients = patients[:searchQueries.Limit]
+		last := patients[len(patients)-1]
+
+		nextCursor, err := encodeSearchCursor(models.PatientSearchCursor{
+			Score:          last.Score,
+			CreatedAt:      last.CreatedAt,
+			IdentityNumber: last.IdentityNumber,
+		})
+		if err != nil {
+			return nil, meta, responses.NewInternalServerError(fmt.Sprintf("failed to encode cursor : %+v", err.Error()))
+		}
+		meta.NextCursor = &nextCursor
+	}
+
+	return patients, meta, responses.CustomError{}
+}
+
+// GetIdentityCard hands out a short-lived signed URL to the identity card scan
+// when the store supports it, otherwise (or when access.Stream is set) the
+// content itself. Every access is written to the audit log before anything is
+// returned.
+func (s *patientService) GetIdentityCard(ctx context.Context, identityNumber int64, access models.IdentityCardAccess) (*models.IdentityCard, responses.CustomError) {
+	if access.Role != "admin" && access.Role != "nurse" {
+		return nil, responses.NewForbiddenError("only admin and nurse can see identity card scans")
+	}
+
+	key, err := s.repo.GetIdentityCardKey(ctx, identityNumber, access.Thumbnail)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, responses.NewNotFoundError("patient with identity number provided is not exist").WithCode(responses.CodePatientNotFound)
+		}
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get identity card : %+v", err.Error()))
+	}
+
+	if key == "" {
+		return nil, responses.NewNotFoundError("identity card thumbnail is not available for this patient").WithCode(responses.CodeIdentityCardNotFound)
+	}
+
+	signer, canSign := s.store.(storage.URLSigner)
+	accessMode := "stream"
+	if canSign && !access.Stream {
+		accessMode = "signed_url"
+	}
+
+	// scans uploaded before the blob store are public URLs, they can only be
+	// handed out as they are
+	legacyURL := strings.HasPrefix(key, "http://") || strings.HasPrefix(key, "https://")
+	if legacyURL {
+		accessMode = "legacy_url"
+	}
+
+	err = s.repo.CreateIdentityCardAccessLog(ctx, models.IdentityCardAccessLog{
+		IdentityNumber: identityNumber,
+		UserId:         access.UserId,
+		Role:           access.Role,
+		AccessMode:     accessMode,
+		ClientIP:       access.ClientIP,
+	})
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to write identity card access log : %+v", err.Error()))
+	}
+
+	if legacyURL {
+		return &models.IdentityCard{URL: key}, responses.CustomError{}
+	}
+
+	if accessMode == "signed_url" {
+		signedURL, err := signer.SignedURL(ctx, key, s.urlExpiry)
+		if err != nil {
+			return nil, responses.NewInternalServerError(fmt.Sprintf("failed to sign identity card url : %+v", err.Error()))
+		}
+
+		expiresAt := time.Now().Add(s.urlExpiry).Format(time.RFC3339Nano)
+		return &models.IdentityCard{URL: signedURL, ExpiresAt: &expiresAt}, responses.CustomError{}
+	}
+
+	body, err := s.store.Get(ctx, key)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, responses.NewNotFoundError("identity card scan is not exist").WithCode(responses.CodeIdentityCardNotFound)
+		}
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to read identity card : %+v", err.Error()))
+	}
+
+	return &models.IdentityCard{Body: body, ContentType: storage.ContentType(key)}, responses.CustomError{}
+}
+
+func encodeSearchCursor(cursor models.PatientSearchCursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeSearchCursor(encoded string) (*models.PatientSearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor models.PatientSearchCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, cursor.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func checkPatientExists(ctx context.Context, repo repositories.PatientRepositories, identityNumber int64) responses.CustomError {
+	_, err := repo.GetPatient(ctx, identityNumber)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return responses.NewNotFoundError("patient with identity number provided is not exist").WithCode(responses.CodePatientNotFound)
+		}
+		return responses.NewInternalServerError(fmt.Sprintf("failed to check existing patient : %+v", err.Error()))
+	}
+
+	return responses.CustomError{}
+}
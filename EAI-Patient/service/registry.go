@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ravenocx/hospital-mgt/models"
+	"github.com/ravenocx/hospital-mgt/repositories"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/utils"
+)
+
+type RegistryService interface {
+	RegisterAllergy(ctx context.Context, identityNumber int64, newAllergy models.AllergyRegistrationPayload, recordedBy string) (string, responses.CustomError)
+	GetAllergies(ctx context.Context, identityNumber int64) ([]models.GetAllergyResponse, responses.CustomError)
+	RegisterCondition(ctx context.Context, identityNumber int64, newCondition models.ConditionRegistrationPayload, recordedBy string) (string, responses.CustomError)
+	UpdateCondition(ctx context.Context, identityNumber int64, conditionId string, updatePayload models.ConditionUpdatePayload) responses.CustomError
+	GetConditions(ctx context.Context, identityNumber int64, filter models.GetConditionQueries) ([]models.GetConditionResponse, responses.CustomError)
+}
+
+type registryService struct {
+	repo        repositories.RegistryRepositories
+	patientRepo repositories.PatientRepositories
+}
+
+func NewRegistryService(repo repositories.RegistryRepositories, patientRepo repositories.PatientRepositories) RegistryService {
+	return &registryService{repo, patientRepo}
+}
+
+func (s *registryService) RegisterAllergy(ctx context.Context, identityNumber int64, newAllergy models.AllergyRegistrationPayload, recordedBy string) (string, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&newAllergy); err != nil {
+		return "", responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return "", custErr
+	}
+
+	existingAllergy, err := s.repo.GetAllergyBySubstance(ctx, identityNumber, newAllergy.Substance)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			return "", responses.NewInternalServerError(fmt.Sprintf("failed to check existing allergy : %+v", err.Error()))
+		}
+	}
+
+	if existingAllergy != "" {
+		return "", responses.NewConflictError("allergy to the substance provided is already recorded").WithCode(responses.CodeAllergyConflict)
+	}
+
+	id, err := s.repo.CreateAllergy(ctx, identityNumber, &newAllergy, recordedBy)
+	if err != nil {
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to create allergy : %+v", err.Error()))
+	}
+
+	return id, responses.CustomError{}
+}
+
+func (s *registryService) GetAllergies(ctx context.Context, identityNumber int64) ([]models.GetAllergyResponse, responses.CustomError) {
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	allergies, err := s.repo.GetAllergies(ctx, identityNumber)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get allergies : %+v", err.Error()))
+	}
+
+	return allergies, responses.CustomError{}
+}
+
+func (s *registryService) RegisterCondition(ctx context.Context, identityNumber int64, newCondition models.ConditionRegistrationPayload, recordedBy string) (string, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&newCondition); err != nil {
+		return "", responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return "", custErr
+	}
+
+	id, err := s.repo.CreateCondition(ctx, identityNumber, &newCondition, recordedBy)
+	if err != nil {
+		return "", responses.NewInternalServerError(fmt.Sprintf("failed to create condition : %+v", err.Error()))
+	}
+
+	return id, responses.CustomError{}
+}
+
+func (s *registryService) UpdateCondition(ctx context.Context, identityNumber int64, conditionId string, updatePayload models.ConditionUpdatePayload) responses.CustomError {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&updatePayload); err != nil {
+		return responses.NewValidationError("payload request doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if _, err := uuid.Parse(conditionId); err != nil {
+		return responses.NewNotFoundError("condition not found or conditionId is not in valid format").WithCode(responses.CodeConditionNotFound)
+	}
+
+	res, err := s.repo.UpdateConditionStatus(ctx, identityNumber, conditionId, updatePayload.Status)
+	if err != nil {
+		return responses.NewInternalServerError(fmt.Sprintf("failed to update condition : %+v", err.Error()))
+	}
+
+	if res.RowsAffected() == 0 {
+		return responses.NewNotFoundError("condition not found").WithCode(responses.CodeConditionNotFound)
+	}
+
+	return responses.CustomError{}
+}
+
+func (s *registryService) GetConditions(ctx context.Context, identityNumber int64, filter models.GetConditionQueries) ([]models.GetConditionResponse, responses.CustomError) {
+	validate := utils.NewValidator()
+
+	if err := validate.Struct(&filter); err != nil {
+		return nil, responses.NewValidationError("query params doesn't meet requirement", utils.ValidatorErrors(err))
+	}
+
+	if custErr := checkPatientExists(ctx, s.patientRepo, identityNumber); (custErr != responses.CustomError{}) {
+		return nil, custErr
+	}
+
+	conditions, err := s.repo.GetConditions(ctx, identityNumber, filter)
+	if err != nil {
+		return nil, responses.NewInternalServerError(fmt.Sprintf("failed to get conditions : %+v", err.Error()))
+	}
+
+	return conditions, responses.CustomError{}
+}
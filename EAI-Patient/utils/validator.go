@@ -1,18 +1,34 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/ravenocx/hospital-mgt/responses"
+	"github.com/ravenocx/hospital-mgt/sdk/imageproc"
 )
 
 func NewValidator() *validator.Validate {
 	validate := validator.New()
 
+	// name the fields as in the JSON of the request
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
 	_ = validate.RegisterValidation("uuid", func(fl validator.FieldLevel) bool {
 		field := fl.Field().String()
 		if _, err := uuid.Parse(field); err != nil {
@@ -48,6 +64,19 @@ func NewValidator() *validator.Validate {
 		return regex.MatchString(field)
 	})
 
+	_ = validate.RegisterValidation("datetime", func(fl validator.FieldLevel) bool {
+		// datetime=<layout> parses with the layout given, the bare rule with
+		// the one the services parse the timestamps with, so a value without
+		// its UTC offset is turned away here and not in the handler
+		layout := fl.Param()
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+
+		_, err := time.Parse(layout, fl.Field().String())
+		return err == nil
+	})
+
 	_ = validate.RegisterValidation("identity_number", func(fl validator.FieldLevel) bool {
 		field := fl.Field().Int()
 
@@ -57,10 +86,19 @@ func NewValidator() *validator.Validate {
 	})
 
 	_ = validate.RegisterValidation("img_file", func(fl validator.FieldLevel) bool {
-		field := fl.Field().Interface()
+		// the validator hands over the dereferenced struct, take its address
+		// back to get the *multipart.FileHeader the form parser gave us
+		field := fl.Field()
+		if field.Kind() != reflect.Ptr && field.CanAddr() {
+			field = field.Addr()
+		}
+
+		fileHeader, ok := field.Interface().(*multipart.FileHeader)
+		if !ok || fileHeader == nil {
+			return false
+		}
 
-		fileHeader, ok := field.(multipart.FileHeader)
-		if !ok {
+		if fileHeader.Size > imageproc.DefaultLimits.MaxBytes {
 			return false
 		}
 
@@ -70,24 +108,67 @@ func NewValidator() *validator.Validate {
 		}
 		defer file.Close()
 
-		// Check file extension
-		ext := strings.ToLower(fileHeader.Filename[strings.LastIndex(fileHeader.Filename, ".")+1:])
-		if ext != "jpg" && ext != "jpeg" && ext != "png" {
+		// the content decides the type, not the file extension
+		header := make([]byte, 512)
+		n, err := io.ReadFull(file, header)
+		if err != nil && err != io.ErrUnexpectedEOF {
 			return false
 		}
 
-		return true
+		_, err = imageproc.Sniff(header[:n])
+		return err == nil
 	})
 
 	return validate
 }
 
-func ValidatorErrors(err error) map[string]string {
-	fields := map[string]string{}
+// ValidatorErrors lists the fields that failed validation, nil when err is
+// not a validation error.
+func ValidatorErrors(err error) []responses.FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
 
-	for _, err := range err.(validator.ValidationErrors) {
-		fields[err.Field()] = err.Error()
+	fields := make([]responses.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		// drop the name of the validated struct, keep the path to the field
+		field := fieldErr.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		fields = append(fields, responses.FieldError{
+			Field:  field,
+			Rule:   fieldErr.Tag(),
+			Detail: fieldDetail(fieldErr),
+		})
 	}
 
 	return fields
 }
+
+func fieldDetail(err validator.FieldError) string {
+	unit := ""
+	switch err.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch err.Tag() {
+	case "required", "required_if", "required_unless", "required_without":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", err.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", err.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", err.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(err.Param(), "'", ""))
+	}
+
+	return fmt.Sprintf("doesn't meet the %s rule", err.Tag())
+}
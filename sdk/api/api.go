@@ -0,0 +1,175 @@
+// Package api is what the clients of the services share: sending a call with
+// the bearer token, decoding the JSON body the services answer with and
+// turning the other answers into an *Error.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+)
+
+// ErrInvalidResponse is returned, wrapped, when a successful answer can't be
+// decoded into the type the client expects.
+var ErrInvalidResponse = errors.New("invalid response")
+
+type Client struct {
+	http   *httpclient.Client
+	token  string
+	strict bool
+}
+
+func NewClient(http *httpclient.Client) *Client {
+	return &Client{http: http}
+}
+
+// WithToken returns a copy of the client sending the token as bearer token.
+func (c *Client) WithToken(token string) *Client {
+	copy := *c
+	copy.token = token
+	return &copy
+}
+
+// Strict returns a copy of the client failing to decode an answer holding a
+// field the client doesn't know. The contract tests use it so a field added
+// by a service has to be added to its client too.
+func (c *Client) Strict() *Client {
+	copy := *c
+	copy.strict = true
+	return &copy
+}
+
+// Response is the body most calls are answered with.
+type Response[T any] struct {
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
+
+// Result is the body of the calls answering with the id of what they changed.
+type Result struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// Get sends a GET request and decodes the answer into v.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, v)
+}
+
+// Do sends the request with body encoded as JSON, a nil body sends none, and
+// decodes the answer into v.
+func (c *Client) Do(ctx context.Context, method string, path string, query url.Values, body interface{}, v interface{}) error {
+	var payload []byte
+	header := c.header()
+
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Raw(ctx, method, path, query, header, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Decode(resp, v)
+}
+
+// DoForm sends the form as multipart/form-data and decodes the answer into v.
+func (c *Client) DoForm(ctx context.Context, method string, path string, form *Form, v interface{}) error {
+	payload, contentType, err := form.encode()
+	if err != nil {
+		return err
+	}
+
+	header := c.header()
+	header.Set("Content-Type", contentType)
+
+	resp, err := c.Raw(ctx, method, path, nil, header, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Decode(resp, v)
+}
+
+// Raw sends the request as it is and returns the answer undecoded, for the
+// calls that can answer with something else than JSON. An answer other than
+// 2xx is returned as an *Error.
+func (c *Client) Raw(ctx context.Context, method string, path string, query url.Values, header http.Header, body []byte) (*httpclient.Response, error) {
+	if header == nil {
+		header = c.header()
+	}
+
+	resp, err := c.http.Do(ctx, method, path, query, header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newError(resp)
+	}
+
+	return resp, nil
+}
+
+// Decode decodes the JSON body of the answer into v.
+func (c *Client) Decode(resp *httpclient.Response, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(resp.Body))
+	if c.strict {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w : %v", ErrInvalidResponse, err)
+	}
+
+	return nil
+}
+
+func (c *Client) header() http.Header {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return header
+}
+
+func isJSON(resp *httpclient.Response) bool {
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json")
+}
+
+// File is a file uploaded with a form or answered instead of JSON.
+type File struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// GetOrFile sends a GET request to a call answering either with JSON, decoded
+// into v, or with a file, which is returned.
+func (c *Client) GetOrFile(ctx context.Context, path string, query url.Values, v interface{}) (*File, error) {
+	resp, err := c.Raw(ctx, http.MethodGet, path, query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isJSON(resp) {
+		return &File{ContentType: resp.Header.Get("Content-Type"), Content: resp.Body}, nil
+	}
+
+	return nil, c.Decode(resp, v)
+}
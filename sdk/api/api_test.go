@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := httpclient.DefaultConfig(server.URL)
+	config.MaxRetries = 0
+	config.Timeout = time.Second
+
+	return NewClient(httpclient.New(config))
+}
+
+func TestDoSendsTokenAndBody(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Content-Type") != "application/json" || string(body) != `{"name":"nurse"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"message":"success","data":{"id":"1"}}`))
+	})
+
+	body := struct {
+		Name string `json:"name"`
+	}{"nurse"}
+
+	var resp Response[struct {
+		ID string `json:"id"`
+	}]
+	if err := client.WithToken("token").Do(context.Background(), http.MethodPost, "/", nil, body, &resp); err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+	if resp.Data.ID != "1" {
+		t.Errorf("got data %+v", resp.Data)
+	}
+}
+
+func TestWithTokenDoesNotChangeTheClient(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	client.WithToken("token")
+
+	if err := client.Get(context.Background(), "/", nil, &struct{}{}); err != nil {
+		t.Errorf("got error %v, the token leaked into the client", err)
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"problem", "application/problem+json", `{"type":"about:blank","title":"Not Found","status":404,"detail":"patient not found","code":"PATIENT_NOT_FOUND"}`, "patient not found"},
+		{"service", "application/json", `{"message":"patient not found"}`, "patient not found"},
+		{"jwt middleware", "application/json", `{"error":true,"msg":"Missing or malformed JWT"}`, "Missing or malformed JWT"},
+		{"plain text", "text/plain", "Unprocessable Entity\n", "Unprocessable Entity"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(test.body))
+			})
+
+			err := client.Get(context.Background(), "/", nil, &struct{}{})
+
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, want an *Error", err)
+			}
+			if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != test.want {
+				t.Errorf("got %d %q, want 404 %q", apiErr.StatusCode, apiErr.Message, test.want)
+			}
+			if StatusCode(err) != http.StatusNotFound {
+				t.Errorf("got status code %d", StatusCode(err))
+			}
+		})
+	}
+}
+
+func TestErrorProblem(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"payload request doesn't meet requirement","code":"VALIDATION_FAILED","requestId":"req-1","errors":[{"field":"nip","rule":"required","detail":"is required"}]}`))
+	})
+
+	err := client.Get(context.Background(), "/", nil, &struct{}{})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got error %v, want an *Error", err)
+	}
+	if Code(err) != "VALIDATION_FAILED" || apiErr.RequestId != "req-1" {
+		t.Errorf("got code %q and request id %q", Code(err), apiErr.RequestId)
+	}
+	want := []FieldError{{Field: "nip", Rule: "required", Detail: "is required"}}
+	if !reflect.DeepEqual(apiErr.Fields, want) {
+		t.Errorf("got fields %+v, want %+v", apiErr.Fields, want)
+	}
+}
+
+func TestStrictRejectsUnknownFields(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":"success","data":{"id":"1","renamed":"2"}}`))
+	})
+
+	var resp Response[struct {
+		ID string `json:"id"`
+	}]
+	if err := client.Get(context.Background(), "/", nil, &resp); err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+
+	err := client.Strict().Get(context.Background(), "/", nil, &resp)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("got error %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestDoFormSendsMultipart(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		file, header, err := r.FormFile("scan")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+
+		if r.FormValue("nip") != "303" || header.Filename != "scan.png" || string(content) != "png" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	form := NewForm().Field("nip", "303").File("scan", File{Name: "scan.png", Content: []byte("png")})
+	if err := client.DoForm(context.Background(), http.MethodPost, "/", form, &struct{}{}); err != nil {
+		t.Errorf("unexpected error : %+v", err)
+	}
+}
+
+func TestGetOrFile(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("mode") == "stream" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"success","data":{"url":"https://storage/scan.png"}}`))
+	})
+
+	var resp Response[struct {
+		URL string `json:"url"`
+	}]
+	file, err := client.GetOrFile(context.Background(), "/", nil, &resp)
+	if err != nil || file != nil || resp.Data.URL != "https://storage/scan.png" {
+		t.Errorf("got %+v, %+v, %v, want the URL", resp, file, err)
+	}
+
+	file, err = client.GetOrFile(context.Background(), "/", map[string][]string{"mode": {"stream"}}, &resp)
+	if err != nil || file == nil || file.ContentType != "image/png" || string(file.Content) != "png" {
+		t.Errorf("got %+v, %v, want the file", file, err)
+	}
+}
+
+func TestQueryLeavesOutZeroValues(t *testing.T) {
+	query := Query{}.String("name", "").String("gender", "male").Int("limit", 0).Int("offset", 5).Bool("includeRevoked", false)
+
+	if got := query.Values().Encode(); got != "gender=male&offset=5" {
+		t.Errorf("got query %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ravenocx/hospital-mgt/sdk/httpclient"
+)
+
+// Error is an answer other than 2xx.
+type Error struct {
+	StatusCode int
+	Message    string
+	// Code is the stable code of the error, VALIDATION_FAILED, PATIENT_NOT_FOUND...
+	Code      string
+	RequestId string
+	// Fields are the invalid fields of a VALIDATION_FAILED
+	Fields []FieldError
+}
+
+// FieldError is a field of the request that failed a validation rule.
+type FieldError struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s : %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// StatusCode returns the status of the answer when err is an *Error, 0
+// otherwise.
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+
+	return 0
+}
+
+// Code returns the code of the answer when err is an *Error, "" otherwise.
+func Code(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+
+	return ""
+}
+
+func newError(resp *httpclient.Response) *Error {
+	// the services answer errors with a problem, RFC 7807, the older ones
+	// with a message or a msg, and a proxy in front of them with plain text
+	var body struct {
+		Detail    string       `json:"detail"`
+		Code      string       `json:"code"`
+		RequestId string       `json:"requestId"`
+		Errors    []FieldError `json:"errors"`
+		Message   string       `json:"message"`
+		Msg       string       `json:"msg"`
+	}
+
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(resp.Body))}
+	if err := json.Unmarshal(resp.Body, &body); err == nil {
+		apiErr.Message = body.Detail
+		for _, message := range []string{body.Message, body.Msg} {
+			if apiErr.Message == "" {
+				apiErr.Message = message
+			}
+		}
+		apiErr.Code = body.Code
+		apiErr.RequestId = body.RequestId
+		apiErr.Fields = body.Errors
+	}
+
+	return apiErr
+}
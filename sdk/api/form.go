@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+)
+
+// Form is a multipart/form-data body, for the calls uploading a file.
+type Form struct {
+	fields []formField
+	files  []formFile
+}
+
+type formField struct {
+	name  string
+	value string
+}
+
+type formFile struct {
+	name string
+	file File
+}
+
+func NewForm() *Form {
+	return &Form{}
+}
+
+func (f *Form) Field(name string, value string) *Form {
+	f.fields = append(f.fields, formField{name, value})
+	return f
+}
+
+func (f *Form) File(name string, file File) *Form {
+	f.files = append(f.files, formFile{name, file})
+	return f
+}
+
+func (f *Form) encode() ([]byte, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, field := range f.fields {
+		if err := writer.WriteField(field.name, field.value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, file := range f.files {
+		part, err := writer.CreateFormFile(file.name, file.file.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(file.file.Content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
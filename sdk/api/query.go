@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Query builds the query string of a call. Zero values are left out so the
+// service applies its defaults.
+type Query url.Values
+
+func (q Query) String(key string, value string) Query {
+	if value != "" {
+		url.Values(q).Set(key, value)
+	}
+	return q
+}
+
+func (q Query) Int(key string, value int64) Query {
+	if value != 0 {
+		url.Values(q).Set(key, strconv.FormatInt(value, 10))
+	}
+	return q
+}
+
+func (q Query) Bool(key string, value bool) Query {
+	if value {
+		url.Values(q).Set(key, "true")
+	}
+	return q
+}
+
+func (q Query) Values() url.Values {
+	return url.Values(q)
+}
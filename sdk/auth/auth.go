@@ -0,0 +1,98 @@
+// Package auth is the client of the AuthService, served under /v1/user.
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type Client struct {
+	api *api.Client
+}
+
+func New(client *api.Client) *Client {
+	return &Client{client}
+}
+
+// WithToken returns a copy of the client calling with the access token.
+func (c *Client) WithToken(token string) *Client {
+	return &Client{c.api.WithToken(token)}
+}
+
+type Credential struct {
+	Nip      int64  `json:"nip"`
+	Password string `json:"password"`
+}
+
+type AdminRegistration struct {
+	Nip      int64  `json:"nip"`
+	Name     string `json:"name,omitempty"`
+	Password string `json:"password"`
+}
+
+// Session is the user logged in and its tokens.
+type Session struct {
+	UserId string `json:"userId"`
+	Nip    int64  `json:"nip"`
+	Name   string `json:"name"`
+	Token  Token  `json:"token"`
+}
+
+type Token struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RenewedTokens are the tokens given by RenewTokens, the service sends them
+// with capitalized names.
+type RenewedTokens struct {
+	Access  string `json:"Access"`
+	Refresh string `json:"Refresh"`
+}
+
+func (c *Client) NurseLogin(ctx context.Context, credential Credential) (*Session, error) {
+	var resp api.Response[Session]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/user/nurse/login", nil, credential, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) AdminRegister(ctx context.Context, admin AdminRegistration) (*Session, error) {
+	var resp api.Response[Session]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/user/admin/register", nil, admin, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) AdminLogin(ctx context.Context, credential Credential) (*Session, error) {
+	var resp api.Response[Session]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/user/admin/login", nil, credential, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// RenewTokens trades the refresh token for new tokens, the client must carry
+// the access token of the same session.
+func (c *Client) RenewTokens(ctx context.Context, refreshToken string) (*RenewedTokens, error) {
+	body := struct {
+		RefreshToken string `json:"refresh_token"`
+	}{refreshToken}
+
+	var resp struct {
+		Message string        `json:"message"`
+		Tokens  RenewedTokens `json:"tokens"`
+	}
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/user/token/renew", nil, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Tokens, nil
+}
@@ -0,0 +1,247 @@
+// Package envelope encrypts column values with envelope encryption: every
+// value gets its own random data key, the data key is wrapped by a versioned
+// master key from the config and stored next to the ciphertext.
+//
+// An encrypted value looks like
+//
+//	enc:v1:<master key version>:<wrapped data key>:<ciphertext>
+//
+// with both binary parts in unpadded base64url. Values without the enc: prefix
+// are plaintext written before the column was encrypted, they are returned
+// as they are until the re-encryption job gets to them.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	prefix        = "enc:"
+	formatVersion = "v1"
+	keySize       = 32
+	// blind indexes keep half of the HMAC, enough to tell values apart
+	blindIndexSize = 16
+)
+
+var encoding = base64.RawURLEncoding
+
+var ErrMalformed = errors.New("malformed encrypted value")
+
+// Keyring holds the master keys by version. New values are encrypted with the
+// active version, values of the other versions can still be decrypted.
+type Keyring struct {
+	keys      map[int][]byte
+	active    int
+	searchKey []byte
+}
+
+// New parses the master keys given as comma separated "<version>:<base64 key>"
+// pairs, e.g. "1:3q2+7w...,2:AAEC...". The search key is the base64 key of
+// the blind indexes. It can't be rotated without rebuilding them.
+func New(keys string, active int, searchKey string) (*Keyring, error) {
+	k := &Keyring{keys: map[int][]byte{}, active: active}
+
+	for _, pair := range strings.Split(keys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		versionString, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("master key %q is not <version>:<base64 key>", pair)
+		}
+
+		version, err := strconv.Atoi(versionString)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("master key version %q is not a positive number", versionString)
+		}
+
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %d : %v", version, err)
+		}
+
+		if _, ok := k.keys[version]; ok {
+			return nil, fmt.Errorf("master key %d is given twice", version)
+		}
+		k.keys[version] = key
+	}
+
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("active master key %d is not configured", active)
+	}
+
+	var err error
+	k.searchKey, err = decodeKey(searchKey)
+	if err != nil {
+		return nil, fmt.Errorf("search key : %v", err)
+	}
+
+	return k, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 : %v", err)
+	}
+
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	return key, nil
+}
+
+// ActivePrefix is the prefix of the values encrypted with the active master
+// key. Values without it need to be re-encrypted.
+func (k *Keyring) ActivePrefix() string {
+	return prefix + formatVersion + ":" + strconv.Itoa(k.active) + ":"
+}
+
+// IsCurrent tells whether the value is encrypted with the active master key.
+func (k *Keyring) IsCurrent(value string) bool {
+	return strings.HasPrefix(value, k.ActivePrefix())
+}
+
+// Encrypt encrypts the value under a new data key wrapped by the active
+// master key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	header := k.ActivePrefix()
+
+	// the header is authenticated with both parts, so a value can't be
+	// passed off as encrypted by another master key
+	wrapped, err := seal(k.keys[k.active], dataKey, []byte(header))
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext), []byte(header))
+	if err != nil {
+		return "", err
+	}
+
+	return header + encoding.EncodeToString(wrapped) + ":" + encoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt returns the plaintext of an encrypted value. Plaintext values are
+// returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 5 || parts[1] != formatVersion {
+		return "", ErrMalformed
+	}
+
+	version, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	masterKey, ok := k.keys[version]
+	if !ok {
+		return "", fmt.Errorf("master key %d is not configured", version)
+	}
+
+	wrapped, err := encoding.DecodeString(parts[3])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	ciphertext, err := encoding.DecodeString(parts[4])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	header := []byte(strings.Join(parts[:3], ":") + ":")
+
+	dataKey, err := open(masterKey, wrapped, header)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key : %v", err)
+	}
+
+	plaintext, err := open(dataKey, ciphertext, header)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value : %v", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// DecryptAll decrypts the values in place.
+func (k *Keyring) DecryptAll(values ...*string) error {
+	for _, value := range values {
+		plaintext, err := k.Decrypt(*value)
+		if err != nil {
+			return err
+		}
+		*value = plaintext
+	}
+
+	return nil
+}
+
+// BlindIndex is a keyed hash of the value that can be compared for equality
+// in queries without revealing the value.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.searchKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil)[:blindIndexSize])
+}
+
+// seal returns the nonce followed by the AES-GCM ciphertext.
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key []byte, sealed []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
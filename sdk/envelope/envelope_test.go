@@ -0,0 +1,141 @@
+package envelope
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	key1      = "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="
+	key2      = "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI="
+	searchKey = "AwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwM="
+)
+
+func mustKeyring(t *testing.T, keys string, active int) *Keyring {
+	t.Helper()
+
+	k, err := New(keys, active, searchKey)
+	if err != nil {
+		t.Fatalf("failed to create keyring : %+v", err)
+	}
+
+	return k
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	k := mustKeyring(t, "1:"+key1, 1)
+
+	for _, plaintext := range []string{"", "fever and cough", "paracetamol 500mg, 3x1 : after meals", "+6281234567890"} {
+		value, err := k.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("failed to encrypt %q : %+v", plaintext, err)
+		}
+		if !k.IsCurrent(value) {
+			t.Errorf("value %q doesn't have the active prefix", value)
+		}
+		if plaintext != "" && strings.Contains(value, plaintext) {
+			t.Errorf("value %q contains the plaintext", value)
+		}
+
+		got, err := k.Decrypt(value)
+		if err != nil {
+			t.Fatalf("failed to decrypt %q : %+v", value, err)
+		}
+		if got != plaintext {
+			t.Errorf("got %q, want %q", got, plaintext)
+		}
+	}
+}
+
+func TestEncryptUsesANewDataKeyEveryTime(t *testing.T) {
+	k := mustKeyring(t, "1:"+key1, 1)
+
+	a, _ := k.Encrypt("fever")
+	b, _ := k.Encrypt("fever")
+	if a == b {
+		t.Errorf("the same plaintext encrypted twice gave the same value %q", a)
+	}
+}
+
+func TestDecryptPassesPlaintextThrough(t *testing.T) {
+	k := mustKeyring(t, "1:"+key1, 1)
+
+	got, err := k.Decrypt("fever and cough")
+	if err != nil || got != "fever and cough" {
+		t.Errorf("got %q, %v, want the plaintext back", got, err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := mustKeyring(t, "1:"+key1, 1)
+	value, _ := old.Encrypt("fever")
+
+	rotated := mustKeyring(t, "1:"+key1+",2:"+key2, 2)
+	if rotated.IsCurrent(value) {
+		t.Errorf("value under the old key is reported as current")
+	}
+
+	got, err := rotated.Decrypt(value)
+	if err != nil || got != "fever" {
+		t.Fatalf("got %q, %v, want the old value to still decrypt", got, err)
+	}
+
+	retired := mustKeyring(t, "2:"+key2, 2)
+	if _, err := retired.Decrypt(value); err == nil {
+		t.Errorf("value decrypted without its master key")
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	k := mustKeyring(t, "1:"+key1+",2:"+key2, 1)
+	value, _ := k.Encrypt("fever")
+	parts := strings.Split(value, ":")
+
+	// pointing the value at another master key must not work
+	relabeled := strings.Join([]string{parts[0], parts[1], "2", parts[3], parts[4]}, ":")
+	if _, err := k.Decrypt(relabeled); err == nil {
+		t.Errorf("relabeled value decrypted")
+	}
+
+	other, _ := k.Encrypt("cough")
+	swapped := strings.Join(append(parts[:4:4], strings.Split(other, ":")[4]), ":")
+	if _, err := k.Decrypt(swapped); err == nil {
+		t.Errorf("ciphertext decrypted with the data key of another value")
+	}
+
+	if _, err := k.Decrypt("enc:v1:1:nope"); err != ErrMalformed {
+		t.Errorf("got %v, want ErrMalformed", err)
+	}
+}
+
+func TestNewRejectsBadKeys(t *testing.T) {
+	cases := map[string]struct {
+		keys   string
+		active int
+	}{
+		"missing active": {"1:" + key1, 2},
+		"short key":      {"1:AQID", 1},
+		"no version":     {key1, 1},
+		"duplicate":      {"1:" + key1 + ",1:" + key2, 1},
+	}
+
+	for name, c := range cases {
+		if _, err := New(c.keys, c.active, searchKey); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := mustKeyring(t, "1:"+key1, 1)
+
+	if k.BlindIndex("fever") != k.BlindIndex("fever") {
+		t.Errorf("blind index is not deterministic")
+	}
+	if k.BlindIndex("fever") == k.BlindIndex("cough") {
+		t.Errorf("different values have the same blind index")
+	}
+	if len(k.BlindIndex("fever")) != 2*blindIndexSize {
+		t.Errorf("got blind index of length %d", len(k.BlindIndex("fever")))
+	}
+}
@@ -0,0 +1,43 @@
+package envelope
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// BatchFunc re-encrypts up to one batch of values that are still plaintext or
+// under an old master key, and returns how many it changed.
+type BatchFunc func(ctx context.Context) (int, error)
+
+// RunReencryption calls batch until there is nothing left to re-encrypt, then
+// checks again every interval. It returns when the context is done.
+func RunReencryption(ctx context.Context, name string, interval time.Duration, batch BatchFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		total := 0
+		for ctx.Err() == nil {
+			n, err := batch(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to re-encrypt", "name", name, "error", err)
+				break
+			}
+			if n == 0 {
+				break
+			}
+			total += n
+		}
+
+		if total > 0 {
+			slog.InfoContext(ctx, "re-encrypted", "name", name, "total", total)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package httpclient calls the other services of the hospital. A client talks
+// to one base URL with a timeout per attempt, retries idempotent requests
+// with jittered exponential backoff, stops calling a failing service for a
+// while with a circuit breaker and reuses its connections.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type Config struct {
+	BaseURL string
+	// Timeout bounds every attempt, the context of the call bounds them all
+	Timeout time.Duration
+	// MaxRetries is how many times a failed GET or HEAD is tried again
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// the breaker opens after BreakerThreshold failures in a row and lets a
+	// trial request through after BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// WrapTransport, when set, wraps the transport of the client, to trace
+	// the calls or pass headers on
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
+// DefaultConfig returns the defaults for the given base URL.
+func DefaultConfig(baseURL string) Config {
+	return Config{
+		BaseURL:          baseURL,
+		Timeout:          3 * time.Second,
+		MaxRetries:       2,
+		RetryBaseDelay:   100 * time.Millisecond,
+		RetryMaxDelay:    2 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+type Client struct {
+	config  Config
+	http    *http.Client
+	breaker *breaker
+}
+
+// Response is a response read in full, so the connection goes back to the
+// pool before the caller looks at it.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// JSON decodes the body into v.
+func (r *Response) JSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+func New(config Config) *Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   config.Timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if config.WrapTransport != nil {
+		roundTripper = config.WrapTransport(transport)
+	}
+
+	return &Client{
+		config:  config,
+		http:    &http.Client{Transport: roundTripper},
+		breaker: newBreaker(config.BreakerThreshold, config.BreakerCooldown),
+	}
+}
+
+// Get sends a GET request to the path with the query and header.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, header http.Header) (*Response, error) {
+	return c.Do(ctx, http.MethodGet, path, query, header, nil)
+}
+
+// Do sends the request. Transport errors and 502, 503 and 504 responses count
+// as failures of the service, they are retried for GET and HEAD only. Other
+// responses, including 4xx ones, are returned as they are.
+func (c *Client) Do(ctx context.Context, method string, path string, query url.Values, header http.Header, body []byte) (*Response, error) {
+	reqURL := strings.TrimRight(c.config.BaseURL, "/") + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodHead {
+		attempts += c.config.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		if !c.breaker.allow() {
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w after : %v", ErrCircuitOpen, lastErr)
+			}
+			return nil, ErrCircuitOpen
+		}
+
+		resp, err := c.attempt(ctx, method, reqURL, header, body)
+		if err == nil && !isServerFailure(resp.StatusCode) {
+			c.breaker.success()
+			return resp, nil
+		}
+		c.breaker.failure()
+
+		// the caller gave up, there is no point in trying again
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("%s %s returned %d", method, path, resp.StatusCode)
+			if attempt == attempts-1 {
+				return resp, nil
+			}
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (c *Client) attempt(ctx context.Context, method string, reqURL string, header http.Header, body []byte) (*Response, error) {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
+
+// backoff is a random delay up to the exponential backoff of the attempt
+// ("full jitter"), so clients retrying together don't all come back at once.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.config.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > c.config.RetryMaxDelay {
+		delay = c.config.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+func isServerFailure(statusCode int) bool {
+	return statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,321 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig(baseURL string) Config {
+	return Config{
+		BaseURL:          baseURL,
+		Timeout:          time.Second,
+		MaxRetries:       2,
+		RetryBaseDelay:   time.Millisecond,
+		RetryMaxDelay:    5 * time.Millisecond,
+		BreakerThreshold: 100,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+// downstream stands in for a service that answers with the given statuses in
+// turn, then with the last one.
+func downstream(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"success","data":[{"identityNumber":6100000000000001,"name":"patient"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestGetSendsQueryAndHeader(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"message":"success"}`))
+	}))
+	defer server.Close()
+
+	client := New(testConfig(server.URL + "/"))
+	header := http.Header{"Authorization": []string{"Bearer token"}}
+
+	resp, err := client.Get(context.Background(), "/v1/medical/patient", url.Values{"identityNumber": []string{"6100000000000001"}}, header)
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+
+	if got.URL.Path != "/v1/medical/patient" || got.URL.Query().Get("identityNumber") != "6100000000000001" {
+		t.Errorf("got request %s", got.URL)
+	}
+	if got.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("got authorization %q", got.Header.Get("Authorization"))
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := resp.JSON(&body); err != nil || body.Message != "success" {
+		t.Errorf("got body %+v, %v", body, err)
+	}
+}
+
+// roundTripperFunc adds a header to the requests, the way a tracing transport
+// adds the traceparent.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWrapTransportSeesEveryAttempt(t *testing.T) {
+	var traceparents int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") != "" {
+			atomic.AddInt32(&traceparents, 1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := testConfig(server.URL)
+	config.WrapTransport = func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			return next.RoundTrip(req)
+		})
+	}
+
+	New(config).Get(context.Background(), "/", nil, nil)
+
+	if n := atomic.LoadInt32(&traceparents); n != 3 {
+		t.Errorf("got %d attempts through the transport, want 3", n)
+	}
+}
+
+func TestGetRetriesServerFailures(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+
+	resp, err := New(testConfig(server.URL)).Get(context.Background(), "/", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", resp.StatusCode)
+	}
+	if *calls != 3 {
+		t.Errorf("got %d calls, want 3", *calls)
+	}
+}
+
+func TestGetReturnsLastFailureWhenRetriesRunOut(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable)
+
+	resp, err := New(testConfig(server.URL)).Get(context.Background(), "/", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want 503", resp.StatusCode)
+	}
+	if *calls != 3 {
+		t.Errorf("got %d calls, want 3", *calls)
+	}
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	server, calls := downstream(t, http.StatusNotFound)
+
+	resp, err := New(testConfig(server.URL)).Get(context.Background(), "/", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %d, want 404", resp.StatusCode)
+	}
+	if *calls != 1 {
+		t.Errorf("got %d calls, want 1", *calls)
+	}
+}
+
+func TestPostIsNotRetried(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	resp, err := New(testConfig(server.URL)).Do(context.Background(), http.MethodPost, "/", nil, nil, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || *calls != 1 {
+		t.Errorf("got status %d after %d calls, want 503 after 1", resp.StatusCode, *calls)
+	}
+}
+
+func TestGetTimesOutSlowAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// only the first attempt hangs
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	config := testConfig(server.URL)
+	config.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	resp, err := New(config).Get(context.Background(), "/", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error : %+v", err)
+	}
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("got status %d after %d calls, want 200 after 2", resp.StatusCode, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s, the slow attempt wasn't cut off", elapsed)
+	}
+}
+
+func TestGetStopsWhenContextIsDone(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable)
+
+	config := testConfig(server.URL)
+	config.RetryBaseDelay = time.Second
+	config.RetryMaxDelay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := New(config).Get(ctx, "/", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if *calls != 1 {
+		t.Errorf("got %d calls, want 1", *calls)
+	}
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+
+	config := testConfig(server.URL)
+	config.MaxRetries = 0
+	config.BreakerThreshold = 3
+	config.BreakerCooldown = time.Minute
+
+	client := New(config)
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), "/", nil, nil); err != nil {
+			t.Fatalf("call %d : unexpected error : %+v", i, err)
+		}
+	}
+
+	// open: the service isn't called
+	if _, err := client.Get(context.Background(), "/", nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got error %v, want ErrCircuitOpen", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("got %d calls, want 3", *calls)
+	}
+
+	// after the cooldown a trial request goes through and closes the breaker
+	now = now.Add(config.BreakerCooldown)
+	resp, err := client.Get(context.Background(), "/", nil, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %v, %v, want the trial request to succeed", resp, err)
+	}
+
+	if _, err := client.Get(context.Background(), "/", nil, nil); err != nil {
+		t.Errorf("breaker didn't close : %v", err)
+	}
+}
+
+func TestBreakerReopensWhenTrialFails(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable)
+
+	config := testConfig(server.URL)
+	config.MaxRetries = 0
+	config.BreakerThreshold = 1
+
+	client := New(config)
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	client.Get(context.Background(), "/", nil, nil)
+
+	now = now.Add(config.BreakerCooldown)
+	client.Get(context.Background(), "/", nil, nil)
+
+	if _, err := client.Get(context.Background(), "/", nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got error %v, want ErrCircuitOpen", err)
+	}
+	if *calls != 2 {
+		t.Errorf("got %d calls, want 2", *calls)
+	}
+}
+
+func TestBreakerStopsRetries(t *testing.T) {
+	server, calls := downstream(t, http.StatusServiceUnavailable)
+
+	config := testConfig(server.URL)
+	config.MaxRetries = 5
+	config.BreakerThreshold = 2
+
+	_, err := New(config).Get(context.Background(), "/", nil, nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got error %v, want ErrCircuitOpen", err)
+	}
+	if *calls != 2 {
+		t.Errorf("got %d calls, want 2", *calls)
+	}
+}
+
+func TestConnectionsAreReused(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := New(testConfig(server.URL))
+	for i := 0; i < 10; i++ {
+		if _, err := client.Get(context.Background(), "/", nil, nil); err != nil {
+			t.Fatalf("unexpected error : %+v", err)
+		}
+	}
+
+	if connections != 1 {
+		t.Errorf("got %d connections for 10 sequential requests, want 1", connections)
+	}
+}
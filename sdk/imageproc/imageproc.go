@@ -0,0 +1,178 @@
+// Package imageproc validates uploaded images and normalizes them before they
+// are stored. Every upload is decoded and re-encoded, which drops any metadata
+// the original file carried (EXIF, GPS location, camera details).
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrInvalidImage is wrapped by every error caused by the upload itself rather
+// than by the server, callers can map it to a bad request.
+var ErrInvalidImage = errors.New("invalid image")
+
+var (
+	ErrTooLarge        = fmt.Errorf("%w: file is too large", ErrInvalidImage)
+	ErrUnsupportedType = fmt.Errorf("%w: only JPEG and PNG images are allowed", ErrInvalidImage)
+	ErrDimensions      = fmt.Errorf("%w: image dimensions are out of range", ErrInvalidImage)
+)
+
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+)
+
+// Limits bounds what is accepted as an upload.
+type Limits struct {
+	MaxBytes      int64
+	MinWidth      int
+	MinHeight     int
+	MaxWidth      int
+	MaxHeight     int
+	ThumbnailSize int // longest side of the thumbnail in pixels
+	JPEGQuality   int
+}
+
+// DefaultLimits fits identity card scans and photos taken with a phone camera.
+var DefaultLimits = Limits{
+	MaxBytes:      5 << 20,
+	MinWidth:      200,
+	MinHeight:     200,
+	MaxWidth:      8000,
+	MaxHeight:     8000,
+	ThumbnailSize: 320,
+	JPEGQuality:   85,
+}
+
+// Image is a normalized upload and its thumbnail, both in the same format.
+type Image struct {
+	Data        []byte
+	Thumbnail   []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Sniff detects the content type from the first bytes of the content, the
+// filename and the Content-Type sent by the client are never trusted.
+func Sniff(header []byte) (string, error) {
+	contentType := http.DetectContentType(header)
+	if contentType != ContentTypeJPEG && contentType != ContentTypePNG {
+		return "", ErrUnsupportedType
+	}
+
+	return contentType, nil
+}
+
+// Process reads an upload, checks it against the limits and returns it
+// re-encoded together with a thumbnail.
+func Process(r io.Reader, limits Limits) (*Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, fmt.Errorf("%w (max %d bytes)", ErrTooLarge, limits.MaxBytes)
+	}
+
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the dimensions from the header before decoding, a small file can
+	// still declare a huge image
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImage, err.Error())
+	}
+	if config.Width < limits.MinWidth || config.Height < limits.MinHeight ||
+		config.Width > limits.MaxWidth || config.Height > limits.MaxHeight {
+		return nil, fmt.Errorf("%w (got %dx%d, allowed %dx%d to %dx%d)", ErrDimensions,
+			config.Width, config.Height, limits.MinWidth, limits.MinHeight, limits.MaxWidth, limits.MaxHeight)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImage, err.Error())
+	}
+
+	// the EXIF orientation is lost with the rest of the metadata, so apply it
+	// to the pixels first or phone photos end up sideways
+	if contentType == ContentTypeJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	result := &Image{
+		ContentType: contentType,
+		Ext:         extension(contentType),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+
+	if result.Data, err = encode(img, contentType, limits.JPEGQuality); err != nil {
+		return nil, err
+	}
+
+	if result.Thumbnail, err = encode(thumbnail(img, limits.ThumbnailSize), contentType, limits.JPEGQuality); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func encode(img image.Image, contentType string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if contentType == ContentTypePNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// thumbnail scales img down so its longest side is at most size pixels,
+// keeping the aspect ratio. Smaller images are kept as they are.
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func extension(contentType string) string {
+	if contentType == ContentTypePNG {
+		return ".png"
+	}
+	return ".jpg"
+}
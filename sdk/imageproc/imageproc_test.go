@@ -0,0 +1,334 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage is red on its left half and blue on its right half, so a rotation
+// or a mirror can be told from the result.
+func testImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("failed to encode jpeg : %+v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png : %+v", err)
+	}
+	return buf.Bytes()
+}
+
+// exifTIFF returns a big endian TIFF header with a single IFD entry.
+func exifTIFF(tag uint16, value uint16) []byte {
+	tiff := []byte("MM\x00\x2a")
+	tiff = binary.BigEndian.AppendUint32(tiff, 8)
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, tag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, value)
+	tiff = append(tiff, 0, 0)
+	// no next IFD, followed by a GPS-like marker that must not survive
+	tiff = binary.BigEndian.AppendUint32(tiff, 0)
+	return append(tiff, []byte("GPS -6.2088,106.8456")...)
+}
+
+// withAPP1 inserts an APP1 segment holding payload right after the SOI marker
+// of a JPEG.
+func withAPP1(jpg []byte, payload []byte) []byte {
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func withOrientation(jpg []byte, orientation uint16) []byte {
+	return withAPP1(jpg, append([]byte("Exif\x00\x00"), exifTIFF(exifOrientationTag, orientation)...))
+}
+
+// withPNGSize rewrites the dimensions declared in the IHDR chunk of a PNG,
+// keeping its CRC valid, without touching the pixel data.
+func withPNGSize(data []byte, width, height uint32) []byte {
+	out := append([]byte{}, data...)
+	// 8 bytes of signature, 4 of length, 4 of type, then width and height
+	binary.BigEndian.PutUint32(out[16:20], width)
+	binary.BigEndian.PutUint32(out[20:24], height)
+	binary.BigEndian.PutUint32(out[29:33], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+func TestSniff(t *testing.T) {
+	var gifData bytes.Buffer
+	gif.Encode(&gifData, testImage(4, 4), nil)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"jpeg", encodeJPEG(t, testImage(4, 4)), ContentTypeJPEG},
+		{"png", encodePNG(t, testImage(4, 4)), ContentTypePNG},
+		{"gif", gifData.Bytes(), ""},
+		{"text named like an image", []byte("not an image at all"), ""},
+		{"html", []byte("<html><script>alert(1)</script></html>"), ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sniff(tt.data)
+			if tt.want == "" {
+				if !errors.Is(err, ErrUnsupportedType) {
+					t.Errorf("got %q, %v, want ErrUnsupportedType", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	valid := encodePNG(t, testImage(300, 200))
+	jpg := encodeJPEG(t, testImage(300, 200))
+
+	var gifData bytes.Buffer
+	gif.Encode(&gifData, testImage(300, 200), nil)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		limits Limits
+		want   error
+	}{
+		{"over the size limit", valid, Limits{MaxBytes: int64(len(valid)) - 1, MaxWidth: 8000, MaxHeight: 8000}, ErrTooLarge},
+		{"gif", gifData.Bytes(), DefaultLimits, ErrUnsupportedType},
+		{"too small", encodePNG(t, testImage(100, 300)), DefaultLimits, ErrDimensions},
+		{"wider than allowed", valid, Limits{MaxBytes: 5 << 20, MaxWidth: 299, MaxHeight: 8000}, ErrDimensions},
+		// a few hundred bytes declaring a 100000x100000 image, rejected before
+		// anything is allocated for its pixels
+		{"huge declared dimensions", withPNGSize(valid, 100000, 100000), DefaultLimits, ErrDimensions},
+		{"truncated png", valid[:len(valid)/2], DefaultLimits, ErrInvalidImage},
+		{"truncated jpeg", jpg[:len(jpg)/2], DefaultLimits, ErrInvalidImage},
+		{"png header only", valid[:33], DefaultLimits, ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(bytes.NewReader(tt.data), tt.limits)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+			if !errors.Is(err, ErrInvalidImage) {
+				t.Errorf("got error %v, want it to wrap ErrInvalidImage", err)
+			}
+		})
+	}
+}
+
+func TestProcessStripsMetadata(t *testing.T) {
+	data := withOrientation(encodeJPEG(t, testImage(300, 200)), 1)
+	// a comment segment is metadata too
+	data = append(data[:2], append([]byte{0xFF, 0xFE, 0x00, 0x0E, 'p', 'a', 't', 'i', 'e', 'n', 't', ' ', 'o', 'n', 'e', 0}, data[2:]...)...)
+
+	processed, err := Process(bytes.NewReader(data), DefaultLimits)
+	if err != nil {
+		t.Fatalf("failed to process : %+v", err)
+	}
+
+	for _, leaked := range []string{"Exif", "GPS", "patient one"} {
+		if bytes.Contains(processed.Data, []byte(leaked)) || bytes.Contains(processed.Thumbnail, []byte(leaked)) {
+			t.Errorf("%q survived the re-encoding", leaked)
+		}
+	}
+	if processed.ContentType != ContentTypeJPEG || processed.Ext != ".jpg" {
+		t.Errorf("got %s %s, want a jpeg", processed.ContentType, processed.Ext)
+	}
+}
+
+func TestProcessOrientation(t *testing.T) {
+	// the source is 300x200, red on the left and blue on the right
+	tests := []struct {
+		orientation   uint16
+		width, height int
+		// colour of the top left corner of the result
+		topLeftRed bool
+	}{
+		{1, 300, 200, true},
+		{2, 300, 200, false},
+		{3, 300, 200, false},
+		{4, 300, 200, true},
+		{5, 200, 300, true},
+		{6, 200, 300, true},
+		{7, 200, 300, false},
+		{8, 200, 300, false},
+	}
+
+	jpg := encodeJPEG(t, testImage(300, 200))
+	for _, tt := range tests {
+		t.Run(string(rune('0'+tt.orientation)), func(t *testing.T) {
+			processed, err := Process(bytes.NewReader(withOrientation(jpg, tt.orientation)), DefaultLimits)
+			if err != nil {
+				t.Fatalf("failed to process : %+v", err)
+			}
+			if processed.Width != tt.width || processed.Height != tt.height {
+				t.Errorf("got %dx%d, want %dx%d", processed.Width, processed.Height, tt.width, tt.height)
+			}
+
+			img, err := jpeg.Decode(bytes.NewReader(processed.Data))
+			if err != nil {
+				t.Fatalf("failed to decode result : %+v", err)
+			}
+			r, _, b, _ := img.At(5, 5).RGBA()
+			if red := r > b; red != tt.topLeftRed {
+				t.Errorf("got top left red %v, want %v", red, tt.topLeftRed)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientationMalformed(t *testing.T) {
+	jpg := encodeJPEG(t, testImage(4, 4))
+	exif := func(tiff []byte) []byte { return withAPP1(jpg, append([]byte("Exif\x00\x00"), tiff...)) }
+	valid := exifTIFF(exifOrientationTag, 6)
+
+	littleEndian := []byte("II\x2a\x00")
+	littleEndian = binary.LittleEndian.AppendUint32(littleEndian, 8)
+	littleEndian = binary.LittleEndian.AppendUint16(littleEndian, 1)
+	littleEndian = binary.LittleEndian.AppendUint16(littleEndian, exifOrientationTag)
+	littleEndian = binary.LittleEndian.AppendUint16(littleEndian, 3)
+	littleEndian = binary.LittleEndian.AppendUint32(littleEndian, 1)
+	littleEndian = binary.LittleEndian.AppendUint16(littleEndian, 8)
+	littleEndian = append(littleEndian, 0, 0, 0, 0, 0, 0)
+
+	outOfRangeIFD := append([]byte{}, valid...)
+	binary.BigEndian.PutUint32(outOfRangeIFD[4:8], 0xFFFFFFF0)
+
+	// the orientation would be in one of the missing entries
+	tooManyEntries := exifTIFF(0x010F, 6)[:22]
+	binary.BigEndian.PutUint16(tooManyEntries[8:10], 500)
+
+	// a segment length running past the end of the file
+	overlong := append([]byte{}, jpg[:2]...)
+	overlong = append(overlong, 0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x', 'i', 'f', 0, 0)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"valid big endian", exif(valid), 6},
+		{"valid little endian", exif(littleEndian), 8},
+		{"no exif", jpg, 1},
+		{"not a jpeg", encodePNG(t, testImage(4, 4)), 1},
+		{"empty", nil, 1},
+		{"soi only", jpg[:2], 1},
+		{"truncated tiff header", exif(valid[:6]), 1},
+		{"unknown byte order", exif(append([]byte("XX"), valid[2:]...)), 1},
+		{"ifd offset out of range", exif(outOfRangeIFD), 1},
+		{"entries past the end", exif(tooManyEntries), 1},
+		{"orientation out of range", exif(exifTIFF(exifOrientationTag, 9)), 1},
+		{"orientation zero", exif(exifTIFF(exifOrientationTag, 0)), 1},
+		{"other tag only", exif(exifTIFF(0x010F, 6)), 1},
+		{"segment length past the end", overlong, 1},
+		{"segment length below two", append(append([]byte{}, jpg[:2]...), 0xFF, 0xE1, 0x00, 0x01), 1},
+		{"garbage after soi", append(append([]byte{}, jpg[:2]...), 0x00, 0x01, 0x02, 0x03), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("got orientation %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessMalformedEXIF(t *testing.T) {
+	jpg := encodeJPEG(t, testImage(300, 200))
+	outOfRangeIFD := exifTIFF(exifOrientationTag, 6)
+	binary.BigEndian.PutUint32(outOfRangeIFD[4:8], 0xFFFFFFF0)
+
+	// the upload is still accepted, upright as it was decoded
+	for _, data := range [][]byte{
+		withAPP1(jpg, []byte("Exif\x00\x00MM")),
+		withAPP1(jpg, append([]byte("Exif\x00\x00"), outOfRangeIFD...)),
+		withOrientation(jpg, 42),
+	} {
+		processed, err := Process(bytes.NewReader(data), DefaultLimits)
+		if err != nil {
+			t.Fatalf("failed to process : %+v", err)
+		}
+		if processed.Width != 300 || processed.Height != 200 {
+			t.Errorf("got %dx%d, want 300x200", processed.Width, processed.Height)
+		}
+	}
+}
+
+func TestProcessThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		thumbW        int
+		thumbH        int
+	}{
+		{"landscape", 1000, 500, 320, 160},
+		{"portrait", 500, 1000, 160, 320},
+		{"square", 640, 640, 320, 320},
+		{"already small", 250, 200, 250, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := Process(bytes.NewReader(encodePNG(t, testImage(tt.width, tt.height))), DefaultLimits)
+			if err != nil {
+				t.Fatalf("failed to process : %+v", err)
+			}
+
+			config, err := png.DecodeConfig(bytes.NewReader(processed.Thumbnail))
+			if err != nil {
+				t.Fatalf("failed to decode thumbnail : %+v", err)
+			}
+			if config.Width != tt.thumbW || config.Height != tt.thumbH {
+				t.Errorf("got thumbnail %dx%d, want %dx%d", config.Width, config.Height, tt.thumbW, tt.thumbH)
+			}
+			if processed.ContentType != ContentTypePNG || processed.Ext != ".png" {
+				t.Errorf("got %s %s, want a png", processed.ContentType, processed.Ext)
+			}
+		})
+	}
+}
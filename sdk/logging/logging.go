@@ -0,0 +1,77 @@
+// Package logging is the logger of the services: JSON lines written through
+// slog, with the request id of the context and the personal data and secrets
+// masked before anything is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+type requestIdKey struct{}
+
+// WithRequestId returns a copy of ctx whose logs carry the request id.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestId returns the request id of ctx, "" when there is none.
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+// New returns a logger writing JSON lines to w, from level up, with the
+// service name on every line.
+func New(w io.Writer, service string, level slog.Leveler) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})
+
+	return slog.New(contextHandler{handler}).With("service", service)
+}
+
+// Setup makes New(os.Stderr, service, level) the default logger, for slog and
+// for the log package. level is debug, info, warn or error.
+func Setup(service string, level string) error {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+
+	slog.SetDefault(New(os.Stderr, service, logLevel))
+
+	return nil
+}
+
+// Fatal logs the error and stops the process, for the failures the service
+// can't start or keep running with.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// contextHandler adds the request id of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestId := RequestId(ctx); requestId != "" {
+		record.AddAttrs(slog.String("requestId", requestId))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the attributes and JSON fields never written, compared in
+// lower case without "_" and "-". Keys ending with token, password or secret
+// are never written either.
+var sensitiveKeys = map[string]bool{
+	"nip":            true,
+	"identitynumber": true,
+	"phone":          true,
+	"phonenumber":    true,
+	"authorization":  true,
+	"cookie":         true,
+	"jwt":            true,
+}
+
+var (
+	// "password": "...", token=... in messages and error strings
+	keyValuePattern = regexp.MustCompile(`(?i)("?(?:password|token|secret|nip|identity_?number|phone_?number)"?\s*[:=]\s*)("[^"]*"|[^\s,&}"]+)`)
+
+	valuePatterns = []*regexp.Regexp{
+		// JWTs
+		regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		regexp.MustCompile(`(?i)\bbearer\s+[^\s"]+`),
+		// phone numbers, +62 or 0 then 8 and the subscriber number
+		regexp.MustCompile(`(\+62|\b0)8\d{7,11}\b`),
+		// NIPs (13 digits) and identity numbers (16 digits)
+		regexp.MustCompile(`\b\d{13,16}\b`),
+	}
+)
+
+func isSensitiveKey(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+
+	return sensitiveKeys[key] || strings.HasSuffix(key, "token") || strings.HasSuffix(key, "password") || strings.HasSuffix(key, "secret")
+}
+
+// redact masks the values that look like personal data or secrets in s.
+func redact(s string) string {
+	s = keyValuePattern.ReplaceAllString(s, "${1}"+redacted)
+	for _, pattern := range valuePatterns {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+
+	return s
+}
+
+// redactAttr is the ReplaceAttr of the handler, it sees every attribute,
+// the message included.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if groups == nil && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+		return a
+	}
+
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	a.Value = redactValue(a.Value)
+	return a
+}
+
+func redactValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.StringValue(redact(v.String()))
+	case slog.KindInt64, slog.KindUint64:
+		if redact(v.String()) != v.String() {
+			return slog.StringValue(redacted)
+		}
+	case slog.KindAny:
+		return redactAny(v.Any())
+	}
+
+	return v
+}
+
+// redactAny masks the errors and the bodies, and walks the structs, maps and
+// slices through their JSON to mask their fields.
+func redactAny(v any) slog.Value {
+	switch v := v.(type) {
+	case error:
+		return slog.StringValue(redact(v.Error()))
+	case []byte:
+		return slog.StringValue(redact(string(v)))
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		// what can't be checked isn't written
+		return slog.StringValue(redacted)
+	}
+
+	var decoded any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return slog.StringValue(redacted)
+	}
+
+	return slog.AnyValue(redactJSON(decoded))
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	case string:
+		return redact(v)
+	case json.Number:
+		if redact(v.String()) != v.String() {
+			return redacted
+		}
+	}
+
+	return v
+}
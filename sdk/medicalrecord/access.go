@@ -0,0 +1,132 @@
+package medicalrecord
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type BreakGlassRequest struct {
+	IdentityNumber int64  `json:"identityNumber"`
+	Justification  string `json:"justification"`
+}
+
+type BreakGlassGrant struct {
+	ID             string `json:"id"`
+	UserId         string `json:"userId"`
+	Role           string `json:"role"`
+	IdentityNumber int64  `json:"identityNumber"`
+	Justification  string `json:"justification"`
+	GrantedAt      string `json:"grantedAt"`
+	ExpiresAt      string `json:"expiresAt"`
+	// ReviewStatus is pending, acknowledged or escalated
+	ReviewStatus string  `json:"reviewStatus"`
+	ReviewNote   *string `json:"reviewNote"`
+	ReviewedBy   *string `json:"reviewedByUserId"`
+	ReviewedAt   *string `json:"reviewedAt"`
+	// Reads is the number of records read with the grant
+	Reads int `json:"reads"`
+}
+
+type GetBreakGlassGrantsQuery struct {
+	// ReviewStatus defaults to pending
+	ReviewStatus string
+	Limit        int
+	Offset       int
+}
+
+type BreakGlassReview struct {
+	// Decision is acknowledged or escalated, an escalation needs a note
+	Decision string `json:"decision"`
+	Note     string `json:"note,omitempty"`
+}
+
+type AccessLog struct {
+	ID             int64    `json:"id"`
+	ReaderUserId   string   `json:"readerUserId"`
+	ReaderRole     string   `json:"readerRole"`
+	IdentityNumber int64    `json:"identityNumber"`
+	RecordIds      []string `json:"recordIds"`
+	AccessType     string   `json:"accessType"`
+	Reason         *string  `json:"reason"`
+	ClientIP       string   `json:"clientIp"`
+	AccessedAt     string   `json:"accessedAt"`
+	PrevHash       string   `json:"prevHash"`
+	Hash           string   `json:"hash"`
+}
+
+type GetAccessLogsQuery struct {
+	IdentityNumber int64
+	Limit          int
+	Offset         int
+}
+
+// AccessLogVerification is the result of walking the hash chain of the access
+// log, BrokenAtId is the first entry whose hash doesn't match.
+type AccessLogVerification struct {
+	Valid      bool   `json:"valid"`
+	Entries    int64  `json:"entries"`
+	BrokenAtId *int64 `json:"brokenAtId,omitempty"`
+}
+
+// BreakGlass grants the caller an emergency access to the records of a
+// patient outside of their wards and encounters.
+func (c *Client) BreakGlass(ctx context.Context, request BreakGlassRequest) (*BreakGlassGrant, error) {
+	var resp api.Response[BreakGlassGrant]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/medical/break-glass", nil, request, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// GetBreakGlassGrants lists the grants to review, admins only.
+func (c *Client) GetBreakGlassGrants(ctx context.Context, query GetBreakGlassGrantsQuery) ([]BreakGlassGrant, error) {
+	params := api.Query{}.
+		String("reviewStatus", query.ReviewStatus).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset))
+
+	var resp api.Response[[]BreakGlassGrant]
+	if err := c.api.Get(ctx, "/v1/medical/break-glass", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *Client) ReviewBreakGlass(ctx context.Context, grantId string, review BreakGlassReview) (*BreakGlassGrant, error) {
+	var resp api.Response[BreakGlassGrant]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/medical/break-glass/"+url.PathEscape(grantId)+"/review", nil, review, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// GetAccessLogs lists who read the records of a patient, admins only.
+func (c *Client) GetAccessLogs(ctx context.Context, query GetAccessLogsQuery) ([]AccessLog, error) {
+	params := api.Query{}.
+		Int("identityNumber", query.IdentityNumber).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset))
+
+	var resp api.Response[[]AccessLog]
+	if err := c.api.Get(ctx, "/v1/medical/access-log", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// VerifyAccessLogs checks the hash chain of the access log, admins only.
+func (c *Client) VerifyAccessLogs(ctx context.Context) (*AccessLogVerification, error) {
+	var resp api.Response[AccessLogVerification]
+	if err := c.api.Get(ctx, "/v1/medical/access-log/verify", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
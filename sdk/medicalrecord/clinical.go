@@ -0,0 +1,123 @@
+package medicalrecord
+
+import (
+	"context"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type GetVitalSignsQuery struct {
+	IdentityNumber int64
+	Type           string
+	From           string
+	To             string
+	// Reason is required from admins and logged with the access
+	Reason string
+}
+
+// VitalSignSeries is every measurement of one vital sign type, oldest first.
+type VitalSignSeries struct {
+	Type   string           `json:"type"`
+	Unit   string           `json:"unit"`
+	Points []VitalSignPoint `json:"points"`
+}
+
+type VitalSignPoint struct {
+	Value      *float64 `json:"value,omitempty"`
+	Systolic   *float64 `json:"systolic,omitempty"`
+	Diastolic  *float64 `json:"diastolic,omitempty"`
+	MeasuredAt string   `json:"measuredAt"`
+}
+
+type Drug struct {
+	Code   string   `json:"code"`
+	Name   string   `json:"name"`
+	Form   string   `json:"form"`
+	Units  []string `json:"units"`
+	Routes []string `json:"routes"`
+}
+
+type SearchQuery struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+type MedicationOrder struct {
+	ID           string  `json:"id"`
+	DrugCode     string  `json:"drugCode"`
+	DrugName     string  `json:"drugName"`
+	Dose         float64 `json:"dose"`
+	DoseUnit     string  `json:"doseUnit"`
+	Route        string  `json:"route"`
+	Frequency    string  `json:"frequency"`
+	StartAt      string  `json:"startAt"`
+	StopAt       *string `json:"stopAt"`
+	PrescribedBy Author  `json:"prescribedBy"`
+}
+
+type Icd10Code struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+func (c *Client) GetVitalSigns(ctx context.Context, query GetVitalSignsQuery) ([]VitalSignSeries, error) {
+	params := api.Query{}.
+		Int("identityNumber", query.IdentityNumber).
+		String("type", query.Type).
+		String("from", query.From).
+		String("to", query.To).
+		String("reason", query.Reason)
+
+	var resp api.Response[[]VitalSignSeries]
+	if err := c.api.Get(ctx, "/v1/medical/vitals", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// SearchDrugs searches the drug catalog by name.
+func (c *Client) SearchDrugs(ctx context.Context, query SearchQuery) ([]Drug, error) {
+	params := api.Query{}.
+		String("name", query.Query).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset))
+
+	var resp api.Response[[]Drug]
+	if err := c.api.Get(ctx, "/v1/medical/drug", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// GetActiveMedications returns the orders of the patient not stopped yet,
+// reason is required from admins.
+func (c *Client) GetActiveMedications(ctx context.Context, identityNumber int64, reason string) ([]MedicationOrder, error) {
+	params := api.Query{}.
+		Int("identityNumber", identityNumber).
+		String("reason", reason)
+
+	var resp api.Response[[]MedicationOrder]
+	if err := c.api.Get(ctx, "/v1/medical/medication/active", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// SearchDiagnosisCodes searches the ICD-10 codes by code or description.
+func (c *Client) SearchDiagnosisCodes(ctx context.Context, query SearchQuery) ([]Icd10Code, error) {
+	params := api.Query{}.
+		String("q", query.Query).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset))
+
+	var resp api.Response[[]Icd10Code]
+	if err := c.api.Get(ctx, "/v1/medical/diagnosis-code", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
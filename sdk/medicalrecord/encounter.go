@@ -0,0 +1,96 @@
+package medicalrecord
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type NewEncounter struct {
+	IdentityNumber int64 `json:"identityNumber"`
+	// EncounterType is outpatient, inpatient or emergency
+	EncounterType   string `json:"encounterType"`
+	AdmittingReason string `json:"admittingReason"`
+	// Ward is required for inpatients
+	Ward              string   `json:"ward,omitempty"`
+	AttendingStaffIds []string `json:"attendingStaffIds"`
+}
+
+type OpenedEncounter struct {
+	ID             string `json:"id"`
+	IdentityNumber int64  `json:"identityNumber"`
+	EncounterType  string `json:"encounterType"`
+	Status         string `json:"status"`
+}
+
+type GetEncountersQuery struct {
+	IdentityNumber int64
+	// Status is open or discharged
+	Status string
+	Limit  int
+	Offset int
+	// Reason is required from admins and logged with the access
+	Reason string
+}
+
+type Encounter struct {
+	ID                 string            `json:"id"`
+	IdentityNumber     int64             `json:"identityNumber"`
+	EncounterType      string            `json:"encounterType"`
+	AdmittingReason    string            `json:"admittingReason"`
+	Ward               *string           `json:"ward"`
+	Status             string            `json:"status"`
+	AttendingStaffIds  []string          `json:"attendingStaffIds"`
+	OpenedByUserId     string            `json:"openedByUserId"`
+	AdmittedAt         string            `json:"admittedAt"`
+	DischargeSummary   *string           `json:"dischargeSummary"`
+	DischargedByUserId *string           `json:"dischargedByUserId"`
+	DischargedAt       *string           `json:"dischargedAt"`
+	Records            []EncounterRecord `json:"records"`
+}
+
+type EncounterRecord struct {
+	ID          string `json:"id"`
+	Symptoms    string `json:"symptoms"`
+	Medications string `json:"medications"`
+	CreatedBy   Author `json:"createdBy"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+func (c *Client) OpenEncounter(ctx context.Context, encounter NewEncounter) (*OpenedEncounter, error) {
+	var resp api.Response[OpenedEncounter]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/medical/encounter", nil, encounter, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) GetEncounters(ctx context.Context, query GetEncountersQuery) ([]Encounter, error) {
+	params := api.Query{}.
+		Int("identityNumber", query.IdentityNumber).
+		String("status", query.Status).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset)).
+		String("reason", query.Reason)
+
+	var resp api.Response[[]Encounter]
+	if err := c.api.Get(ctx, "/v1/medical/encounter", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *Client) DischargeEncounter(ctx context.Context, encounterId string, summary string) error {
+	body := struct {
+		DischargeSummary string `json:"dischargeSummary"`
+	}{summary}
+
+	var resp struct {
+		Message string `json:"message"`
+	}
+	return c.api.Do(ctx, http.MethodPost, "/v1/medical/encounter/"+url.PathEscape(encounterId)+"/discharge", nil, body, &resp)
+}
@@ -0,0 +1,255 @@
+// Package medicalrecord is the client of the MedicalRecord service, served
+// under /v1/medical.
+package medicalrecord
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type Client struct {
+	api *api.Client
+}
+
+func New(client *api.Client) *Client {
+	return &Client{client}
+}
+
+// WithToken returns a copy of the client calling with the access token.
+func (c *Client) WithToken(token string) *Client {
+	return &Client{c.api.WithToken(token)}
+}
+
+type NewRecord struct {
+	IdentityNumber   int64                    `json:"identityNumber"`
+	Symptoms         string                   `json:"symptoms"`
+	Medications      string                   `json:"medications"`
+	MedicationOrders []MedicationOrderRequest `json:"medicationOrders,omitempty"`
+	EncounterId      string                   `json:"encounterId,omitempty"`
+	Vitals           []VitalSignRequest       `json:"vitals,omitempty"`
+	Diagnoses        []DiagnosisRequest       `json:"diagnoses,omitempty"`
+}
+
+type MedicationOrderRequest struct {
+	DrugCode  string  `json:"drugCode"`
+	Dose      float64 `json:"dose"`
+	DoseUnit  string  `json:"doseUnit"`
+	Route     string  `json:"route"`
+	Frequency string  `json:"frequency"`
+	StartAt   string  `json:"startAt,omitempty"`
+	StopAt    string  `json:"stopAt,omitempty"`
+}
+
+// VitalSignRequest is a measurement, blood pressure is given with Systolic
+// and Diastolic, the other types with Value.
+type VitalSignRequest struct {
+	Type       string   `json:"type"`
+	Value      *float64 `json:"value,omitempty"`
+	Systolic   *float64 `json:"systolic,omitempty"`
+	Diastolic  *float64 `json:"diastolic,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+	MeasuredAt string   `json:"measuredAt,omitempty"`
+}
+
+type DiagnosisRequest struct {
+	Code string `json:"code"`
+	// Type is primary or secondary
+	Type string `json:"type"`
+}
+
+type Record struct {
+	ID               string            `json:"id"`
+	IdentityDetail   PatientDetail     `json:"identityDetail"`
+	Symptoms         string            `json:"symptoms"`
+	Medications      string            `json:"medications"`
+	Vitals           []VitalSign       `json:"vitals"`
+	MedicationOrders []MedicationOrder `json:"medicationOrders"`
+	Diagnoses        []Diagnosis       `json:"diagnoses"`
+	// Version is the number of versions of the record, amendments included
+	Version   int    `json:"version"`
+	CreatedBy Author `json:"createdBy"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type Author struct {
+	Nip    string `json:"nip"`
+	Name   string `json:"name"`
+	UserId string `json:"userId"`
+	Role   string `json:"role,omitempty"`
+}
+
+type PatientDetail struct {
+	IdentityNumber      int64  `json:"identityNumber"`
+	PhoneNumber         string `json:"phoneNumber"`
+	Name                string `json:"name"`
+	BirthDate           string `json:"birthDate"`
+	Gender              string `json:"gender"`
+	IdentityCardScanImg string `json:"identityCardScanImg"`
+	// Partial is set when the patient could not be found, only IdentityNumber
+	// is filled then
+	Partial bool `json:"partial,omitempty"`
+}
+
+type VitalSign struct {
+	Type       string   `json:"type"`
+	Value      *float64 `json:"value,omitempty"`
+	Systolic   *float64 `json:"systolic,omitempty"`
+	Diastolic  *float64 `json:"diastolic,omitempty"`
+	Unit       string   `json:"unit"`
+	MeasuredAt string   `json:"measuredAt"`
+}
+
+type Diagnosis struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+// AllergyWarning is a recorded allergy of the patient to a substance of the
+// medications, it doesn't block the record.
+type AllergyWarning struct {
+	Substance string `json:"substance"`
+	Reaction  string `json:"reaction"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+// GetRecordsQuery filters GetRecords, zero values are left to the service
+// defaults (limit 5, offset 0, newest first).
+type GetRecordsQuery struct {
+	IdentityNumber  int64
+	Limit           int
+	Offset          int
+	CreatedByNip    string
+	CreatedByUserId string
+	CreatedByRole   string
+	// CreatedAt is the sort direction, asc or desc
+	CreatedAt   string
+	CreatedFrom string
+	CreatedTo   string
+	// Search is a web search style query over the symptoms and medications
+	Search        string
+	EncounterId   string
+	DiagnosisCode string
+	// Reason is required from admins and logged with the access
+	Reason string
+}
+
+type Amendment struct {
+	Symptoms    string `json:"symptoms,omitempty"`
+	Medications string `json:"medications,omitempty"`
+	Reason      string `json:"reason"`
+	// BaseVersion is the version the amendment is written against
+	BaseVersion int `json:"baseVersion"`
+}
+
+type AmendedRecord struct {
+	RecordId    string `json:"recordId"`
+	Version     int    `json:"version"`
+	Symptoms    string `json:"symptoms"`
+	Medications string `json:"medications"`
+	Reason      string `json:"reason"`
+}
+
+type RecordVersion struct {
+	Version     int           `json:"version"`
+	Symptoms    string        `json:"symptoms"`
+	Medications string        `json:"medications"`
+	Reason      *string       `json:"reason"`
+	Author      Author        `json:"author"`
+	CreatedAt   string        `json:"createdAt"`
+	Changes     []FieldChange `json:"changes"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type recordResponse[T any] struct {
+	Message  string           `json:"message"`
+	Data     T                `json:"data"`
+	Warnings []AllergyWarning `json:"warnings"`
+}
+
+// CreateRecord records a visit of the patient, by the nurse or admin the
+// client carries the token of.
+func (c *Client) CreateRecord(ctx context.Context, record NewRecord) (*Record, []AllergyWarning, error) {
+	var resp recordResponse[Record]
+	if err := c.api.Do(ctx, http.MethodPost, "/v1/medical/record", nil, record, &resp); err != nil {
+		return nil, nil, err
+	}
+
+	return &resp.Data, resp.Warnings, nil
+}
+
+func (c *Client) GetRecords(ctx context.Context, query GetRecordsQuery) ([]Record, error) {
+	params := api.Query{}.
+		Int("identityNumber", query.IdentityNumber).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset)).
+		String("createdBy.nip", query.CreatedByNip).
+		String("createdBy.userId", query.CreatedByUserId).
+		String("createdBy.role", query.CreatedByRole).
+		String("createdAt", query.CreatedAt).
+		String("createdFrom", query.CreatedFrom).
+		String("createdTo", query.CreatedTo).
+		String("q", query.Search).
+		String("encounterId", query.EncounterId).
+		String("diagnosisCode", query.DiagnosisCode).
+		String("reason", query.Reason)
+
+	var resp api.Response[[]Record]
+	if err := c.api.Get(ctx, "/v1/medical/record", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// GetRecord returns the record, reason is required from admins.
+func (c *Client) GetRecord(ctx context.Context, recordId string, reason string) (*Record, error) {
+	params := api.Query{}.String("reason", reason)
+
+	var resp api.Response[Record]
+	if err := c.api.Get(ctx, recordPath(recordId), params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// AmendRecord adds a version to the record, it is rejected with 409 when the
+// record was amended since amendment.BaseVersion. reason is the one required
+// from admins to access the record, amendment.Reason the one of the change.
+func (c *Client) AmendRecord(ctx context.Context, recordId string, amendment Amendment, reason string) (*AmendedRecord, []AllergyWarning, error) {
+	params := api.Query{}.String("reason", reason)
+
+	var resp recordResponse[AmendedRecord]
+	if err := c.api.Do(ctx, http.MethodPost, recordPath(recordId)+"/amend", params.Values(), amendment, &resp); err != nil {
+		return nil, nil, err
+	}
+
+	return &resp.Data, resp.Warnings, nil
+}
+
+// GetRecordHistory returns every version of the record, reason is required
+// from admins.
+func (c *Client) GetRecordHistory(ctx context.Context, recordId string, reason string) ([]RecordVersion, error) {
+	params := api.Query{}.String("reason", reason)
+
+	var resp api.Response[[]RecordVersion]
+	if err := c.api.Get(ctx, recordPath(recordId)+"/history", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func recordPath(recordId string) string {
+	return "/v1/medical/record/" + url.PathEscape(recordId)
+}
@@ -0,0 +1,178 @@
+// Package nurse is the client of the NurseManagement service, served under
+// /v1/user.
+package nurse
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type Client struct {
+	api *api.Client
+}
+
+func New(client *api.Client) *Client {
+	return &Client{client}
+}
+
+// WithToken returns a copy of the client calling with the access token.
+func (c *Client) WithToken(token string) *Client {
+	return &Client{c.api.WithToken(token)}
+}
+
+type User struct {
+	UserId    string `json:"userId"`
+	Nip       int64  `json:"nip"`
+	Name      string `json:"name"`
+	Access    bool   `json:"access"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// GetUsersQuery filters GetUsers, zero values are left to the service
+// defaults (limit 5, offset 0).
+type GetUsersQuery struct {
+	UserId    string
+	Limit     int
+	Offset    int
+	Name      string
+	Nip       int64
+	Role      string
+	CreatedAt string
+}
+
+type NurseRegistration struct {
+	Nip                 int64
+	Name                string
+	IdentityCardScanImg api.File
+}
+
+type RegisteredNurse struct {
+	UserId string `json:"userId"`
+	Nip    int64  `json:"nip"`
+	Name   string `json:"name"`
+}
+
+type NurseUpdate struct {
+	Nip  int64  `json:"nip"`
+	Name string `json:"name,omitempty"`
+}
+
+type Wards struct {
+	UserId string   `json:"userId"`
+	Wards  []string `json:"wards"`
+}
+
+// IdentityCard is either a signed URL to the scan or the scan itself when the
+// store can't sign URLs or Stream is asked, never both.
+type IdentityCard struct {
+	URL       string    `json:"url"`
+	ExpiresAt *string   `json:"expiresAt"`
+	Scan      *api.File `json:"-"`
+}
+
+type IdentityCardOptions struct {
+	Stream    bool
+	Thumbnail bool
+}
+
+// GetUsers lists the users, admins only.
+func (c *Client) GetUsers(ctx context.Context, query GetUsersQuery) ([]User, error) {
+	params := api.Query{}.
+		String("userId", query.UserId).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset)).
+		String("name", query.Name).
+		Int("nip", query.Nip).
+		String("role", query.Role).
+		String("createdAt", query.CreatedAt)
+
+	var resp api.Response[[]User]
+	if err := c.api.Get(ctx, "/v1/user", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *Client) RegisterNurse(ctx context.Context, nurse NurseRegistration) (*RegisteredNurse, error) {
+	form := api.NewForm().
+		Field("nip", strconv.FormatInt(nurse.Nip, 10)).
+		Field("name", nurse.Name).
+		File("identityCardScanImg", nurse.IdentityCardScanImg)
+
+	var resp api.Response[RegisteredNurse]
+	if err := c.api.DoForm(ctx, http.MethodPost, "/v1/user/nurse/register", form, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) UpdateNurse(ctx context.Context, userId string, update NurseUpdate) error {
+	var resp api.Result
+	return c.api.Do(ctx, http.MethodPut, "/v1/user/nurse/"+url.PathEscape(userId), nil, update, &resp)
+}
+
+func (c *Client) DeleteNurse(ctx context.Context, userId string) error {
+	var resp api.Result
+	return c.api.Do(ctx, http.MethodDelete, "/v1/user/nurse/"+url.PathEscape(userId), nil, nil, &resp)
+}
+
+// GrantAccess lets the nurse log in with the password.
+func (c *Client) GrantAccess(ctx context.Context, userId string, password string) error {
+	body := struct {
+		Password string `json:"password"`
+	}{password}
+
+	var resp api.Result
+	return c.api.Do(ctx, http.MethodPost, "/v1/user/nurse/"+url.PathEscape(userId)+"/access", nil, body, &resp)
+}
+
+func (c *Client) GetWards(ctx context.Context, userId string) (*Wards, error) {
+	var resp api.Response[Wards]
+	if err := c.api.Get(ctx, "/v1/user/nurse/"+url.PathEscape(userId)+"/wards", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// UpdateWards replaces every ward the nurse is assigned to.
+func (c *Client) UpdateWards(ctx context.Context, userId string, wards []string) (*Wards, error) {
+	body := struct {
+		Wards []string `json:"wards"`
+	}{wards}
+
+	var resp api.Response[Wards]
+	if err := c.api.Do(ctx, http.MethodPut, "/v1/user/nurse/"+url.PathEscape(userId)+"/wards", nil, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) GetIdentityCard(ctx context.Context, userId string, options IdentityCardOptions) (*IdentityCard, error) {
+	params := url.Values{}
+	if options.Stream {
+		params.Set("mode", "stream")
+	}
+	if options.Thumbnail {
+		params.Set("variant", "thumbnail")
+	}
+
+	var resp api.Response[IdentityCard]
+	scan, err := c.api.GetOrFile(ctx, "/v1/user/nurse/"+url.PathEscape(userId)+"/identity-card", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if scan != nil {
+		return &IdentityCard{Scan: scan}, nil
+	}
+
+	return &resp.Data, nil
+}
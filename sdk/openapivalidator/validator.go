@@ -0,0 +1,115 @@
+// Package openapivalidator checks the requests of a service against its
+// OpenAPI document before they reach the handlers.
+package openapivalidator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// FieldError is the parameter or field of the body that doesn't match the
+// document, and the rule it fails.
+type FieldError struct {
+	Field  string
+	Rule   string
+	Detail string
+}
+
+// Error is a request that doesn't match the document. Field is nil when the
+// failure isn't tied to a parameter or the body.
+type Error struct {
+	Message string
+	Field   *FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New returns a handler rejecting the requests whose parameters or body don't
+// match doc with what invalid answers for the failure, so each service
+// answers it in its own format. Requests the document doesn't describe are
+// passed on. The token isn't checked, the handler goes after the
+// authentication of the route so only authenticated callers learn what the
+// document expects.
+func New(doc *openapi3.T, invalid func(c *fiber.Ctx, err *Error) error) (fiber.Handler, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *fiber.Ctx) error {
+		req, err := adaptor.ConvertRequest(c, false)
+		if err != nil {
+			return err
+		}
+
+		// fiber routes match with or without a trailing slash, the document
+		// has them without
+		if len(req.URL.Path) > 1 {
+			req.URL.Path = strings.TrimRight(req.URL.Path, "/")
+		}
+
+		route, pathParams, err := router.FindRoute(req)
+		if err != nil {
+			return c.Next()
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+
+		if err := openapi3filter.ValidateRequest(c.UserContext(), input); err != nil {
+			return invalid(c, validationError(err))
+		}
+
+		return c.Next()
+	}, nil
+}
+
+// validationError shortens the errors of openapi3filter, which dump the whole
+// schema, to the field and the reason.
+func validationError(err error) *Error {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return &Error{Message: err.Error()}
+	}
+
+	field, rule, reason := "", "", requestErr.Reason
+	var schemaErr *openapi3.SchemaError
+	switch {
+	case errors.As(err, &schemaErr):
+		field = strings.Join(schemaErr.JSONPointer(), ".")
+		rule = schemaErr.SchemaField
+		reason = schemaErr.Reason
+	case requestErr.Err != nil:
+		reason = requestErr.Err.Error()
+	}
+
+	var message string
+	switch {
+	case requestErr.Parameter != nil:
+		message = fmt.Sprintf("%s parameter %s doesn't meet requirement", requestErr.Parameter.In, requestErr.Parameter.Name)
+		field = requestErr.Parameter.Name
+	case requestErr.RequestBody != nil:
+		message = "payload request doesn't meet requirement"
+	default:
+		return &Error{Message: reason}
+	}
+
+	return &Error{Message: message, Field: &FieldError{Field: field, Rule: rule, Detail: reason}}
+}
@@ -0,0 +1,114 @@
+package openapivalidator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+const testDocument = `{
+  "openapi": "3.0.3",
+  "info": {"title": "test", "version": "1"},
+  "paths": {
+    "/v1/item/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+      "post": {
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 0}}],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "required": ["name"],
+            "properties": {"name": {"type": "string", "minLength": 3}}
+          }}}
+        },
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`
+
+func TestValidator(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(testDocument))
+	if err != nil {
+		t.Fatalf("failed to load the document : %+v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid document : %+v", err)
+	}
+
+	var got *Error
+	validate, err := New(doc, func(c *fiber.Ctx, err *Error) error {
+		got = err
+		return c.SendStatus(http.StatusBadRequest)
+	})
+	if err != nil {
+		t.Fatalf("failed to create the validator : %+v", err)
+	}
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app.Post("/v1/item/:id", validate, ok)
+	app.Post("/v1/other", validate, ok)
+
+	tests := []struct {
+		name  string
+		path  string
+		body  string
+		want  int
+		field *FieldError
+	}{
+		{name: "valid", path: "/v1/item/1?limit=5", body: `{"name": "item one"}`, want: http.StatusOK},
+		{name: "trailing slash", path: "/v1/item/1/", body: `{"name": "item one"}`, want: http.StatusOK},
+		{name: "not in the document", path: "/v1/other", body: `{}`, want: http.StatusOK},
+		{name: "path parameter", path: "/v1/item/abc", body: `{"name": "item one"}`, want: http.StatusBadRequest, field: &FieldError{Field: "id"}},
+		{name: "query parameter", path: "/v1/item/1?limit=-1", body: `{"name": "item one"}`, want: http.StatusBadRequest, field: &FieldError{Field: "limit", Rule: "minimum"}},
+		{name: "missing field", path: "/v1/item/1", body: `{}`, want: http.StatusBadRequest, field: &FieldError{Field: "name", Rule: "required"}},
+		{name: "short field", path: "/v1/item/1", body: `{"name": "ab"}`, want: http.StatusBadRequest, field: &FieldError{Field: "name", Rule: "minLength"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("unexpected error : %+v", err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tc.want)
+			}
+
+			if tc.field == nil {
+				if got != nil {
+					t.Errorf("got error %+v for a valid request", got)
+				}
+				return
+			}
+			if got == nil || got.Field == nil {
+				t.Fatalf("got error %+v, want one on %s", got, tc.field.Field)
+			}
+			if got.Field.Field != tc.field.Field || (tc.field.Rule != "" && got.Field.Rule != tc.field.Rule) || got.Field.Detail == "" {
+				t.Errorf("got field %+v, want %+v with a detail", *got.Field, *tc.field)
+			}
+			if strings.Contains(got.Message, "{") {
+				t.Errorf("got message %q, want it without the schema", got.Message)
+			}
+		})
+	}
+}
+
+func TestValidationErrorWithoutRequestError(t *testing.T) {
+	got := validationError(errors.New("no route"))
+	if got.Message != "no route" || got.Field != nil {
+		t.Errorf("got %+v, want the message alone", got)
+	}
+}
@@ -0,0 +1,107 @@
+package patient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+const (
+	ConsentTypeTreatment   = "treatment"
+	ConsentTypeDataSharing = "data_sharing"
+	ConsentTypeResearch    = "research"
+	ConsentTypeSmsContact  = "sms_contact"
+)
+
+type ConsentRegistration struct {
+	ConsentType string `json:"consentType"`
+	Version     string `json:"version"`
+	Granted     bool   `json:"granted"`
+	WitnessName string `json:"witnessName"`
+	// SignedAt is RFC3339, the service uses the time of the call when empty
+	SignedAt string `json:"signedAt,omitempty"`
+}
+
+type RegisteredConsent struct {
+	ID             string `json:"id"`
+	IdentityNumber int64  `json:"identityNumber"`
+	ConsentType    string `json:"consentType"`
+	Version        string `json:"version"`
+	Granted        bool   `json:"granted"`
+}
+
+type Consent struct {
+	ID               string  `json:"id"`
+	IdentityNumber   int64   `json:"identityNumber"`
+	ConsentType      string  `json:"consentType"`
+	Version          string  `json:"version"`
+	Granted          bool    `json:"granted"`
+	WitnessName      string  `json:"witnessName"`
+	RecordedByUserId string  `json:"recordedByUserId"`
+	SignedAt         string  `json:"signedAt"`
+	RevokedAt        *string `json:"revokedAt"`
+	RevokedByUserId  *string `json:"revokedByUserId"`
+	RevocationReason *string `json:"revocationReason"`
+}
+
+type GetConsentsQuery struct {
+	ConsentType    string
+	IncludeRevoked bool
+}
+
+// ConsentCheck is the current consent of a type, the other fields are nil
+// when the patient never gave it.
+type ConsentCheck struct {
+	IdentityNumber int64   `json:"identityNumber"`
+	ConsentType    string  `json:"consentType"`
+	Granted        bool    `json:"granted"`
+	ConsentId      *string `json:"consentId"`
+	Version        *string `json:"version"`
+	SignedAt       *string `json:"signedAt"`
+}
+
+// CheckConsent tells whether the patient currently gives the consent, the
+// other services call it before acting on patient data.
+func (c *Client) CheckConsent(ctx context.Context, identityNumber int64, consentType string) (*ConsentCheck, error) {
+	params := api.Query{}.String("consentType", consentType)
+
+	var resp api.Response[ConsentCheck]
+	if err := c.api.Get(ctx, patientPath(identityNumber)+"/consent/check", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) RegisterConsent(ctx context.Context, identityNumber int64, consent ConsentRegistration) (*RegisteredConsent, error) {
+	var resp api.Response[RegisteredConsent]
+	if err := c.api.Do(ctx, http.MethodPost, patientPath(identityNumber)+"/consent", nil, consent, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) GetConsents(ctx context.Context, identityNumber int64, query GetConsentsQuery) ([]Consent, error) {
+	params := api.Query{}.
+		String("consentType", query.ConsentType).
+		Bool("includeRevoked", query.IncludeRevoked)
+
+	var resp api.Response[[]Consent]
+	if err := c.api.Get(ctx, patientPath(identityNumber)+"/consent", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *Client) RevokeConsent(ctx context.Context, identityNumber int64, consentId string, reason string) error {
+	body := struct {
+		Reason string `json:"reason"`
+	}{reason}
+
+	var resp api.Result
+	return c.api.Do(ctx, http.MethodPost, patientPath(identityNumber)+"/consent/"+url.PathEscape(consentId)+"/revoke", nil, body, &resp)
+}
@@ -0,0 +1,177 @@
+// Package patient is the client of the Patient service, served under
+// /v1/medical.
+package patient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type Client struct {
+	api *api.Client
+}
+
+func New(client *api.Client) *Client {
+	return &Client{client}
+}
+
+// WithToken returns a copy of the client calling with the access token.
+func (c *Client) WithToken(token string) *Client {
+	return &Client{c.api.WithToken(token)}
+}
+
+type Patient struct {
+	IdentityNumber int64  `json:"identityNumber"`
+	PhoneNumber    string `json:"phoneNumber"`
+	Name           string `json:"name"`
+	BirthDate      string `json:"birthDate"`
+	Gender         string `json:"gender"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// GetPatientsQuery filters GetPatients, zero values are left to the service
+// defaults (limit 5, offset 0).
+type GetPatientsQuery struct {
+	IdentityNumber int64
+	Limit          int
+	Offset         int
+	Name           string
+	PhoneNumber    string
+	CreatedAt      string
+}
+
+type SearchPatientsQuery struct {
+	Name          string
+	BirthDateFrom string
+	BirthDateTo   string
+	Gender        string
+	PhoneSuffix   string
+	Limit         int
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+}
+
+type SearchResult struct {
+	Patient
+	Score float64 `json:"score"`
+}
+
+type SearchMeta struct {
+	Total      int64   `json:"total"`
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+type PatientRegistration struct {
+	IdentityNumber      int64
+	PhoneNumber         string
+	Name                string
+	BirthDate           string
+	Gender              string
+	IdentityCardScanImg api.File
+}
+
+type RegisteredPatient struct {
+	IdentityNumber int64  `json:"identityNumber"`
+	Name           string `json:"name"`
+}
+
+// IdentityCard is either a signed URL to the scan or the scan itself when the
+// store can't sign URLs or Stream is asked, never both.
+type IdentityCard struct {
+	URL       string    `json:"url"`
+	ExpiresAt *string   `json:"expiresAt"`
+	Scan      *api.File `json:"-"`
+}
+
+type IdentityCardOptions struct {
+	Stream    bool
+	Thumbnail bool
+}
+
+func (c *Client) RegisterPatient(ctx context.Context, patient PatientRegistration) (*RegisteredPatient, error) {
+	form := api.NewForm().
+		Field("identityNumber", strconv.FormatInt(patient.IdentityNumber, 10)).
+		Field("phoneNumber", patient.PhoneNumber).
+		Field("name", patient.Name).
+		Field("birthDate", patient.BirthDate).
+		Field("gender", patient.Gender).
+		File("identityCardScanImg", patient.IdentityCardScanImg)
+
+	var resp api.Response[RegisteredPatient]
+	if err := c.api.DoForm(ctx, http.MethodPost, "/v1/medical/patient", form, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) GetPatients(ctx context.Context, query GetPatientsQuery) ([]Patient, error) {
+	params := api.Query{}.
+		Int("identityNumber", query.IdentityNumber).
+		Int("limit", int64(query.Limit)).
+		Int("offset", int64(query.Offset)).
+		String("name", query.Name).
+		String("phoneNumber", query.PhoneNumber).
+		String("createdAt", query.CreatedAt)
+
+	var resp api.Response[[]Patient]
+	if err := c.api.Get(ctx, "/v1/medical/patient", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// SearchPatients returns a page of patients ranked by how well they match.
+func (c *Client) SearchPatients(ctx context.Context, query SearchPatientsQuery) ([]SearchResult, *SearchMeta, error) {
+	params := api.Query{}.
+		String("name", query.Name).
+		String("birthDateFrom", query.BirthDateFrom).
+		String("birthDateTo", query.BirthDateTo).
+		String("gender", query.Gender).
+		String("phoneSuffix", query.PhoneSuffix).
+		Int("limit", int64(query.Limit)).
+		String("cursor", query.Cursor)
+
+	var resp struct {
+		Message string         `json:"message"`
+		Data    []SearchResult `json:"data"`
+		Meta    SearchMeta     `json:"meta"`
+	}
+	if err := c.api.Get(ctx, "/v1/medical/patient/search", params.Values(), &resp); err != nil {
+		return nil, nil, err
+	}
+
+	return resp.Data, &resp.Meta, nil
+}
+
+func (c *Client) GetIdentityCard(ctx context.Context, identityNumber int64, options IdentityCardOptions) (*IdentityCard, error) {
+	params := url.Values{}
+	if options.Stream {
+		params.Set("mode", "stream")
+	}
+	if options.Thumbnail {
+		params.Set("variant", "thumbnail")
+	}
+
+	var resp api.Response[IdentityCard]
+	scan, err := c.api.GetOrFile(ctx, patientPath(identityNumber)+"/identity-card", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if scan != nil {
+		return &IdentityCard{Scan: scan}, nil
+	}
+
+	return &resp.Data, nil
+}
+
+func patientPath(identityNumber int64) string {
+	return "/v1/medical/patient/" + strconv.FormatInt(identityNumber, 10)
+}
@@ -0,0 +1,105 @@
+package patient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ravenocx/hospital-mgt/sdk/api"
+)
+
+type AllergyRegistration struct {
+	Substance string `json:"substance"`
+	Reaction  string `json:"reaction"`
+	// Severity is mild, moderate, severe or life_threatening
+	Severity string `json:"severity"`
+}
+
+type RegisteredAllergy struct {
+	ID             string `json:"id"`
+	IdentityNumber int64  `json:"identityNumber"`
+	Substance      string `json:"substance"`
+}
+
+type Allergy struct {
+	ID               string `json:"id"`
+	IdentityNumber   int64  `json:"identityNumber"`
+	Substance        string `json:"substance"`
+	Reaction         string `json:"reaction"`
+	Severity         string `json:"severity"`
+	RecordedByUserId string `json:"recordedByUserId"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+type ConditionRegistration struct {
+	Condition string `json:"condition"`
+	OnsetDate string `json:"onsetDate,omitempty"`
+	// Status is active, inactive or resolved
+	Status string `json:"status"`
+}
+
+type RegisteredCondition struct {
+	ID             string `json:"id"`
+	IdentityNumber int64  `json:"identityNumber"`
+	Condition      string `json:"condition"`
+	Status         string `json:"status"`
+}
+
+type Condition struct {
+	ID               string `json:"id"`
+	IdentityNumber   int64  `json:"identityNumber"`
+	Condition        string `json:"condition"`
+	OnsetDate        string `json:"onsetDate,omitempty"`
+	Status           string `json:"status"`
+	RecordedByUserId string `json:"recordedByUserId"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+func (c *Client) RegisterAllergy(ctx context.Context, identityNumber int64, allergy AllergyRegistration) (*RegisteredAllergy, error) {
+	var resp api.Response[RegisteredAllergy]
+	if err := c.api.Do(ctx, http.MethodPost, patientPath(identityNumber)+"/allergy", nil, allergy, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+func (c *Client) GetAllergies(ctx context.Context, identityNumber int64) ([]Allergy, error) {
+	var resp api.Response[[]Allergy]
+	if err := c.api.Get(ctx, patientPath(identityNumber)+"/allergy", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *Client) RegisterCondition(ctx context.Context, identityNumber int64, condition ConditionRegistration) (*RegisteredCondition, error) {
+	var resp api.Response[RegisteredCondition]
+	if err := c.api.Do(ctx, http.MethodPost, patientPath(identityNumber)+"/condition", nil, condition, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// GetConditions lists the conditions of the patient, an empty status lists
+// them all.
+func (c *Client) GetConditions(ctx context.Context, identityNumber int64, status string) ([]Condition, error) {
+	params := api.Query{}.String("status", status)
+
+	var resp api.Response[[]Condition]
+	if err := c.api.Get(ctx, patientPath(identityNumber)+"/condition", params.Values(), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *Client) UpdateCondition(ctx context.Context, identityNumber int64, conditionId string, status string) error {
+	body := struct {
+		Status string `json:"status"`
+	}{status}
+
+	var resp api.Result
+	return c.api.Do(ctx, http.MethodPut, patientPath(identityNumber)+"/condition/"+url.PathEscape(conditionId), nil, body, &resp)
+}
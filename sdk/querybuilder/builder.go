@@ -0,0 +1,131 @@
+// Package querybuilder assembles the dynamic WHERE, ORDER BY and LIMIT parts
+// of a query. User input only ever ends up in the argument list, never in the
+// SQL text itself.
+package querybuilder
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Builder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func New() *Builder {
+	return &Builder{}
+}
+
+// Arg adds value to the argument list and returns its positional placeholder.
+// The placeholder can be used more than once in the query.
+func (b *Builder) Arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// Where adds a condition, each "?" in it is replaced by the placeholder of the
+// next value. The condition itself must never contain user input.
+func (b *Builder) Where(condition string, values ...interface{}) *Builder {
+	var sb strings.Builder
+	next := 0
+
+	for _, r := range condition {
+		if r == '?' && next < len(values) {
+			sb.WriteString(b.Arg(values[next]))
+			next++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	b.conditions = append(b.conditions, sb.String())
+	return b
+}
+
+func (b *Builder) Equal(column string, value interface{}) *Builder {
+	return b.Where(column+" = ?", value)
+}
+
+// Contains matches column case-insensitively against value anywhere in the
+// text. LIKE wildcards in value are matched literally.
+func (b *Builder) Contains(column string, value string) *Builder {
+	return b.Where(column+" ILIKE '%' || ? || '%'", EscapeLike(value))
+}
+
+// HasPrefix matches column case-insensitively against values starting with
+// value. LIKE wildcards in value are matched literally.
+func (b *Builder) HasPrefix(column string, value string) *Builder {
+	return b.Where(column+" ILIKE ? || '%'", EscapeLike(value))
+}
+
+// WhereClause returns the conditions joined with AND, or an empty string when
+// there is no condition.
+func (b *Builder) WhereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+func (b *Builder) Limit(limit int, offset int) string {
+	return " LIMIT " + b.Arg(limit) + " OFFSET " + b.Arg(offset)
+}
+
+func (b *Builder) Args() []interface{} {
+	return b.args
+}
+
+// EscapeLike escapes the LIKE wildcards so value is matched literally.
+func EscapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+// Sort whitelists the columns and directions a caller may sort on. Columns
+// maps the public name used in query params to the SQL expression.
+type Sort struct {
+	Columns          map[string]string
+	DefaultColumn    string
+	DefaultDirection string
+}
+
+// Valid reports whether both column and direction are whitelisted. An empty
+// column or direction is valid and falls back to the default.
+func (s Sort) Valid(column string, direction string) bool {
+	if column != "" {
+		if _, ok := s.Columns[column]; !ok {
+			return false
+		}
+	}
+
+	return direction == "" || normalizeDirection(direction) != ""
+}
+
+// Clause returns the ORDER BY clause, falling back to the default column or
+// direction for anything that is not whitelisted.
+func (s Sort) Clause(column string, direction string) string {
+	sqlColumn, ok := s.Columns[column]
+	if !ok {
+		sqlColumn = s.Columns[s.DefaultColumn]
+	}
+
+	sqlDirection := normalizeDirection(direction)
+	if sqlDirection == "" {
+		sqlDirection = normalizeDirection(s.DefaultDirection)
+	}
+
+	return " ORDER BY " + sqlColumn + " " + sqlDirection
+}
+
+func normalizeDirection(direction string) string {
+	switch strings.ToLower(direction) {
+	case "asc":
+		return "ASC"
+	case "desc":
+		return "DESC"
+	}
+
+	return ""
+}
@@ -0,0 +1,118 @@
+package querybuilder
+
+import (
+	"strings"
+	"testing"
+)
+
+var hostileInputs = []string{
+	"'; DROP TABLE patients; --",
+	"' OR '1'='1",
+	"%' OR name ILIKE '%",
+	"$1; SELECT pg_sleep(10)",
+	"? OR ?",
+	`\'; DELETE FROM users; --`,
+	"name) UNION SELECT password FROM users --",
+}
+
+func TestWhereKeepsInputOutOfSQL(t *testing.T) {
+	for _, input := range hostileInputs {
+		b := New()
+		b.Equal("id", input).Contains("name", input).HasPrefix("nip", input)
+
+		sql := b.WhereClause()
+		want := " WHERE id = $1 AND name ILIKE '%' || $2 || '%' AND nip ILIKE $3 || '%'"
+		if sql != want {
+			t.Errorf("input %q: got sql %q, want %q", input, sql, want)
+		}
+
+		args := b.Args()
+		if len(args) != 3 {
+			t.Fatalf("input %q: got %d args, want 3", input, len(args))
+		}
+		if args[0] != input {
+			t.Errorf("input %q: equal arg changed to %q", input, args[0])
+		}
+	}
+}
+
+func TestWhereDoesNotExpandPlaceholdersInValues(t *testing.T) {
+	b := New()
+	b.Where("a = ? AND b = ?", "?", "$1")
+
+	if got, want := b.WhereClause(), " WHERE a = $1 AND b = $2"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := b.Args(); got[0] != "?" || got[1] != "$1" {
+		t.Errorf("args changed: %v", got)
+	}
+}
+
+func TestLikeWildcardsAreEscaped(t *testing.T) {
+	b := New()
+	b.Contains("name", `50%_off\`)
+
+	if got, want := b.Args()[0], `50\%\_off\\`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLimitContinuesPlaceholderNumbering(t *testing.T) {
+	b := New()
+	b.Equal("gender", "female")
+
+	if got, want := b.Limit(5, 10), " LIMIT $2 OFFSET $3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := b.Args(); got[1] != 5 || got[2] != 10 {
+		t.Errorf("got args %v", got)
+	}
+}
+
+func TestEmptyBuilder(t *testing.T) {
+	b := New()
+
+	if got := b.WhereClause(); got != "" {
+		t.Errorf("got %q, want empty where clause", got)
+	}
+	if got := len(b.Args()); got != 0 {
+		t.Errorf("got %d args, want 0", got)
+	}
+}
+
+func TestSortWhitelist(t *testing.T) {
+	sort := Sort{
+		Columns:          map[string]string{"createdAt": "created_at", "name": "name"},
+		DefaultColumn:    "createdAt",
+		DefaultDirection: "desc",
+	}
+
+	tests := []struct {
+		column    string
+		direction string
+		want      string
+		valid     bool
+	}{
+		{"", "", " ORDER BY created_at DESC", true},
+		{"name", "asc", " ORDER BY name ASC", true},
+		{"createdAt", "ASC", " ORDER BY created_at ASC", true},
+		{"created_at; DROP TABLE patients", "asc", " ORDER BY created_at ASC", false},
+		{"name", "asc; DROP TABLE patients", " ORDER BY name DESC", false},
+		{"(SELECT password FROM users)", "desc, (SELECT 1)", " ORDER BY created_at DESC", false},
+	}
+
+	for _, tt := range tests {
+		if got := sort.Clause(tt.column, tt.direction); got != tt.want {
+			t.Errorf("Clause(%q, %q) = %q, want %q", tt.column, tt.direction, got, tt.want)
+		}
+		if got := sort.Valid(tt.column, tt.direction); got != tt.valid {
+			t.Errorf("Valid(%q, %q) = %v, want %v", tt.column, tt.direction, got, tt.valid)
+		}
+	}
+
+	for _, input := range hostileInputs {
+		if strings.Contains(sort.Clause(input, input), input) {
+			t.Errorf("hostile input %q leaked into the sort clause", input)
+		}
+	}
+}
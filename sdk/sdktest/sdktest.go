@@ -0,0 +1,74 @@
+// Package sdktest helps the services test their client against their handlers.
+package sdktest
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// RequireFilled fails the test when a field of v, at any depth, holds its zero
+// value or an empty slice. Decoding an answer the service filled completely
+// with a strict client, this makes sure every field of the client is sent by
+// the service under the same name. Fields named in optional are skipped, for
+// the ones the service leaves out on purpose.
+func RequireFilled(t testing.TB, v interface{}, optional ...string) {
+	t.Helper()
+
+	skip := map[string]bool{}
+	for _, name := range optional {
+		skip[name] = true
+	}
+
+	for _, path := range emptyFields(reflect.ValueOf(v), reflect.TypeOf(v).String(), skip) {
+		t.Errorf("%s is empty, the service doesn't send it or sends it under another name", path)
+	}
+}
+
+func emptyFields(v reflect.Value, path string, skip map[string]bool) []string {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return []string{path}
+		}
+		return emptyFields(v.Elem(), path, skip)
+
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return []string{path}
+		}
+		// []byte is content, not a list
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+
+		empty := []string{}
+		for i := 0; i < v.Len(); i++ {
+			empty = append(empty, emptyFields(v.Index(i), path+"["+strconv.Itoa(i)+"]", skip)...)
+		}
+		return empty
+
+	case reflect.Struct:
+		empty := []string{}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || skip[field.Name] {
+				continue
+			}
+
+			// an embedded struct is flattened in the JSON body
+			fieldPath := path + "." + field.Name
+			if field.Anonymous {
+				fieldPath = path
+			}
+			empty = append(empty, emptyFields(v.Field(i), fieldPath, skip)...)
+		}
+		return empty
+
+	default:
+		if v.IsZero() {
+			return []string{path}
+		}
+		return nil
+	}
+}
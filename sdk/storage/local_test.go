@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store : %+v", err)
+	}
+	ctx := context.Background()
+	key := ContentKey("identity-card", []byte("scan"), ".png")
+
+	if err := store.Put(ctx, key, strings.NewReader("scan"), 4, "image/png"); err != nil {
+		t.Fatalf("failed to put : %+v", err)
+	}
+
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("failed to get : %+v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "scan" {
+		t.Errorf("got %q, want scan", data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("failed to delete : %+v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v after delete, want ErrNotFound", err)
+	}
+	// deleting twice isn't an error
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("got error %v deleting a missing object", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "blobs")
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("failed to create store : %+v", err)
+	}
+	ctx := context.Background()
+
+	// a file next to the root, the keys below try to reach it
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatalf("failed to write file : %+v", err)
+	}
+
+	keys := []string{
+		"../secret",
+		"identity-card/../../secret",
+		"../blobs-other/scan.png",
+		"..",
+		"",
+		".",
+		"identity-card/..",
+	}
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			if err := store.Put(ctx, key, strings.NewReader("overwritten"), 11, "image/png"); err == nil {
+				t.Error("put succeeded")
+			}
+			if r, err := store.Get(ctx, key); err == nil {
+				r.Close()
+				t.Error("get succeeded")
+			}
+			if err := store.Delete(ctx, key); err == nil {
+				t.Error("delete succeeded")
+			}
+		})
+	}
+
+	data, err := os.ReadFile(secret)
+	if err != nil || string(data) != "secret" {
+		t.Errorf("got %q, %v, want the file next to the root untouched", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blobs-other")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want no directory created next to the root", err)
+	}
+}
+
+func TestLocalStoreCleansKeysInsideRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store : %+v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "identity-card/../scan.png", strings.NewReader("scan"), 4, "image/png"); err != nil {
+		t.Fatalf("failed to put : %+v", err)
+	}
+
+	r, err := store.Get(ctx, "scan.png")
+	if err != nil {
+		t.Fatalf("got error %v, want the key resolved inside the root", err)
+	}
+	r.Close()
+}
@@ -0,0 +1,77 @@
+// Package storage keeps binary objects such as identity card scans, either on
+// the local disk or in an S3-compatible object store.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// URLSigner is implemented by stores able to hand out short-lived URLs to a
+// private object. Stores that can't must have their objects streamed.
+type URLSigner interface {
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Config selects the store, Driver is STORAGE_DRIVER of the service, local or
+// s3. The S3 fields are only read by the s3 driver.
+type Config struct {
+	Driver   string
+	LocalDir string
+
+	S3Endpoint  string
+	S3AccessKey string
+	S3SecretKey string
+	S3Bucket    string
+	S3Region    string
+	S3UseSSL    bool
+}
+
+// New returns the BlobStore selected by config.Driver.
+func New(config Config) (BlobStore, error) {
+	switch config.Driver {
+	case "local":
+		return NewLocalStore(config.LocalDir)
+	case "s3":
+		return NewS3Store(config.S3Endpoint, config.S3AccessKey, config.S3SecretKey, config.S3Bucket, config.S3Region, config.S3UseSSL)
+	}
+
+	return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+}
+
+// ContentKey returns the key of an object derived from its content, so the
+// same file uploaded twice is stored once. The first two bytes of the hash
+// are used as a directory to keep the number of entries per directory low.
+func ContentKey(prefix string, data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	return path.Join(prefix, hash[:2], hash+ext)
+}
+
+// ContentType guesses the content type of an object from its key.
+func ContentType(key string) string {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import amqp "github.com/rabbitmq/amqp091-go"
+
+// AMQPHeaders carries the trace context in the headers of a RabbitMQ
+// message.
+type AMQPHeaders amqp.Table
+
+func (h AMQPHeaders) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (h AMQPHeaders) Set(key string, value string) {
+	h[key] = value
+}
+
+func (h AMQPHeaders) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
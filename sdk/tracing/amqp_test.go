@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAMQPHeadersCarryTheTrace(t *testing.T) {
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled})
+
+	propagator := propagation.TraceContext{}
+	headers := AMQPHeaders{}
+	propagator.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), headers)
+
+	got := trace.SpanContextFromContext(propagator.Extract(context.Background(), headers))
+	if got.TraceID() != traceId || got.SpanID() != spanId || !got.IsRemote() {
+		t.Errorf("got span context %+v, want trace %s span %s", got, traceId, spanId)
+	}
+}
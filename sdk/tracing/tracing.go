@@ -0,0 +1,47 @@
+// Package tracing sends the spans of a service to an OpenTelemetry
+// collector and passes the trace context on to the services it calls.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Init installs the W3C trace context propagator and, when endpoint is set,
+// a tracer provider exporting the spans with OTLP over HTTP to it, like
+// http://localhost:4318 for a local collector. Without an endpoint no span is
+// recorded but the traceparent of the callers is still passed on.
+//
+// The spans are exported in batches, the ones of the last seconds are lost
+// when the process is killed.
+func Init(ctx context.Context, serviceName string, endpoint string) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if endpoint == "" {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return fmt.Errorf("unable to create the OTLP exporter : %+v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return fmt.Errorf("unable to describe the service : %+v", err)
+	}
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	))
+
+	return nil
+}